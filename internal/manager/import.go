@@ -1,106 +1,54 @@
 package manager
 
 import (
-	"context"
 	"fmt"
-	"io"
-	"strconv"
+	"strings"
 
-	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/models"
 )
 
-type ImportDuplicateEnum string
+// importActionPartial counts objects that were imported without the values
+// that failed to import. It is not returned by models.PerformImport.
+const importActionPartial models.ImportAction = "PARTIAL"
 
-const (
-	ImportDuplicateEnumIgnore    ImportDuplicateEnum = "IGNORE"
-	ImportDuplicateEnumOverwrite ImportDuplicateEnum = "OVERWRITE"
-	ImportDuplicateEnumFail      ImportDuplicateEnum = "FAIL"
-)
-
-var AllImportDuplicateEnum = []ImportDuplicateEnum{
-	ImportDuplicateEnumIgnore,
-	ImportDuplicateEnumOverwrite,
-	ImportDuplicateEnumFail,
-}
-
-func (e ImportDuplicateEnum) IsValid() bool {
-	switch e {
-	case ImportDuplicateEnumIgnore, ImportDuplicateEnumOverwrite, ImportDuplicateEnumFail:
-		return true
-	}
-	return false
-}
-
-func (e ImportDuplicateEnum) String() string {
-	return string(e)
-}
-
-func (e *ImportDuplicateEnum) UnmarshalGQL(v interface{}) error {
-	str, ok := v.(string)
-	if !ok {
-		return fmt.Errorf("enums must be strings")
-	}
+// importSummary counts the results of the objects imported in a stage of
+// the import task.
+type importSummary map[models.ImportAction]int
 
-	*e = ImportDuplicateEnum(str)
-	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid ImportDuplicateEnum", str)
+// add counts the result of importing an object. Objects whose transaction
+// failed are counted as failed, whatever their result.
+func (s importSummary) add(result *models.ImportResult, err error) {
+	if err != nil || result == nil {
+		s[models.ImportActionFailed]++
+		return
 	}
-	return nil
-}
-
-func (e ImportDuplicateEnum) MarshalGQL(w io.Writer) {
-	fmt.Fprint(w, strconv.Quote(e.String()))
-}
 
-type importer interface {
-	PreImport(ctx context.Context) error
-	PostImport(ctx context.Context, id int) error
-	Name() string
-	FindExistingID(ctx context.Context) (*int, error)
-	Create(ctx context.Context) (*int, error)
-	Update(ctx context.Context, id int) error
+	s[result.Action]++
 }
 
-func performImport(ctx context.Context, i importer, duplicateBehaviour ImportDuplicateEnum) error {
-	if err := i.PreImport(ctx); err != nil {
-		return err
-	}
-
-	// try to find an existing object with the same name
-	name := i.Name()
-	existing, err := i.FindExistingID(ctx)
-	if err != nil {
-		return fmt.Errorf("error finding existing objects: %v", err)
+func (s importSummary) String() string {
+	labels := []struct {
+		action models.ImportAction
+		label  string
+	}{
+		{models.ImportActionCreated, "created"},
+		{models.ImportActionUpdated, "updated"},
+		{models.ImportActionSkippedUnchanged, "unchanged"},
+		{models.ImportActionSkipped, "skipped"},
+		{importActionPartial, "imported with errors"},
+		{models.ImportActionFailed, "failed"},
 	}
 
-	var id int
-
-	if existing != nil {
-		if duplicateBehaviour == ImportDuplicateEnumFail {
-			return fmt.Errorf("existing object with name '%s'", name)
-		} else if duplicateBehaviour == ImportDuplicateEnumIgnore {
-			logger.Infof("Skipping existing object %q", name)
-			return nil
-		}
-
-		// must be overwriting
-		id = *existing
-		if err := i.Update(ctx, id); err != nil {
-			return fmt.Errorf("error updating existing object: %v", err)
+	var ret []string
+	for _, l := range labels {
+		if n := s[l.action]; n > 0 {
+			ret = append(ret, fmt.Sprintf("%d %s", n, l.label))
 		}
-	} else {
-		// creating
-		createdID, err := i.Create(ctx)
-		if err != nil {
-			return fmt.Errorf("error creating object: %v", err)
-		}
-
-		id = *createdID
 	}
 
-	if err := i.PostImport(ctx, id); err != nil {
-		return err
+	if len(ret) == 0 {
+		return "nothing imported"
 	}
 
-	return nil
+	return strings.Join(ret, ", ")
 }
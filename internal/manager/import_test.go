@@ -0,0 +1,24 @@
+package manager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportSummary(t *testing.T) {
+	summary := importSummary{}
+	assert.Equal(t, "nothing imported", summary.String())
+
+	summary.add(&models.ImportResult{Action: models.ImportActionCreated}, nil)
+	summary.add(&models.ImportResult{Action: models.ImportActionCreated}, nil)
+	summary.add(&models.ImportResult{Action: models.ImportActionSkipped}, nil)
+	// the transaction may fail after the object was imported
+	summary.add(&models.ImportResult{Action: models.ImportActionUpdated}, errors.New("commit error"))
+	summary.add(nil, errors.New("read error"))
+	summary[importActionPartial]++
+
+	assert.Equal(t, "2 created, 1 skipped, 1 imported with errors, 2 failed", summary.String())
+}
@@ -1,6 +1,7 @@
 package manager
 
 import (
+	"os"
 	"path/filepath"
 
 	"github.com/stashapp/stash/pkg/models/jsonschema"
@@ -12,7 +13,8 @@ type jsonUtils struct {
 }
 
 func (jp *jsonUtils) getScraped() ([]jsonschema.ScrapedItem, error) {
-	return jsonschema.LoadScrapedFile(jp.json.ScrapedFile)
+	dir, name := filepath.Split(jp.json.ScrapedFile)
+	return jsonschema.LoadScrapedFile(os.DirFS(dir), name)
 }
 
 func (jp *jsonUtils) saveScaped(scraped []jsonschema.ScrapedItem) error {
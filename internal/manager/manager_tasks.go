@@ -101,7 +101,7 @@ func (s *Manager) Import(ctx context.Context) (int, error) {
 			txnManager:          s.Repository,
 			BaseDir:             metadataPath,
 			Reset:               true,
-			DuplicateBehaviour:  ImportDuplicateEnumFail,
+			DuplicateBehaviour:  models.ImportDuplicateEnumFail,
 			MissingRefBehaviour: models.ImportMissingRefEnumFail,
 			fileNamingAlgorithm: config.GetVideoFileNamingAlgorithm(),
 		}
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"time"
 
@@ -31,19 +33,69 @@ type ImportTask struct {
 	txnManager Repository
 	json       jsonUtils
 
+	// FS contains the metadata to import. If nil, it is set to the
+	// contents of TmpZip if set, otherwise the contents of BaseDir.
+	FS fs.FS
+
 	BaseDir             string
 	TmpZip              string
 	Reset               bool
-	DuplicateBehaviour  ImportDuplicateEnum
+	DuplicateBehaviour  models.ImportDuplicateEnum
 	MissingRefBehaviour models.ImportMissingRefEnum
 
+	// DeferPerformerImages writes performer images once the metadata of all
+	// performers has been imported.
+	DeferPerformerImages bool
+	// TagHierarchyFile, if set, is the path within FS of a file containing
+	// the tag hierarchy. It is imported after the tags and before the
+	// performers. See tag.ImportHierarchy.
+	TagHierarchyFile string
+	// PerformerSchema, if set, is a JSON Schema document that each
+	// performer file is validated against before it is imported.
+	PerformerSchema []byte
+	// PerformerDuplicateRecordPolicy determines how multiple performer
+	// records resolving to the same performer are handled.
+	PerformerDuplicateRecordPolicy performer.DuplicateRecordPolicy
+	// PerformerCaseInsensitiveMatch matches existing performers by name
+	// ignoring case.
+	PerformerCaseInsensitiveMatch bool
+	// PerformerMatchAliases matches existing performers by alias if no
+	// performer matches by name.
+	PerformerMatchAliases bool
+	// PerformerMergeStrategy determines how performer records are applied
+	// to existing performers.
+	PerformerMergeStrategy performer.MergeStrategy
+	// PerformerRatingScale is the scale of the performer ratings in the
+	// import. Defaults to performer.RatingScale5.
+	PerformerRatingScale performer.RatingScale
+	// PerformerCanonicalizeCountry converts performer countries to their
+	// ISO 3166-1 alpha-2 codes.
+	PerformerCanonicalizeCountry bool
+	// PerformerSkipUnchanged skips writing existing performers that are
+	// identical to their records.
+	PerformerSkipUnchanged bool
+	// PerformerContinueOnError imports performers without the tags, image
+	// and attachments that fail to import, logging the errors instead of
+	// failing the performer.
+	PerformerContinueOnError bool
+	// PerformerFetchImages reads performer images referenced by path,
+	// relative to FS, or by URL.
+	PerformerFetchImages bool
+	// PerformerImageLimits constrains the performer images accepted on
+	// import. The zero value accepts the default formats and size. See
+	// performer.ImageLimits.
+	PerformerImageLimits performer.ImageLimits
+	// PerformerLegacyImages disables the performer image checks, so that
+	// backups made before the images were checked can be restored.
+	PerformerLegacyImages bool
+
 	scraped             []jsonschema.ScrapedItem
 	fileNamingAlgorithm models.HashAlgorithm
 }
 
 type ImportObjectsInput struct {
 	File                graphql.Upload              `json:"file"`
-	DuplicateBehaviour  ImportDuplicateEnum         `json:"duplicateBehaviour"`
+	DuplicateBehaviour  models.ImportDuplicateEnum  `json:"duplicateBehaviour"`
 	MissingRefBehaviour models.ImportMissingRefEnum `json:"missingRefBehaviour"`
 }
 
@@ -85,33 +137,42 @@ func (t *ImportTask) GetDescription() string {
 }
 
 func (t *ImportTask) Start(ctx context.Context) {
-	if t.TmpZip != "" {
-		defer func() {
-			err := fsutil.RemoveDir(t.BaseDir)
+	if t.FS == nil {
+		if t.TmpZip != "" {
+			defer func() {
+				err := fsutil.RemoveDir(t.BaseDir)
+				if err != nil {
+					logger.Errorf("error removing directory %s: %s", t.BaseDir, err.Error())
+				}
+			}()
+
+			zipFS, err := zip.OpenReader(t.TmpZip)
 			if err != nil {
-				logger.Errorf("error removing directory %s: %s", t.BaseDir, err.Error())
+				logger.Errorf("error opening provided file for import: %s", err.Error())
+				return
 			}
-		}()
+			defer zipFS.Close()
 
-		if err := t.unzipFile(); err != nil {
-			logger.Errorf("error unzipping provided file for import: %s", err.Error())
-			return
+			t.FS = zipFS
+		} else {
+			t.FS = os.DirFS(t.BaseDir)
 		}
 	}
 
+	// paths are relative to the root of t.FS
 	t.json = jsonUtils{
-		json: *paths.GetJSONPaths(t.BaseDir),
+		json: *paths.GetJSONPaths(""),
 	}
 
 	// set default behaviour if not provided
 	if !t.DuplicateBehaviour.IsValid() {
-		t.DuplicateBehaviour = ImportDuplicateEnumFail
+		t.DuplicateBehaviour = models.ImportDuplicateEnumFail
 	}
 	if !t.MissingRefBehaviour.IsValid() {
 		t.MissingRefBehaviour = models.ImportMissingRefEnumFail
 	}
 
-	scraped, _ := t.json.getScraped()
+	scraped, _ := jsonschema.LoadScrapedFile(t.FS, t.json.json.ScrapedFile)
 	if scraped == nil {
 		logger.Warn("missing scraped json")
 	}
@@ -127,6 +188,7 @@ func (t *ImportTask) Start(ctx context.Context) {
 	}
 
 	t.ImportTags(ctx)
+	t.ImportTagHierarchy(ctx)
 	t.ImportPerformers(ctx)
 	t.ImportStudios(ctx)
 	t.ImportMovies(ctx)
@@ -138,98 +200,143 @@ func (t *ImportTask) Start(ctx context.Context) {
 	t.ImportImages(ctx)
 }
 
-func (t *ImportTask) unzipFile() error {
-	defer func() {
-		err := os.Remove(t.TmpZip)
-		if err != nil {
-			logger.Errorf("error removing temporary zip file %s: %s", t.TmpZip, err.Error())
-		}
-	}()
+// legacyPerformerImageLimits accepts the performer images of backups made
+// before images were checked on import, which may be GIF or animated
+// images of any size.
+var legacyPerformerImageLimits = performer.ImageLimits{
+	MaxSize:       -1,
+	Formats:       append([]string{"image/gif"}, performer.DefaultImageFormats...),
+	AllowAnimated: true,
+}
 
-	// now we can read the zip file
-	r, err := zip.OpenReader(t.TmpZip)
-	if err != nil {
-		return err
+func (t *ImportTask) performerImageLimits() performer.ImageLimits {
+	if t.PerformerLegacyImages {
+		return legacyPerformerImageLimits
 	}
-	defer r.Close()
 
-	for _, f := range r.File {
-		fn := filepath.Join(t.BaseDir, f.Name)
+	return t.PerformerImageLimits
+}
 
-		if f.FileInfo().IsDir() {
-			if err := os.MkdirAll(fn, os.ModePerm); err != nil {
-				logger.Warnf("couldn't create directory %v while unzipping import file: %v", fn, err)
-			}
-			continue
-		}
+func (t *ImportTask) ImportPerformers(ctx context.Context) {
+	logger.Info("[performers] importing")
 
-		if err := os.MkdirAll(filepath.Dir(fn), os.ModePerm); err != nil {
-			return err
+	dir := t.json.json.Performers
+	files, err := fs.ReadDir(t.FS, dir)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			logger.Errorf("[performers] failed to read performers directory: %v", err)
 		}
 
-		o, err := os.OpenFile(fn, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
+		return
+	}
 
-		i, err := f.Open()
-		if err != nil {
-			o.Close()
-			return err
-		}
+	batch := &performer.Batch{
+		DuplicateRecordPolicy: t.PerformerDuplicateRecordPolicy,
+	}
 
-		if _, err := io.Copy(o, i); err != nil {
-			o.Close()
-			i.Close()
-			return err
+	var schema *performer.SchemaValidator
+	if t.PerformerSchema != nil {
+		schema, err = performer.NewSchemaValidator(t.PerformerSchema)
+		if err != nil {
+			logger.Errorf("[performers] invalid schema: %v", err)
+			return
 		}
-
-		o.Close()
-		i.Close()
 	}
 
-	return nil
-}
-
-func (t *ImportTask) ImportPerformers(ctx context.Context) {
-	logger.Info("[performers] importing")
+	var imageQueue *performer.ImageQueue
+	if t.DeferPerformerImages {
+		imageQueue = performer.NewImageQueue(ctx, t.txnManager, t.txnManager.Performer)
+	}
 
-	path := t.json.json.Performers
-	files, err := os.ReadDir(path)
-	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			logger.Errorf("[performers] failed to read performers directory: %v", err)
+	var imageFetcher *performer.ImageFetcher
+	if t.PerformerFetchImages {
+		imageFetcher = &performer.ImageFetcher{
+			FS: t.FS,
 		}
-
-		return
 	}
 
+	summary := importSummary{}
 	for i, fi := range files {
 		index := i + 1
-		performerJSON, err := jsonschema.LoadPerformerFile(filepath.Join(path, fi.Name()))
+		filePath := path.Join(dir, fi.Name())
+
+		if schema != nil {
+			// validate the file as authored, rather than as decoded
+			if err := validatePerformerFile(t.FS, filePath, schema); err != nil {
+				logger.Errorf("[performers] <%s> %v", fi.Name(), err)
+				summary.add(nil, err)
+				continue
+			}
+		}
+
+		performerJSON, err := jsonschema.LoadPerformerFile(t.FS, filePath)
 		if err != nil {
 			logger.Errorf("[performers] failed to read json: %s", err.Error())
+			summary.add(nil, err)
 			continue
 		}
 
 		logger.Progressf("[performers] %d of %d", index, len(files))
 
-		if err := t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
-			r := t.txnManager
-			readerWriter := r.Performer
-			importer := &performer.Importer{
-				ReaderWriter: readerWriter,
-				TagWriter:    r.Tag,
-				Input:        *performerJSON,
-			}
-
-			return performImport(ctx, importer, t.DuplicateBehaviour)
-		}); err != nil {
+		importer := &performer.Importer{
+			ReaderWriter: t.txnManager.Performer,
+			TagWriter:    t.txnManager.Tag,
+			Input:        *performerJSON,
+			ImageQueue:   imageQueue,
+			Batch:        batch,
+
+			CaseInsensitiveMatch: t.PerformerCaseInsensitiveMatch,
+			MatchAliases:         t.PerformerMatchAliases,
+			MergeStrategy:        t.PerformerMergeStrategy,
+			RatingScale:          t.PerformerRatingScale,
+			SkipUnchanged:        t.PerformerSkipUnchanged,
+			CanonicalizeCountry:  t.PerformerCanonicalizeCountry,
+			ContinueOnError:      t.PerformerContinueOnError,
+			ImageLimits:          t.performerImageLimits(),
+			ImageFetcher:         imageFetcher,
+		}
+
+		// each performer is imported in its own transaction, which the
+		// importer rolls back in full if any stage fails
+		var result *models.ImportResult
+		err = importer.WithTxn(ctx, t.txnManager, func(ctx context.Context) error {
+			var err error
+			result, err = models.PerformImport(ctx, importer, t.DuplicateBehaviour)
+			return err
+		})
+
+		var importErrs performer.ImportErrors
+		switch {
+		case errors.As(err, &importErrs):
+			// the performer was imported without the failed values
+			logger.Warnf("[performers] <%s> imported with errors: %v", fi.Name(), err)
+			summary[importActionPartial]++
+		case err != nil:
 			logger.Errorf("[performers] <%s> import failed: %s", fi.Name(), err.Error())
+			summary.add(result, err)
+		default:
+			logger.Debugf("[performers] <%s> %s", fi.Name(), result)
+			summary.add(result, nil)
 		}
 	}
 
-	logger.Info("[performers] import complete")
+	if imageQueue != nil {
+		logger.Info("[performers] waiting for images")
+		if err := imageQueue.Wait(); err != nil {
+			logger.Errorf("[performers] %v", err)
+		}
+	}
+
+	logger.Infof("[performers] import complete: %s", summary)
+}
+
+func validatePerformerFile(fsys fs.FS, filePath string, schema *performer.SchemaValidator) error {
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read json: %w", err)
+	}
+
+	return schema.Validate(data)
 }
 
 func (t *ImportTask) ImportStudios(ctx context.Context) {
@@ -237,10 +344,10 @@ func (t *ImportTask) ImportStudios(ctx context.Context) {
 
 	logger.Info("[studios] importing")
 
-	path := t.json.json.Studios
-	files, err := os.ReadDir(path)
+	dir := t.json.json.Studios
+	files, err := fs.ReadDir(t.FS, dir)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
+		if !errors.Is(err, fs.ErrNotExist) {
 			logger.Errorf("[studios] failed to read studios directory: %v", err)
 		}
 
@@ -249,7 +356,7 @@ func (t *ImportTask) ImportStudios(ctx context.Context) {
 
 	for i, fi := range files {
 		index := i + 1
-		studioJSON, err := jsonschema.LoadStudioFile(filepath.Join(path, fi.Name()))
+		studioJSON, err := jsonschema.LoadStudioFile(t.FS, path.Join(dir, fi.Name()))
 		if err != nil {
 			logger.Errorf("[studios] failed to read json: %s", err.Error())
 			continue
@@ -304,9 +411,11 @@ func (t *ImportTask) ImportStudio(ctx context.Context, studioJSON *jsonschema.St
 		importer.MissingRefBehaviour = models.ImportMissingRefEnumFail
 	}
 
-	if err := performImport(ctx, importer, t.DuplicateBehaviour); err != nil {
+	result, err := models.PerformImport(ctx, importer, t.DuplicateBehaviour)
+	if err != nil {
 		return err
 	}
+	logger.Debugf("[studios] <%s> %s", studioJSON.Name, result)
 
 	// now create the studios pending this studios creation
 	s := pendingParent[studioJSON.Name]
@@ -326,27 +435,30 @@ func (t *ImportTask) ImportStudio(ctx context.Context, studioJSON *jsonschema.St
 func (t *ImportTask) ImportMovies(ctx context.Context) {
 	logger.Info("[movies] importing")
 
-	path := t.json.json.Movies
-	files, err := os.ReadDir(path)
+	dir := t.json.json.Movies
+	files, err := fs.ReadDir(t.FS, dir)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
+		if !errors.Is(err, fs.ErrNotExist) {
 			logger.Errorf("[movies] failed to read movies directory: %v", err)
 		}
 
 		return
 	}
 
+	summary := importSummary{}
 	for i, fi := range files {
 		index := i + 1
-		movieJSON, err := jsonschema.LoadMovieFile(filepath.Join(path, fi.Name()))
+		movieJSON, err := jsonschema.LoadMovieFile(t.FS, path.Join(dir, fi.Name()))
 		if err != nil {
 			logger.Errorf("[movies] failed to read json: %s", err.Error())
+			summary.add(nil, err)
 			continue
 		}
 
 		logger.Progressf("[movies] %d of %d", index, len(files))
 
-		if err := t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
+		var result *models.ImportResult
+		err = t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
 			r := t.txnManager
 			readerWriter := r.Movie
 			studioReaderWriter := r.Studio
@@ -358,23 +470,28 @@ func (t *ImportTask) ImportMovies(ctx context.Context) {
 				MissingRefBehaviour: t.MissingRefBehaviour,
 			}
 
-			return performImport(ctx, movieImporter, t.DuplicateBehaviour)
-		}); err != nil {
+			var err error
+			result, err = models.PerformImport(ctx, movieImporter, t.DuplicateBehaviour)
+			return err
+		})
+		summary.add(result, err)
+		if err != nil {
 			logger.Errorf("[movies] <%s> import failed: %s", fi.Name(), err.Error())
-			continue
+		} else {
+			logger.Debugf("[movies] <%s> %s", fi.Name(), result)
 		}
 	}
 
-	logger.Info("[movies] import complete")
+	logger.Infof("[movies] import complete: %s", summary)
 }
 
 func (t *ImportTask) ImportFiles(ctx context.Context) {
 	logger.Info("[files] importing")
 
-	path := t.json.json.Files
-	files, err := os.ReadDir(path)
+	dir := t.json.json.Files
+	files, err := fs.ReadDir(t.FS, dir)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
+		if !errors.Is(err, fs.ErrNotExist) {
 			logger.Errorf("[files] failed to read files directory: %v", err)
 		}
 
@@ -385,7 +502,7 @@ func (t *ImportTask) ImportFiles(ctx context.Context) {
 
 	for i, fi := range files {
 		index := i + 1
-		fileJSON, err := jsonschema.LoadFileFile(filepath.Join(path, fi.Name()))
+		fileJSON, err := jsonschema.LoadFileFile(t.FS, path.Join(dir, fi.Name()))
 		if err != nil {
 			logger.Errorf("[files] failed to read json: %s", err.Error())
 			continue
@@ -439,9 +556,11 @@ func (t *ImportTask) ImportFile(ctx context.Context, fileJSON jsonschema.DirEntr
 	}
 
 	// ignore duplicate files - don't overwrite
-	if err := performImport(ctx, fileImporter, ImportDuplicateEnumIgnore); err != nil {
+	result, err := models.PerformImport(ctx, fileImporter, models.ImportDuplicateEnumIgnore)
+	if err != nil {
 		return err
 	}
+	logger.Debugf("[files] <%s> %s", fileJSON.DirEntry().Path, result)
 
 	// now create the files pending this file's creation
 	s := pendingParent[fileJSON.DirEntry().Path]
@@ -461,27 +580,30 @@ func (t *ImportTask) ImportFile(ctx context.Context, fileJSON jsonschema.DirEntr
 func (t *ImportTask) ImportGalleries(ctx context.Context) {
 	logger.Info("[galleries] importing")
 
-	path := t.json.json.Galleries
-	files, err := os.ReadDir(path)
+	dir := t.json.json.Galleries
+	files, err := fs.ReadDir(t.FS, dir)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
+		if !errors.Is(err, fs.ErrNotExist) {
 			logger.Errorf("[galleries] failed to read galleries directory: %v", err)
 		}
 
 		return
 	}
 
+	summary := importSummary{}
 	for i, fi := range files {
 		index := i + 1
-		galleryJSON, err := jsonschema.LoadGalleryFile(filepath.Join(path, fi.Name()))
+		galleryJSON, err := jsonschema.LoadGalleryFile(t.FS, path.Join(dir, fi.Name()))
 		if err != nil {
 			logger.Errorf("[galleries] failed to read json: %s", err.Error())
+			summary.add(nil, err)
 			continue
 		}
 
 		logger.Progressf("[galleries] %d of %d", index, len(files))
 
-		if err := t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
+		var result *models.ImportResult
+		err = t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
 			r := t.txnManager
 			readerWriter := r.Gallery
 			tagWriter := r.Tag
@@ -499,24 +621,29 @@ func (t *ImportTask) ImportGalleries(ctx context.Context) {
 				MissingRefBehaviour: t.MissingRefBehaviour,
 			}
 
-			return performImport(ctx, galleryImporter, t.DuplicateBehaviour)
-		}); err != nil {
+			var err error
+			result, err = models.PerformImport(ctx, galleryImporter, t.DuplicateBehaviour)
+			return err
+		})
+		summary.add(result, err)
+		if err != nil {
 			logger.Errorf("[galleries] <%s> import failed to commit: %s", fi.Name(), err.Error())
-			continue
+		} else {
+			logger.Debugf("[galleries] <%s> %s", fi.Name(), result)
 		}
 	}
 
-	logger.Info("[galleries] import complete")
+	logger.Infof("[galleries] import complete: %s", summary)
 }
 
 func (t *ImportTask) ImportTags(ctx context.Context) {
 	pendingParent := make(map[string][]*jsonschema.Tag)
 	logger.Info("[tags] importing")
 
-	path := t.json.json.Tags
-	files, err := os.ReadDir(path)
+	dir := t.json.json.Tags
+	files, err := fs.ReadDir(t.FS, dir)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
+		if !errors.Is(err, fs.ErrNotExist) {
 			logger.Errorf("[tags] failed to read tags directory: %v", err)
 		}
 
@@ -525,7 +652,7 @@ func (t *ImportTask) ImportTags(ctx context.Context) {
 
 	for i, fi := range files {
 		index := i + 1
-		tagJSON, err := jsonschema.LoadTagFile(filepath.Join(path, fi.Name()))
+		tagJSON, err := jsonschema.LoadTagFile(t.FS, path.Join(dir, fi.Name()))
 		if err != nil {
 			logger.Errorf("[tags] failed to read json: %s", err.Error())
 			continue
@@ -561,6 +688,31 @@ func (t *ImportTask) ImportTags(ctx context.Context) {
 	logger.Info("[tags] import complete")
 }
 
+// ImportTagHierarchy imports the tag hierarchy from TagHierarchyFile, if
+// set.
+func (t *ImportTask) ImportTagHierarchy(ctx context.Context) {
+	if t.TagHierarchyFile == "" {
+		return
+	}
+
+	logger.Info("[tag hierarchy] importing")
+
+	tags, err := jsonschema.LoadTagHierarchyFile(t.FS, t.TagHierarchyFile)
+	if err != nil {
+		logger.Errorf("[tag hierarchy] failed to read json: %v", err)
+		return
+	}
+
+	if err := t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
+		return tag.ImportHierarchy(ctx, t.txnManager.Tag, tags)
+	}); err != nil {
+		logger.Errorf("[tag hierarchy] failed to import: %v", err)
+		return
+	}
+
+	logger.Info("[tag hierarchy] import complete")
+}
+
 func (t *ImportTask) ImportTag(ctx context.Context, tagJSON *jsonschema.Tag, pendingParent map[string][]*jsonschema.Tag, fail bool, readerWriter tag.NameFinderCreatorUpdater) error {
 	importer := &tag.Importer{
 		ReaderWriter:        readerWriter,
@@ -573,9 +725,11 @@ func (t *ImportTask) ImportTag(ctx context.Context, tagJSON *jsonschema.Tag, pen
 		importer.MissingRefBehaviour = models.ImportMissingRefEnumFail
 	}
 
-	if err := performImport(ctx, importer, t.DuplicateBehaviour); err != nil {
+	result, err := models.PerformImport(ctx, importer, t.DuplicateBehaviour)
+	if err != nil {
 		return err
 	}
+	logger.Debugf("[tags] <%s> %s", tagJSON.Name, result)
 
 	for _, childTagJSON := range pendingParent[tagJSON.Name] {
 		if err := t.ImportTag(ctx, childTagJSON, pendingParent, fail, readerWriter); err != nil {
@@ -648,28 +802,31 @@ func (t *ImportTask) ImportScrapedItems(ctx context.Context) {
 func (t *ImportTask) ImportScenes(ctx context.Context) {
 	logger.Info("[scenes] importing")
 
-	path := t.json.json.Scenes
-	files, err := os.ReadDir(path)
+	dir := t.json.json.Scenes
+	files, err := fs.ReadDir(t.FS, dir)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
+		if !errors.Is(err, fs.ErrNotExist) {
 			logger.Errorf("[scenes] failed to read scenes directory: %v", err)
 		}
 
 		return
 	}
 
+	summary := importSummary{}
 	for i, fi := range files {
 		index := i + 1
 
 		logger.Progressf("[scenes] %d of %d", index, len(files))
 
-		sceneJSON, err := jsonschema.LoadSceneFile(filepath.Join(path, fi.Name()))
+		sceneJSON, err := jsonschema.LoadSceneFile(t.FS, path.Join(dir, fi.Name()))
 		if err != nil {
 			logger.Infof("[scenes] <%s> json parse failure: %s", fi.Name(), err.Error())
+			summary.add(nil, err)
 			continue
 		}
 
-		if err := t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
+		var result *models.ImportResult
+		err = t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
 			r := t.txnManager
 			readerWriter := r.Scene
 			tagWriter := r.Tag
@@ -694,7 +851,9 @@ func (t *ImportTask) ImportScenes(ctx context.Context) {
 				TagWriter:       tagWriter,
 			}
 
-			if err := performImport(ctx, sceneImporter, t.DuplicateBehaviour); err != nil {
+			var err error
+			result, err = models.PerformImport(ctx, sceneImporter, t.DuplicateBehaviour)
+			if err != nil {
 				return err
 			}
 
@@ -708,45 +867,54 @@ func (t *ImportTask) ImportScenes(ctx context.Context) {
 					TagWriter:           tagWriter,
 				}
 
-				if err := performImport(ctx, markerImporter, t.DuplicateBehaviour); err != nil {
+				markerResult, err := models.PerformImport(ctx, markerImporter, t.DuplicateBehaviour)
+				if err != nil {
 					return err
 				}
+				logger.Debugf("[scenes] <%s> marker %s", fi.Name(), markerResult)
 			}
 
 			return nil
-		}); err != nil {
+		})
+		summary.add(result, err)
+		if err != nil {
 			logger.Errorf("[scenes] <%s> import failed: %s", fi.Name(), err.Error())
+		} else {
+			logger.Debugf("[scenes] <%s> %s", fi.Name(), result)
 		}
 	}
 
-	logger.Info("[scenes] import complete")
+	logger.Infof("[scenes] import complete: %s", summary)
 }
 
 func (t *ImportTask) ImportImages(ctx context.Context) {
 	logger.Info("[images] importing")
 
-	path := t.json.json.Images
-	files, err := os.ReadDir(path)
+	dir := t.json.json.Images
+	files, err := fs.ReadDir(t.FS, dir)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
+		if !errors.Is(err, fs.ErrNotExist) {
 			logger.Errorf("[images] failed to read images directory: %v", err)
 		}
 
 		return
 	}
 
+	summary := importSummary{}
 	for i, fi := range files {
 		index := i + 1
 
 		logger.Progressf("[images] %d of %d", index, len(files))
 
-		imageJSON, err := jsonschema.LoadImageFile(filepath.Join(path, fi.Name()))
+		imageJSON, err := jsonschema.LoadImageFile(t.FS, path.Join(dir, fi.Name()))
 		if err != nil {
 			logger.Infof("[images] <%s> json parse failure: %s", fi.Name(), err.Error())
+			summary.add(nil, err)
 			continue
 		}
 
-		if err := t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
+		var result *models.ImportResult
+		err = t.txnManager.WithTxn(ctx, func(ctx context.Context) error {
 			r := t.txnManager
 			readerWriter := r.Image
 			tagWriter := r.Tag
@@ -767,13 +935,19 @@ func (t *ImportTask) ImportImages(ctx context.Context) {
 				TagWriter:       tagWriter,
 			}
 
-			return performImport(ctx, imageImporter, t.DuplicateBehaviour)
-		}); err != nil {
+			var err error
+			result, err = models.PerformImport(ctx, imageImporter, t.DuplicateBehaviour)
+			return err
+		})
+		summary.add(result, err)
+		if err != nil {
 			logger.Errorf("[images] <%s> import failed: %s", fi.Name(), err.Error())
+		} else {
+			logger.Debugf("[images] <%s> %s", fi.Name(), result)
 		}
 	}
 
-	logger.Info("[images] import complete")
+	logger.Infof("[images] import complete: %s", summary)
 }
 
 var currentLocation = time.Now().Location()
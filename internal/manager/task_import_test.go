@@ -0,0 +1,150 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stashapp/stash/pkg/models/paths"
+	"github.com/stashapp/stash/pkg/performer"
+	"github.com/stretchr/testify/mock"
+)
+
+// 1x1 png
+const testPerformerImage = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAAAAAA6fptVAAAAD0lEQVR4nAACAP3/AgADAAAGAAMh/KwGAAAAAElFTkSuQmCC"
+
+func newPerformerImportTask(performerRW *mocks.PerformerReaderWriter, files map[string]string) *ImportTask {
+	fsys := fstest.MapFS{}
+	for name, data := range files {
+		fsys["performers/"+name] = &fstest.MapFile{Data: []byte(data)}
+	}
+
+	return &ImportTask{
+		txnManager: Repository{
+			TxnManager: &mocks.TxnManager{},
+			Performer:  performerRW,
+			Tag:        &mocks.TagReaderWriter{},
+		},
+		json: jsonUtils{
+			json: paths.JSONPaths{
+				Performers: "performers",
+			},
+		},
+		FS:                 fsys,
+		DuplicateBehaviour: models.ImportDuplicateEnumOverwrite,
+	}
+}
+
+func TestImportPerformersRollbackCreated(t *testing.T) {
+	const name = "performer"
+	performerRW := &mocks.PerformerReaderWriter{}
+
+	performerRW.On("FindByNames", mock.Anything, []string{name}, false).Return(nil, nil).Twice()
+
+	nextID := 0
+	performerRW.On("Create", mock.Anything, mock.AnythingOfType("*models.Performer")).Run(func(args mock.Arguments) {
+		nextID++
+		args.Get(1).(*models.Performer).ID = nextID
+	}).Return(nil).Twice()
+
+	// the first performer is rolled back
+	performerRW.On("UpdateImage", mock.Anything, 1, mock.Anything).Return(errors.New("UpdateImage error")).Once()
+
+	// the second performer must be created rather than resolving to the
+	// rolled back performer
+	task := newPerformerImportTask(performerRW, map[string]string{
+		"a.json": `{"name": "performer", "image": "` + testPerformerImage + `"}`,
+		"b.json": `{"name": "performer"}`,
+	})
+	task.ImportPerformers(context.Background())
+
+	performerRW.AssertExpectations(t)
+	performerRW.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestImportPerformersRollbackClaimed(t *testing.T) {
+	const (
+		name       = "performer"
+		existingID = 10
+	)
+	performerRW := &mocks.PerformerReaderWriter{}
+
+	performerRW.On("FindByNames", mock.Anything, []string{name}, false).Return([]*models.Performer{
+		{ID: existingID, Name: name},
+	}, nil).Twice()
+	performerRW.On("Update", mock.Anything, mock.AnythingOfType("*models.Performer")).Return(nil).Twice()
+	performerRW.On("UpdateTags", mock.Anything, existingID, mock.Anything).Return(nil).Maybe()
+
+	// the first update is rolled back
+	performerRW.On("UpdateImage", mock.Anything, existingID, mock.Anything).Return(errors.New("UpdateImage error")).Once()
+
+	// the second record must not be reported as a duplicate of the rolled
+	// back record
+	task := newPerformerImportTask(performerRW, map[string]string{
+		"a.json": `{"name": "performer", "image": "` + testPerformerImage + `"}`,
+		"b.json": `{"name": "performer", "details": "updated"}`,
+	})
+	task.PerformerDuplicateRecordPolicy = performer.DuplicateRecordPolicyFail
+	task.ImportPerformers(context.Background())
+
+	performerRW.AssertExpectations(t)
+}
+
+func TestImportPerformersSchema(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"rating": {"type": "integer"}
+		},
+		"additionalProperties": false
+	}`
+
+	performerRW := &mocks.PerformerReaderWriter{}
+
+	// neither record is valid as written, although both decode to a valid
+	// performer: the unknown property is discarded and the null rating is
+	// decoded as zero
+	task := newPerformerImportTask(performerRW, map[string]string{
+		"a.json": `{"name": "performer", "unknown": "value"}`,
+		"b.json": `{"name": "performer", "rating": null}`,
+	})
+	task.PerformerSchema = []byte(schema)
+	task.ImportPerformers(context.Background())
+
+	performerRW.AssertNotCalled(t, "FindByNames", mock.Anything, mock.Anything, mock.Anything)
+	performerRW.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestImportPerformersImageLimits(t *testing.T) {
+	// 1x1 gif
+	const gifImage = "R0lGODlhAQABAIAAAP///wAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw=="
+
+	files := map[string]string{
+		"a.json": `{"name": "performer", "image": "` + gifImage + `"}`,
+	}
+
+	// gif images are rejected by default
+	performerRW := &mocks.PerformerReaderWriter{}
+	task := newPerformerImportTask(performerRW, files)
+	task.ImportPerformers(context.Background())
+
+	performerRW.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// and accepted from legacy backups
+	performerRW = &mocks.PerformerReaderWriter{}
+	performerRW.On("FindByNames", mock.Anything, []string{"performer"}, false).Return(nil, nil).Once()
+	performerRW.On("Create", mock.Anything, mock.AnythingOfType("*models.Performer")).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Performer).ID = 1
+	}).Return(nil).Once()
+	performerRW.On("UpdateImage", mock.Anything, 1, mock.Anything).Return(nil).Once()
+
+	task = newPerformerImportTask(performerRW, files)
+	task.PerformerLegacyImages = true
+	task.ImportPerformers(context.Background())
+
+	performerRW.AssertExpectations(t)
+}
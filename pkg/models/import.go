@@ -1,9 +1,14 @@
 package models
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/logger"
 )
 
 type ImportMissingRefEnum string
@@ -48,3 +53,234 @@ func (e *ImportMissingRefEnum) UnmarshalGQL(v interface{}) error {
 func (e ImportMissingRefEnum) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type ImportDuplicateEnum string
+
+const (
+	ImportDuplicateEnumIgnore    ImportDuplicateEnum = "IGNORE"
+	ImportDuplicateEnumOverwrite ImportDuplicateEnum = "OVERWRITE"
+	ImportDuplicateEnumFail      ImportDuplicateEnum = "FAIL"
+)
+
+var AllImportDuplicateEnum = []ImportDuplicateEnum{
+	ImportDuplicateEnumIgnore,
+	ImportDuplicateEnumOverwrite,
+	ImportDuplicateEnumFail,
+}
+
+func (e ImportDuplicateEnum) IsValid() bool {
+	switch e {
+	case ImportDuplicateEnumIgnore, ImportDuplicateEnumOverwrite, ImportDuplicateEnumFail:
+		return true
+	}
+	return false
+}
+
+func (e ImportDuplicateEnum) String() string {
+	return string(e)
+}
+
+func (e *ImportDuplicateEnum) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportDuplicateEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportDuplicateEnum", str)
+	}
+	return nil
+}
+
+func (e ImportDuplicateEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+// Importer imports a single object. See PerformImport.
+type Importer interface {
+	PreImport(ctx context.Context) error
+	PostImport(ctx context.Context, id int) error
+	Name() string
+	FindExistingID(ctx context.Context) (*int, error)
+	Create(ctx context.Context) (*int, error)
+	Update(ctx context.Context, id int) error
+}
+
+// changeReporter is implemented by importers that can report which parts
+// of the object were written during the import.
+type changeReporter interface {
+	Changed() []string
+}
+
+// warningReporter is implemented by importers that can report non-fatal
+// problems encountered during the import.
+type warningReporter interface {
+	Warnings() []string
+}
+
+// skipReporter is implemented by importers that may decide not to write
+// an existing object.
+type skipReporter interface {
+	Skipped() bool
+}
+
+// unchangedReporter is implemented by importers that can report that an
+// existing object was identical to the input.
+type unchangedReporter interface {
+	Unchanged() bool
+}
+
+type ImportAction string
+
+const (
+	ImportActionCreated ImportAction = "CREATED"
+	ImportActionUpdated ImportAction = "UPDATED"
+	ImportActionSkipped ImportAction = "SKIPPED"
+	ImportActionFailed  ImportAction = "FAILED"
+	// ImportActionSkippedUnchanged indicates that the existing object was
+	// identical to the input, so was not updated.
+	ImportActionSkippedUnchanged ImportAction = "SKIPPED_UNCHANGED"
+)
+
+// Import stages used as keys in ImportResult.Durations.
+const (
+	ImportStagePreImport     = "pre_import"
+	ImportStageFindExisting  = "find_existing"
+	ImportStageCreate        = "create"
+	ImportStageUpdate        = "update"
+	ImportStagePostImport    = "post_import"
+	ImportStageTotalDuration = "total"
+)
+
+// ImportResult describes the outcome of importing a single object.
+type ImportResult struct {
+	// ID is the ID of the created or updated object. It is zero if the
+	// object was not written.
+	ID     int
+	Action ImportAction
+	// Changed lists the parts of the object that were written, if the
+	// importer reports them.
+	Changed []string
+	// Durations contains the time spent in each import stage.
+	Durations map[string]time.Duration
+	Warnings  []string
+}
+
+// String describes the result for logging.
+func (r *ImportResult) String() string {
+	action := strings.ToLower(strings.ReplaceAll(string(r.Action), "_", " "))
+	ret := fmt.Sprintf("%s %d in %s", action, r.ID, r.Durations[ImportStageTotalDuration])
+	if len(r.Changed) > 0 {
+		ret += ": " + strings.Join(r.Changed, ", ")
+	}
+
+	return ret
+}
+
+func (r *ImportResult) timeStage(stage string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	r.Durations[stage] = time.Since(start)
+	return err
+}
+
+func (r *ImportResult) collect(i Importer) {
+	if cr, ok := i.(changeReporter); ok {
+		r.Changed = cr.Changed()
+	}
+	if wr, ok := i.(warningReporter); ok {
+		r.Warnings = wr.Warnings()
+	}
+
+	for _, w := range r.Warnings {
+		logger.Warnf("%s: %s", i.Name(), w)
+	}
+}
+
+// PerformImport imports a single object using the provided importer. The
+// returned result is never nil, and is populated even if the import fails.
+func PerformImport(ctx context.Context, i Importer, duplicateBehaviour ImportDuplicateEnum) (*ImportResult, error) {
+	result := &ImportResult{
+		Action:    ImportActionFailed,
+		Durations: make(map[string]time.Duration),
+	}
+
+	start := time.Now()
+	defer func() {
+		result.Durations[ImportStageTotalDuration] = time.Since(start)
+		result.collect(i)
+	}()
+
+	if err := result.timeStage(ImportStagePreImport, func() error {
+		return i.PreImport(ctx)
+	}); err != nil {
+		return result, err
+	}
+
+	// try to find an existing object with the same name
+	name := i.Name()
+	var existing *int
+	if err := result.timeStage(ImportStageFindExisting, func() error {
+		var err error
+		existing, err = i.FindExistingID(ctx)
+		return err
+	}); err != nil {
+		return result, fmt.Errorf("error finding existing objects: %v", err)
+	}
+
+	var id int
+
+	if existing != nil {
+		if duplicateBehaviour == ImportDuplicateEnumFail {
+			return result, fmt.Errorf("existing object with name '%s'", name)
+		} else if duplicateBehaviour == ImportDuplicateEnumIgnore {
+			logger.Infof("Skipping existing object %q", name)
+			result.ID = *existing
+			result.Action = ImportActionSkipped
+			return result, nil
+		}
+
+		// must be overwriting
+		id = *existing
+		if err := result.timeStage(ImportStageUpdate, func() error {
+			return i.Update(ctx, id)
+		}); err != nil {
+			return result, fmt.Errorf("error updating existing object: %v", err)
+		}
+	} else {
+		// creating
+		if err := result.timeStage(ImportStageCreate, func() error {
+			createdID, err := i.Create(ctx)
+			if err != nil {
+				return err
+			}
+
+			id = *createdID
+			return nil
+		}); err != nil {
+			return result, fmt.Errorf("error creating object: %v", err)
+		}
+	}
+
+	result.ID = id
+
+	if err := result.timeStage(ImportStagePostImport, func() error {
+		return i.PostImport(ctx, id)
+	}); err != nil {
+		return result, err
+	}
+
+	// importers skipping unchanged objects report both
+	if ur, ok := i.(unchangedReporter); ok && ur.Unchanged() {
+		result.Action = ImportActionSkippedUnchanged
+	} else if sr, ok := i.(skipReporter); ok && sr.Skipped() {
+		result.Action = ImportActionSkipped
+	} else if existing != nil {
+		result.Action = ImportActionUpdated
+	} else {
+		result.Action = ImportActionCreated
+	}
+
+	return result, nil
+}
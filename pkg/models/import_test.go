@@ -0,0 +1,227 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testImportExistingID = 10
+	testImportCreatedID  = 11
+)
+
+type testImporter struct {
+	existing  bool
+	preErr    error
+	createErr error
+	postErr   error
+	skipped   bool
+	unchanged bool
+
+	changed  []string
+	warnings []string
+}
+
+func (i *testImporter) PreImport(ctx context.Context) error {
+	return i.preErr
+}
+
+func (i *testImporter) PostImport(ctx context.Context, id int) error {
+	if i.postErr != nil {
+		return i.postErr
+	}
+	i.changed = append(i.changed, "post")
+	return nil
+}
+
+func (i *testImporter) Name() string {
+	return "test"
+}
+
+func (i *testImporter) FindExistingID(ctx context.Context) (*int, error) {
+	if !i.existing {
+		return nil, nil
+	}
+	id := testImportExistingID
+	return &id, nil
+}
+
+func (i *testImporter) Create(ctx context.Context) (*int, error) {
+	if i.createErr != nil {
+		return nil, i.createErr
+	}
+	i.changed = append(i.changed, "object")
+	id := testImportCreatedID
+	return &id, nil
+}
+
+func (i *testImporter) Update(ctx context.Context, id int) error {
+	if !i.unchanged {
+		i.changed = append(i.changed, "object")
+	}
+	return nil
+}
+
+func (i *testImporter) Changed() []string {
+	return i.changed
+}
+
+func (i *testImporter) Skipped() bool {
+	return i.skipped
+}
+
+func (i *testImporter) Unchanged() bool {
+	return i.unchanged
+}
+
+func (i *testImporter) Warnings() []string {
+	return i.warnings
+}
+
+func TestPerformImportResult(t *testing.T) {
+	ctx := context.Background()
+	importErr := errors.New("import error")
+
+	tests := []struct {
+		name               string
+		importer           *testImporter
+		duplicateBehaviour ImportDuplicateEnum
+		wantErr            bool
+		wantID             int
+		wantAction         ImportAction
+		wantChanged        []string
+		wantStages         []string
+	}{
+		{
+			"create",
+			&testImporter{warnings: []string{"warning"}},
+			ImportDuplicateEnumFail,
+			false,
+			testImportCreatedID,
+			ImportActionCreated,
+			[]string{"object", "post"},
+			[]string{ImportStagePreImport, ImportStageFindExisting, ImportStageCreate, ImportStagePostImport},
+		},
+		{
+			"update",
+			&testImporter{existing: true},
+			ImportDuplicateEnumOverwrite,
+			false,
+			testImportExistingID,
+			ImportActionUpdated,
+			[]string{"object", "post"},
+			[]string{ImportStagePreImport, ImportStageFindExisting, ImportStageUpdate, ImportStagePostImport},
+		},
+		{
+			"skip",
+			&testImporter{existing: true},
+			ImportDuplicateEnumIgnore,
+			false,
+			testImportExistingID,
+			ImportActionSkipped,
+			nil,
+			[]string{ImportStagePreImport, ImportStageFindExisting},
+		},
+		{
+			"skipped by importer",
+			&testImporter{existing: true, skipped: true},
+			ImportDuplicateEnumOverwrite,
+			false,
+			testImportExistingID,
+			ImportActionSkipped,
+			[]string{"object", "post"},
+			[]string{ImportStagePreImport, ImportStageFindExisting, ImportStageUpdate, ImportStagePostImport},
+		},
+		{
+			"unchanged",
+			&testImporter{existing: true, unchanged: true},
+			ImportDuplicateEnumOverwrite,
+			false,
+			testImportExistingID,
+			ImportActionSkippedUnchanged,
+			[]string{"post"},
+			[]string{ImportStagePreImport, ImportStageFindExisting, ImportStageUpdate, ImportStagePostImport},
+		},
+		{
+			"duplicate fail",
+			&testImporter{existing: true},
+			ImportDuplicateEnumFail,
+			true,
+			0,
+			ImportActionFailed,
+			nil,
+			[]string{ImportStagePreImport, ImportStageFindExisting},
+		},
+		{
+			"pre import error",
+			&testImporter{preErr: importErr},
+			ImportDuplicateEnumFail,
+			true,
+			0,
+			ImportActionFailed,
+			nil,
+			[]string{ImportStagePreImport},
+		},
+		{
+			"create error",
+			&testImporter{createErr: importErr},
+			ImportDuplicateEnumFail,
+			true,
+			0,
+			ImportActionFailed,
+			nil,
+			[]string{ImportStagePreImport, ImportStageFindExisting, ImportStageCreate},
+		},
+		{
+			"post import error",
+			&testImporter{postErr: importErr},
+			ImportDuplicateEnumFail,
+			true,
+			testImportCreatedID,
+			ImportActionFailed,
+			[]string{"object"},
+			[]string{ImportStagePreImport, ImportStageFindExisting, ImportStageCreate, ImportStagePostImport},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PerformImport(ctx, tt.importer, tt.duplicateBehaviour)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PerformImport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !assert.NotNil(t, result) {
+				return
+			}
+
+			assert.Equal(t, tt.wantID, result.ID)
+			assert.Equal(t, tt.wantAction, result.Action)
+			assert.Equal(t, tt.wantChanged, result.Changed)
+			assert.Equal(t, tt.importer.warnings, result.Warnings)
+
+			for _, stage := range tt.wantStages {
+				assert.Contains(t, result.Durations, stage)
+			}
+			assert.Contains(t, result.Durations, ImportStageTotalDuration)
+			assert.Len(t, result.Durations, len(tt.wantStages)+1)
+		})
+	}
+}
+
+func TestImportResultString(t *testing.T) {
+	result := &ImportResult{
+		ID:      testImportCreatedID,
+		Action:  ImportActionSkippedUnchanged,
+		Changed: []string{"object", "post"},
+		Durations: map[string]time.Duration{
+			ImportStageTotalDuration: time.Millisecond,
+		},
+	}
+
+	assert.Equal(t, "skipped unchanged 11 in 1ms: object, post", result.String())
+}
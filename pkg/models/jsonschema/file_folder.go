@@ -5,7 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"io/fs"
 	"path/filepath"
 	"strings"
 
@@ -97,8 +97,8 @@ type ImageFile struct {
 	Height int    `json:"height,omitempty"`
 }
 
-func LoadFileFile(filePath string) (DirEntry, error) {
-	r, err := os.Open(filePath)
+func LoadFileFile(fsys fs.FS, filePath string) (DirEntry, error) {
+	r, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
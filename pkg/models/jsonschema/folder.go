@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
 	"strings"
 
@@ -32,9 +32,9 @@ func (f *Folder) Filename() string {
 	return fmt.Sprintf("%2x.%s.%s.json", depth, basename, hash)
 }
 
-func LoadFolderFile(filePath string) (*Folder, error) {
+func LoadFolderFile(fsys fs.FS, filePath string) (*Folder, error) {
 	var folder Folder
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
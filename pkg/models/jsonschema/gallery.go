@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
@@ -37,9 +37,9 @@ func (s Gallery) Filename(basename string, hash string) string {
 	return ret + ".json"
 }
 
-func LoadGalleryFile(filePath string) (*Gallery, error) {
+func LoadGalleryFile(fsys fs.FS, filePath string) (*Gallery, error) {
 	var gallery Gallery
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stashapp/stash/pkg/fsutil"
@@ -36,9 +36,9 @@ func (s Image) Filename(basename string, hash string) string {
 	return ret + ".json"
 }
 
-func LoadImageFile(filePath string) (*Image, error) {
+func LoadImageFile(fsys fs.FS, filePath string) (*Image, error) {
 	var image Image
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 
 	jsoniter "github.com/json-iterator/go"
 
@@ -36,32 +36,26 @@ type MovieSynopsisBC struct {
 	Synopsis string `json:"sypnopsis,omitempty"`
 }
 
-func LoadMovieFile(filePath string) (*Movie, error) {
+func LoadMovieFile(fsys fs.FS, filePath string) (*Movie, error) {
 	var movie Movie
-	file, err := os.Open(filePath)
+	data, err := fs.ReadFile(fsys, filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 	var json = jsoniter.ConfigCompatibleWithStandardLibrary
-	jsonParser := json.NewDecoder(file)
-	err = jsonParser.Decode(&movie)
+	err = json.Unmarshal(data, &movie)
 	if err != nil {
 		return nil, err
 	}
 	if movie.Synopsis == "" {
 		// keep backwards compatibility with pre #2664 builds
 		// attempt to get the synopsis from the alternate (sypnopsis) key
-
-		_, err = file.Seek(0, 0) // seek to start of file
+		var synopsis MovieSynopsisBC
+		err = json.Unmarshal(data, &synopsis)
 		if err == nil {
-			var synopsis MovieSynopsisBC
-			err = jsonParser.Decode(&synopsis)
-			if err == nil {
-				movie.Synopsis = synopsis.Synopsis
-				if movie.Synopsis != "" {
-					logger.Debug("Movie synopsis retrieved from alternate key")
-				}
+			movie.Synopsis = synopsis.Synopsis
+			if movie.Synopsis != "" {
+				logger.Debug("Movie synopsis retrieved from alternate key")
 			}
 		}
 	}
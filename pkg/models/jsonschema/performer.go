@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stashapp/stash/pkg/fsutil"
@@ -11,43 +11,111 @@ import (
 )
 
 type Performer struct {
-	Name          string           `json:"name,omitempty"`
-	Gender        string           `json:"gender,omitempty"`
-	URL           string           `json:"url,omitempty"`
-	Twitter       string           `json:"twitter,omitempty"`
-	Instagram     string           `json:"instagram,omitempty"`
-	Birthdate     string           `json:"birthdate,omitempty"`
-	Ethnicity     string           `json:"ethnicity,omitempty"`
-	Country       string           `json:"country,omitempty"`
-	EyeColor      string           `json:"eye_color,omitempty"`
-	Height        string           `json:"height,omitempty"`
-	Measurements  string           `json:"measurements,omitempty"`
-	FakeTits      string           `json:"fake_tits,omitempty"`
-	CareerLength  string           `json:"career_length,omitempty"`
-	Tattoos       string           `json:"tattoos,omitempty"`
-	Piercings     string           `json:"piercings,omitempty"`
-	Aliases       string           `json:"aliases,omitempty"`
-	Favorite      bool             `json:"favorite,omitempty"`
-	Tags          []string         `json:"tags,omitempty"`
-	Image         string           `json:"image,omitempty"`
-	CreatedAt     json.JSONTime    `json:"created_at,omitempty"`
-	UpdatedAt     json.JSONTime    `json:"updated_at,omitempty"`
-	Rating        int              `json:"rating,omitempty"`
-	Details       string           `json:"details,omitempty"`
-	DeathDate     string           `json:"death_date,omitempty"`
-	HairColor     string           `json:"hair_color,omitempty"`
-	Weight        int              `json:"weight,omitempty"`
-	StashIDs      []models.StashID `json:"stash_ids,omitempty"`
-	IgnoreAutoTag bool             `json:"ignore_auto_tag,omitempty"`
+	Name             string           `json:"name,omitempty"`
+	SortName         string           `json:"sort_name,omitempty"`
+	SortNameLocale   string           `json:"sort_name_locale,omitempty"`
+	Disambiguation   string           `json:"disambiguation,omitempty"`
+	Gender           string           `json:"gender,omitempty"`
+	URL              string           `json:"url,omitempty"`
+	URLs             []string         `json:"urls,omitempty"`
+	Twitter          string           `json:"twitter,omitempty"`
+	Instagram        string           `json:"instagram,omitempty"`
+	Birthdate        string           `json:"birthdate,omitempty"`
+	Ethnicity        string           `json:"ethnicity,omitempty"`
+	Country          string           `json:"country,omitempty"`
+	EyeColor         string           `json:"eye_color,omitempty"`
+	Height           string           `json:"height,omitempty"`
+	Measurements     string           `json:"measurements,omitempty"`
+	MeasurementUnits string           `json:"measurement_units,omitempty"`
+	FakeTits         string           `json:"fake_tits,omitempty"`
+	CareerLength     string           `json:"career_length,omitempty"`
+	Tattoos          string           `json:"tattoos,omitempty"`
+	Piercings        string           `json:"piercings,omitempty"`
+	Aliases          string           `json:"aliases,omitempty"`
+	Favorite         bool             `json:"favorite,omitempty"`
+	Tags             []string         `json:"tags,omitempty"`
+	RemoveTags       []string         `json:"remove_tags,omitempty"`
+	Image            string           `json:"image,omitempty"`
+	Images           []string         `json:"images,omitempty"`
+	PrimaryImage     int              `json:"primary_image,omitempty"`
+	ImagePath        string           `json:"image_path,omitempty"`
+	ImageURL         string           `json:"image_url,omitempty"`
+	CreatedAt        json.JSONTime    `json:"created_at,omitempty"`
+	UpdatedAt        json.JSONTime    `json:"updated_at,omitempty"`
+	Rating           int              `json:"rating,omitempty"`
+	Details          string           `json:"details,omitempty"`
+	DeathDate        string           `json:"death_date,omitempty"`
+	HairColor        string           `json:"hair_color,omitempty"`
+	Weight           int              `json:"weight,omitempty"`
+	StashIDs         []models.StashID `json:"stash_ids,omitempty"`
+	IgnoreAutoTag    bool             `json:"ignore_auto_tag,omitempty"`
+	PropagateTags    bool             `json:"propagate_tags,omitempty"`
+
+	Attachments []PerformerAttachment `json:"attachments,omitempty"`
+
+	// CustomFields is arbitrary metadata keyed by field name.
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+
+	// TagAliases maps tag names to the aliases to set on the tag if it is
+	// created during import.
+	TagAliases map[string][]string `json:"tag_aliases,omitempty"`
+	// TagParents maps tag names to the names of the parents to set on the
+	// tag if it is created during import.
+	TagParents map[string][]string `json:"tag_parents,omitempty"`
+
+	// Fields, if set, lists the fields that are authoritative in this
+	// object. Other fields are ignored on import.
+	Fields []string `json:"_fields,omitempty"`
+
+	// Present is the set of json field names present in the decoded object,
+	// including fields that are explicitly empty or null. It is nil if the
+	// performer was not decoded from JSON.
+	Present map[string]bool `json:"-"`
+}
+
+// UnmarshalJSON decodes the performer, recording the fields present in the
+// object in Present.
+func (s *Performer) UnmarshalJSON(data []byte) error {
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	// performer does not implement json.Unmarshaler, so is decoded normally
+	type performer Performer
+	var p performer
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+
+	var fields map[string]jsoniter.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	p.Present = make(map[string]bool)
+	for name := range fields {
+		p.Present[name] = true
+	}
+
+	*s = Performer(p)
+	return nil
+}
+
+// PerformerAttachment is a document associated with a performer.
+type PerformerAttachment struct {
+	Name string `json:"name"`
+	// ContentType is the MIME type of the attachment. If empty, it is
+	// detected from the data.
+	ContentType string `json:"content_type,omitempty"`
+	// Data is the base64 encoded attachment.
+	Data string `json:"data"`
 }
 
 func (s Performer) Filename() string {
 	return fsutil.SanitiseBasename(s.Name) + ".json"
 }
 
-func LoadPerformerFile(filePath string) (*Performer, error) {
+func LoadPerformerFile(fsys fs.FS, filePath string) (*Performer, error) {
 	var performer Performer
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stashapp/stash/pkg/fsutil"
@@ -71,9 +71,9 @@ func (s Scene) Filename(basename string, hash string) string {
 	return ret + ".json"
 }
 
-func LoadSceneFile(filePath string) (*Scene, error) {
+func LoadSceneFile(fsys fs.FS, filePath string) (*Scene, error) {
 	var scene Scene
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
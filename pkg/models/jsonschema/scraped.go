@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stashapp/stash/pkg/models/json"
@@ -25,9 +25,9 @@ type ScrapedItem struct {
 	UpdatedAt       json.JSONTime `json:"updated_at,omitempty"`
 }
 
-func LoadScrapedFile(filePath string) ([]ScrapedItem, error) {
+func LoadScrapedFile(fsys fs.FS, filePath string) ([]ScrapedItem, error) {
 	var scraped []ScrapedItem
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stashapp/stash/pkg/fsutil"
@@ -28,9 +28,9 @@ func (s Studio) Filename() string {
 	return fsutil.SanitiseBasename(s.Name) + ".json"
 }
 
-func LoadStudioFile(filePath string) (*Studio, error) {
+func LoadStudioFile(fsys fs.FS, filePath string) (*Studio, error) {
 	var studio Studio
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -2,7 +2,7 @@ package jsonschema
 
 import (
 	"fmt"
-	"os"
+	"io/fs"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/stashapp/stash/pkg/fsutil"
@@ -24,9 +24,9 @@ func (s Tag) Filename() string {
 	return fsutil.SanitiseBasename(s.Name) + ".json"
 }
 
-func LoadTagFile(filePath string) (*Tag, error) {
+func LoadTagFile(fsys fs.FS, filePath string) (*Tag, error) {
 	var tag Tag
-	file, err := os.Open(filePath)
+	file, err := fsys.Open(filePath)
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +40,23 @@ func LoadTagFile(filePath string) (*Tag, error) {
 	return &tag, nil
 }
 
+// LoadTagHierarchyFile loads a file containing a JSON array of tags.
+func LoadTagHierarchyFile(fsys fs.FS, filePath string) ([]Tag, error) {
+	var tags []Tag
+	file, err := fsys.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var json = jsoniter.ConfigCompatibleWithStandardLibrary
+	jsonParser := json.NewDecoder(file)
+	err = jsonParser.Decode(&tags)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
 func SaveTagFile(filePath string, tag *Tag) error {
 	if tag == nil {
 		return fmt.Errorf("tag must not be nil")
@@ -144,6 +144,29 @@ func (_m *PerformerReaderWriter) Find(ctx context.Context, id int) (*models.Perf
 	return r0, r1
 }
 
+// FindByAlias provides a mock function with given fields: ctx, alias, nocase
+func (_m *PerformerReaderWriter) FindByAlias(ctx context.Context, alias string, nocase bool) ([]*models.Performer, error) {
+	ret := _m.Called(ctx, alias, nocase)
+
+	var r0 []*models.Performer
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) []*models.Performer); ok {
+		r0 = rf(ctx, alias, nocase)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Performer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, alias, nocase)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByGalleryID provides a mock function with given fields: ctx, galleryID
 func (_m *PerformerReaderWriter) FindByGalleryID(ctx context.Context, galleryID int) ([]*models.Performer, error) {
 	ret := _m.Called(ctx, galleryID)
@@ -305,6 +328,52 @@ func (_m *PerformerReaderWriter) FindMany(ctx context.Context, ids []int) ([]*mo
 	return r0, r1
 }
 
+// GetAttachments provides a mock function with given fields: ctx, performerID
+func (_m *PerformerReaderWriter) GetAttachments(ctx context.Context, performerID int) ([]models.PerformerAttachment, error) {
+	ret := _m.Called(ctx, performerID)
+
+	var r0 []models.PerformerAttachment
+	if rf, ok := ret.Get(0).(func(context.Context, int) []models.PerformerAttachment); ok {
+		r0 = rf(ctx, performerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PerformerAttachment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, performerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCustomFields provides a mock function with given fields: ctx, performerID
+func (_m *PerformerReaderWriter) GetCustomFields(ctx context.Context, performerID int) (map[string]string, error) {
+	ret := _m.Called(ctx, performerID)
+
+	var r0 map[string]string
+	if rf, ok := ret.Get(0).(func(context.Context, int) map[string]string); ok {
+		r0 = rf(ctx, performerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, performerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetImage provides a mock function with given fields: ctx, performerID
 func (_m *PerformerReaderWriter) GetImage(ctx context.Context, performerID int) ([]byte, error) {
 	ret := _m.Called(ctx, performerID)
@@ -441,6 +510,34 @@ func (_m *PerformerReaderWriter) Update(ctx context.Context, updatedPerformer *m
 	return r0
 }
 
+// UpdateAttachments provides a mock function with given fields: ctx, performerID, attachments
+func (_m *PerformerReaderWriter) UpdateAttachments(ctx context.Context, performerID int, attachments []models.PerformerAttachment) error {
+	ret := _m.Called(ctx, performerID, attachments)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []models.PerformerAttachment) error); ok {
+		r0 = rf(ctx, performerID, attachments)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCustomFields provides a mock function with given fields: ctx, performerID, fields
+func (_m *PerformerReaderWriter) UpdateCustomFields(ctx context.Context, performerID int, fields map[string]string) error {
+	ret := _m.Called(ctx, performerID, fields)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, map[string]string) error); ok {
+		r0 = rf(ctx, performerID, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateImage provides a mock function with given fields: ctx, performerID, image
 func (_m *PerformerReaderWriter) UpdateImage(ctx context.Context, performerID int, image []byte) error {
 	ret := _m.Called(ctx, performerID, image)
@@ -7,43 +7,64 @@ import (
 )
 
 type Performer struct {
-	ID            int        `json:"id"`
-	Checksum      string     `json:"checksum"`
-	Name          string     `json:"name"`
-	Gender        GenderEnum `json:"gender"`
-	URL           string     `json:"url"`
-	Twitter       string     `json:"twitter"`
-	Instagram     string     `json:"instagram"`
-	Birthdate     *Date      `json:"birthdate"`
-	Ethnicity     string     `json:"ethnicity"`
-	Country       string     `json:"country"`
-	EyeColor      string     `json:"eye_color"`
-	Height        string     `json:"height"`
-	Measurements  string     `json:"measurements"`
-	FakeTits      string     `json:"fake_tits"`
-	CareerLength  string     `json:"career_length"`
-	Tattoos       string     `json:"tattoos"`
-	Piercings     string     `json:"piercings"`
-	Aliases       string     `json:"aliases"`
-	Favorite      bool       `json:"favorite"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
-	Rating        *int       `json:"rating"`
-	Details       string     `json:"details"`
-	DeathDate     *Date      `json:"death_date"`
-	HairColor     string     `json:"hair_color"`
-	Weight        *int       `json:"weight"`
-	IgnoreAutoTag bool       `json:"ignore_auto_tag"`
+	ID       int    `json:"id"`
+	Checksum string `json:"checksum"`
+	Name     string `json:"name"`
+	SortName string `json:"sort_name"`
+	// SortNameLocale is the BCP 47 language tag used to collate SortName.
+	// The default collation is used if empty.
+	SortNameLocale string `json:"sort_name_locale"`
+	// Disambiguation distinguishes performers with the same name.
+	Disambiguation string     `json:"disambiguation"`
+	Gender         GenderEnum `json:"gender"`
+	URL            string     `json:"url"`
+	// URLs are all of the performer's URLs, in order. URL is the first of
+	// them.
+	URLs          []string  `json:"urls"`
+	Twitter       string    `json:"twitter"`
+	Instagram     string    `json:"instagram"`
+	Birthdate     *Date     `json:"birthdate"`
+	Ethnicity     string    `json:"ethnicity"`
+	Country       string    `json:"country"`
+	EyeColor      string    `json:"eye_color"`
+	Height        string    `json:"height"`
+	Measurements  string    `json:"measurements"`
+	FakeTits      string    `json:"fake_tits"`
+	CareerLength  string    `json:"career_length"`
+	Tattoos       string    `json:"tattoos"`
+	Piercings     string    `json:"piercings"`
+	Aliases       string    `json:"aliases"`
+	Favorite      bool      `json:"favorite"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Rating        *int      `json:"rating"`
+	Details       string    `json:"details"`
+	DeathDate     *Date     `json:"death_date"`
+	HairColor     string    `json:"hair_color"`
+	Weight        *int      `json:"weight"`
+	IgnoreAutoTag bool      `json:"ignore_auto_tag"`
+	// PropagateTags indicates that the performer's tags should be applied to
+	// their scenes. Defaults to false. Independent of IgnoreAutoTag, since
+	// auto tagging matches the performer itself, not the performer's tags.
+	PropagateTags bool `json:"propagate_tags"`
+	// CustomFields is arbitrary metadata keyed by field name. It is stored
+	// separately, so is only set if loaded using GetCustomFields.
+	CustomFields map[string]string `json:"custom_fields"`
 }
 
 // PerformerPartial represents part of a Performer object. It is used to update
 // the database entry.
 type PerformerPartial struct {
-	ID            int
-	Checksum      OptionalString
-	Name          OptionalString
-	Gender        OptionalString
-	URL           OptionalString
+	ID             int
+	Checksum       OptionalString
+	Name           OptionalString
+	SortName       OptionalString
+	SortNameLocale OptionalString
+	Disambiguation OptionalString
+	Gender         OptionalString
+	URL            OptionalString
+	// URLs, if not nil, replaces the performer's URLs.
+	URLs          *[]string
 	Twitter       OptionalString
 	Instagram     OptionalString
 	Birthdate     OptionalDate
@@ -66,6 +87,14 @@ type PerformerPartial struct {
 	HairColor     OptionalString
 	Weight        OptionalInt
 	IgnoreAutoTag OptionalBool
+	PropagateTags OptionalBool
+}
+
+// PerformerAttachment is a document associated with a performer.
+type PerformerAttachment struct {
+	Name        string `db:"name" json:"name"`
+	ContentType string `db:"content_type" json:"content_type"`
+	Data        []byte `db:"data" json:"data"`
 }
 
 func NewPerformer(name string) *Performer {
@@ -136,6 +136,7 @@ type PerformerReader interface {
 	FindByImageID(ctx context.Context, imageID int) ([]*Performer, error)
 	FindByGalleryID(ctx context.Context, galleryID int) ([]*Performer, error)
 	FindByNames(ctx context.Context, names []string, nocase bool) ([]*Performer, error)
+	FindByAlias(ctx context.Context, alias string, nocase bool) ([]*Performer, error)
 	FindByStashID(ctx context.Context, stashID StashID) ([]*Performer, error)
 	FindByStashIDStatus(ctx context.Context, hasStashID bool, stashboxEndpoint string) ([]*Performer, error)
 	CountByTagID(ctx context.Context, tagID int) (int, error)
@@ -146,6 +147,8 @@ type PerformerReader interface {
 	QueryForAutoTag(ctx context.Context, words []string) ([]*Performer, error)
 	Query(ctx context.Context, performerFilter *PerformerFilterType, findFilter *FindFilterType) ([]*Performer, int, error)
 	GetImage(ctx context.Context, performerID int) ([]byte, error)
+	GetAttachments(ctx context.Context, performerID int) ([]PerformerAttachment, error)
+	GetCustomFields(ctx context.Context, performerID int) (map[string]string, error)
 	StashIDLoader
 	GetTagIDs(ctx context.Context, performerID int) ([]int, error)
 }
@@ -157,6 +160,8 @@ type PerformerWriter interface {
 	Destroy(ctx context.Context, id int) error
 	UpdateImage(ctx context.Context, performerID int, image []byte) error
 	DestroyImage(ctx context.Context, performerID int) error
+	UpdateAttachments(ctx context.Context, performerID int, attachments []PerformerAttachment) error
+	UpdateCustomFields(ctx context.Context, performerID int, fields map[string]string) error
 	UpdateStashIDs(ctx context.Context, performerID int, stashIDs []StashID) error
 	UpdateTags(ctx context.Context, performerID int, tagIDs []int) error
 }
@@ -0,0 +1,36 @@
+package performer
+
+import (
+	"strings"
+)
+
+// NormalizeAliases normalizes the comma separated aliases of the performer
+// with the provided name. Whitespace is trimmed and collapsed, and empty
+// aliases, aliases equal to the name and duplicate aliases are removed.
+// Aliases are compared case-insensitively, and the first spelling of an
+// alias is kept.
+func NormalizeAliases(aliases string, name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+
+	var ret []string
+	for _, a := range strings.Split(aliases, ",") {
+		a = strings.Join(strings.Fields(a), " ")
+		if a == "" || strings.EqualFold(a, name) || containsFold(ret, a) {
+			continue
+		}
+
+		ret = append(ret, a)
+	}
+
+	return strings.Join(ret, ", ")
+}
+
+func containsFold(vs []string, s string) bool {
+	for _, v := range vs {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
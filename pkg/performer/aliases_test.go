@@ -0,0 +1,31 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		aliases string
+		want    string
+	}{
+		{"empty", "", ""},
+		{"unchanged", "Jane, Janey", "Jane, Janey"},
+		{"duplicates", "Jane, Janey, Jane", "Jane, Janey"},
+		{"case duplicates", "Jane, JANE, jane", "Jane"},
+		{"name", "Jane, Jane Doe, Janey", "Jane, Janey"},
+		{"name case", "jane doe, Janey", "Janey"},
+		{"whitespace", "  Jane ,Janey  Doe,, ", "Jane, Janey Doe"},
+		{"whitespace duplicates", "Janey Doe, Janey  Doe", "Janey Doe"},
+		{"only name", "Jane Doe", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeAliases(tt.aliases, "Jane Doe"))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package performer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+var (
+	// ErrAttachmentTooLarge is returned when an attachment exceeds the
+	// maximum size.
+	ErrAttachmentTooLarge = errors.New("attachment too large")
+	// ErrAttachmentDecode is returned when an attachment cannot be decoded.
+	ErrAttachmentDecode = errors.New("invalid attachment")
+	// ErrAttachmentUnsupportedType is returned when an attachment is not of
+	// one of the accepted content types.
+	ErrAttachmentUnsupportedType = errors.New("unsupported attachment type")
+)
+
+// AttachmentErrorBehaviour determines how invalid attachments are handled.
+type AttachmentErrorBehaviour string
+
+const (
+	// AttachmentErrorFail fails the import. This is the default.
+	AttachmentErrorFail AttachmentErrorBehaviour = "FAIL"
+	// AttachmentErrorSkip skips the attachment and reports a warning.
+	AttachmentErrorSkip AttachmentErrorBehaviour = "SKIP"
+)
+
+// AttachmentLimits constrains the attachments accepted on import. The zero
+// value accepts all attachments.
+type AttachmentLimits struct {
+	// MaxSize is the maximum decoded size of each attachment in bytes. Zero
+	// means no limit.
+	MaxSize int
+	// ContentTypes, if set, lists the accepted MIME types, as detected from
+	// the attachment data.
+	ContentTypes []string
+}
+
+// decodeAttachment decodes the base64 encoded attachment, and checks it
+// against the limits. The content type is detected from the data if not set
+// in the input. The returned errors wrap ErrAttachmentTooLarge,
+// ErrAttachmentDecode or ErrAttachmentUnsupportedType.
+func (l AttachmentLimits) decodeAttachment(input jsonschema.PerformerAttachment) (*models.PerformerAttachment, error) {
+	if input.Name == "" {
+		return nil, fmt.Errorf("%w: missing name", ErrAttachmentDecode)
+	}
+
+	data, err := utils.ProcessBase64Image(input.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAttachmentDecode, err)
+	}
+
+	if l.MaxSize > 0 && len(data) > l.MaxSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrAttachmentTooLarge, len(data), l.MaxSize)
+	}
+
+	detected := http.DetectContentType(data)
+	if err := l.checkContentType(detected); err != nil {
+		return nil, err
+	}
+
+	contentType := input.ContentType
+	if contentType == "" {
+		contentType = detected
+	}
+
+	return &models.PerformerAttachment{
+		Name:        input.Name,
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+func (l AttachmentLimits) checkContentType(contentType string) error {
+	if len(l.ContentTypes) == 0 {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+
+	for _, t := range l.ContentTypes {
+		if strings.EqualFold(t, mediaType) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrAttachmentUnsupportedType, mediaType)
+}
+
+// processAttachments decodes and validates the input attachments, applying
+// AttachmentErrorBehaviour to invalid attachments.
+func (i *Importer) processAttachments(ctx context.Context) error {
+	i.attachments = nil
+
+	for _, a := range i.Input.Attachments {
+		attachment, err := i.AttachmentLimits.decodeAttachment(a)
+		if err != nil {
+			if i.AttachmentErrorBehaviour == AttachmentErrorSkip {
+				i.addWarning("skipping attachment %q: %v", a.Name, err)
+				continue
+			}
+
+			err = fmt.Errorf("error processing attachment %q: %w", a.Name, err)
+			if err := i.continueOnError(ctx, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		i.attachments = append(i.attachments, *attachment)
+	}
+
+	return nil
+}
+
+// setsAttachments returns true if the import sets the performer
+// attachments.
+func (i *Importer) setsAttachments() bool {
+	return len(i.attachments) > 0 || i.fieldMask["attachments"]
+}
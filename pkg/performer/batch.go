@@ -0,0 +1,185 @@
+package performer
+
+import (
+	"sync"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// DuplicateRecordPolicy determines how records in the same batch that
+// resolve to the same performer are handled.
+type DuplicateRecordPolicy string
+
+const (
+	// DuplicateRecordPolicyLast applies the records in order, so that the
+	// last record wins. A warning is reported for each subsequent record.
+	DuplicateRecordPolicyLast DuplicateRecordPolicy = "LAST"
+	// DuplicateRecordPolicyFirst applies the first record and skips
+	// subsequent records.
+	DuplicateRecordPolicyFirst DuplicateRecordPolicy = "FIRST"
+	// DuplicateRecordPolicyFail fails the import of subsequent records.
+	DuplicateRecordPolicyFail DuplicateRecordPolicy = "FAIL"
+)
+
+// Batch holds state shared between the importers of a single import run.
+// It is safe for concurrent use. The zero value is ready to use, and uses
+// DuplicateRecordPolicyLast.
+type Batch struct {
+	DuplicateRecordPolicy DuplicateRecordPolicy
+
+	mutex sync.Mutex
+	// maps performer ID to the name of the first record resolving to it
+	claimed map[int]string
+	// maps performer name and disambiguation to the ID of the performer
+	// created in the run
+	created map[string]map[string]int
+	// maps tag names to the tags found or created in the run with that
+	// name or alias
+	tags map[string]*models.Tag
+	// the set of tag names found not to exist in the run
+	missingTags map[string]bool
+}
+
+// claim records that the named record resolves to the performer with the
+// provided ID. If another record has already claimed the performer, then
+// the name of that record is returned.
+func (b *Batch) claim(id int, name string) (string, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.claimed == nil {
+		b.claimed = make(map[int]string)
+	}
+
+	if existing, found := b.claimed[id]; found {
+		return existing, true
+	}
+
+	b.claimed[id] = name
+	return "", false
+}
+
+// recordCreated records that the performer with the provided ID was created
+// in the run.
+func (b *Batch) recordCreated(name string, disambiguation string, id int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.created == nil {
+		b.created = make(map[string]map[string]int)
+	}
+
+	if b.created[name] == nil {
+		b.created[name] = make(map[string]int)
+	}
+
+	b.created[name][disambiguation] = id
+}
+
+// release removes the records made for the performer with the provided ID,
+// once the transaction that claimed or created it has been rolled back.
+func (b *Batch) release(id int, name string, disambiguation string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.claimed[id] == name {
+		delete(b.claimed, id)
+	}
+
+	if b.created[name][disambiguation] == id {
+		delete(b.created[name], disambiguation)
+	}
+}
+
+// createdIDs returns the IDs of the performers with the provided name that
+// were created in the run, keyed by disambiguation.
+func (b *Batch) createdIDs(name string) map[string]int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	ret := make(map[string]int)
+	for disambiguation, id := range b.created[name] {
+		ret[disambiguation] = id
+	}
+
+	return ret
+}
+
+// cachedTags returns the tags of names that were found or created earlier
+// in the run, the names that were found not to exist, and the names that
+// have not been looked up.
+func (b *Batch) cachedTags(names []string) (found []*models.Tag, missing []string, uncached []string) {
+	if b == nil {
+		return nil, nil, names
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, name := range names {
+		switch {
+		case b.tags[name] != nil:
+			found = append(found, b.tags[name])
+		case b.missingTags[name]:
+			missing = append(missing, name)
+		default:
+			uncached = append(uncached, name)
+		}
+	}
+
+	return found, missing, uncached
+}
+
+// addTags records that the tags with the provided names or aliases exist.
+func (b *Batch) addTags(tags map[string]*models.Tag) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.tags == nil {
+		b.tags = make(map[string]*models.Tag)
+	}
+
+	for name, t := range tags {
+		b.tags[name] = t
+		delete(b.missingTags, name)
+	}
+}
+
+// addMissingTags records that no tags exist with the provided names.
+func (b *Batch) addMissingTags(names []string) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.missingTags == nil {
+		b.missingTags = make(map[string]bool)
+	}
+
+	for _, name := range names {
+		b.missingTags[name] = true
+	}
+}
+
+// forgetTags removes the records of the tags with the provided names, so
+// that they are looked up again. It is used once tags are created with the
+// names, or the transaction that created them has been rolled back.
+func (b *Batch) forgetTags(names []string) {
+	if b == nil {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for _, name := range names {
+		delete(b.tags, name)
+		delete(b.missingTags, name)
+	}
+}
@@ -0,0 +1,30 @@
+package performer
+
+import (
+	"strings"
+)
+
+// noneBodyModifications are values of the tattoos and piercings fields that
+// indicate that the performer has none. Compared case-insensitively.
+var noneBodyModifications = []string{
+	"none",
+	"no",
+	"n/a",
+	"na",
+	"-",
+}
+
+// NormalizeBodyModification normalizes the free text of a tattoos or
+// piercings field. Values indicating none are returned as empty. Otherwise,
+// whitespace is trimmed and collapsed, and the text is otherwise unchanged.
+func NormalizeBodyModification(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	for _, none := range noneBodyModifications {
+		if strings.EqualFold(s, none) {
+			return ""
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,27 @@
+package performer
+
+import "testing"
+
+func TestNormalizeBodyModification(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", ""},
+		{"none", ""},
+		{"None", ""},
+		{" N/A ", ""},
+		{"NA", ""},
+		{"-", ""},
+		{"  Left   arm;\n right  ankle ", "Left arm; right ankle"},
+		{"Nonexistent", "Nonexistent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := NormalizeBodyModification(tt.s); got != tt.want {
+				t.Errorf("NormalizeBodyModification(%q) = %q, want %q", tt.s, got, tt.want)
+			}
+		})
+	}
+}
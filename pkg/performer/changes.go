@@ -0,0 +1,51 @@
+package performer
+
+import (
+	"context"
+)
+
+// ImportChanges summarises the changes made by importing a single performer.
+type ImportChanges struct {
+	// ID is the ID of the created or updated performer.
+	ID int `json:"id"`
+	// Created is true if a new performer was created.
+	Created bool `json:"created"`
+	// Updated is true if an existing performer was updated.
+	Updated bool `json:"updated"`
+	// Skipped is true if the existing performer was identical to the
+	// input, so nothing was written. See Importer.SkipUnchanged.
+	Skipped bool `json:"skipped"`
+	// TagsCreated is the number of missing tags that were created.
+	TagsCreated int `json:"tags_created"`
+	// ImageSet is true if the performer image was written.
+	ImageSet bool `json:"image_set"`
+	// StashIDsChanged is true if the performer stash IDs were written.
+	// When merging into the existing stash IDs, it is only true if a stash
+	// ID was added or replaced.
+	StashIDsChanged bool `json:"stash_ids_changed"`
+}
+
+// Changes returns the changes made by the import.
+func (i *Importer) Changes() ImportChanges {
+	return i.changes
+}
+
+// CreateWithChanges calls Create, returning the changes made by the import.
+// The returned changes are completed by PostImport.
+func (i *Importer) CreateWithChanges(ctx context.Context) (*ImportChanges, error) {
+	if _, err := i.Create(ctx); err != nil {
+		return nil, err
+	}
+
+	return &i.changes, nil
+}
+
+// UpdateWithChanges calls Update, returning the changes made by the import.
+// The returned changes are completed by PostImport.
+func (i *Importer) UpdateWithChanges(ctx context.Context, id int) (*ImportChanges, error) {
+	if err := i.Update(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return &i.changes, nil
+}
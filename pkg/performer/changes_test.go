@@ -0,0 +1,116 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImporterChanges(t *testing.T) {
+	const (
+		createdID   = 201
+		replacedID  = 202
+		mergedID    = 203
+		createdTag1 = "Created 1"
+		createdTag2 = "Created 2"
+	)
+
+	readerWriter := &mocks.PerformerReaderWriter{}
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	// created with two new tags and an existing tag, and an image
+	created := &Importer{
+		ReaderWriter:        readerWriter,
+		TagWriter:           tagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+		Input: jsonschema.Performer{
+			Name:  "created",
+			Image: image,
+			Tags:  []string{existingTagName, createdTag1, createdTag2},
+		},
+	}
+
+	// updated with replaced stash IDs
+	replaced := &Importer{
+		ReaderWriter: readerWriter,
+		Input: jsonschema.Performer{
+			Name:     "replaced",
+			StashIDs: stashIDs,
+		},
+	}
+
+	// merged with stash IDs that are already present
+	merged := &Importer{
+		ReaderWriter:  readerWriter,
+		MergeStrategy: MergeStrategyMerge,
+		Input: jsonschema.Performer{
+			Name:     "merged",
+			StashIDs: stashIDs,
+		},
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagName, createdTag1, createdTag2}, false).Return([]*models.Tag{
+		{ID: existingTagID, Name: existingTagName},
+	}, nil).Once()
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+	for id, name := range map[int]string{existingTagID + 1: createdTag1, existingTagID + 2: createdTag2} {
+		name := name
+		tagReaderWriter.On("Create", testCtx, mock.MatchedBy(func(t models.Tag) bool {
+			return t.Name == name
+		})).Return(&models.Tag{ID: id, Name: name}, nil).Once()
+	}
+
+	readerWriter.On("Create", testCtx, mock.AnythingOfType("*models.Performer")).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Performer).ID = createdID
+	}).Return(nil).Once()
+	readerWriter.On("UpdateTags", testCtx, createdID, mock.Anything).Return(nil).Once()
+	readerWriter.On("UpdateImage", testCtx, createdID, imageBytes).Return(nil).Once()
+
+	readerWriter.On("Update", testCtx, mock.AnythingOfType("*models.Performer")).Return(nil).Once()
+	readerWriter.On("UpdateTags", testCtx, replacedID, mock.Anything).Return(nil).Maybe()
+	readerWriter.On("UpdateStashIDs", testCtx, replacedID, stashIDs).Return(nil).Once()
+
+	readerWriter.On("Find", testCtx, mergedID).Return(&models.Performer{ID: mergedID, Name: "merged"}, nil).Once()
+	readerWriter.On("Update", testCtx, mock.AnythingOfType("*models.Performer")).Return(nil).Once()
+	readerWriter.On("GetStashIDs", testCtx, mergedID).Return(stashIDs, nil).Once()
+	readerWriter.On("UpdateStashIDs", testCtx, mergedID, stashIDs).Return(nil).Once()
+
+	for _, i := range []*Importer{created, replaced, merged} {
+		assert.Nil(t, i.PreImport(testCtx))
+	}
+
+	createdChanges, err := created.CreateWithChanges(testCtx)
+	assert.Nil(t, err)
+	replacedChanges, err := replaced.UpdateWithChanges(testCtx, replacedID)
+	assert.Nil(t, err)
+	mergedChanges, err := merged.UpdateWithChanges(testCtx, mergedID)
+	assert.Nil(t, err)
+
+	assert.Nil(t, created.PostImport(testCtx, createdID))
+	assert.Nil(t, replaced.PostImport(testCtx, replacedID))
+	assert.Nil(t, merged.PostImport(testCtx, mergedID))
+
+	assert.Equal(t, ImportChanges{
+		ID:          createdID,
+		Created:     true,
+		TagsCreated: 2,
+		ImageSet:    true,
+	}, *createdChanges)
+	assert.Equal(t, ImportChanges{
+		ID:              replacedID,
+		Updated:         true,
+		StashIDsChanged: true,
+	}, *replacedChanges)
+	assert.Equal(t, ImportChanges{
+		ID:      mergedID,
+		Updated: true,
+	}, *mergedChanges)
+	assert.Equal(t, *mergedChanges, merged.Changes())
+
+	readerWriter.AssertExpectations(t)
+	tagReaderWriter.AssertExpectations(t)
+}
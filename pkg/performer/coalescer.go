@@ -0,0 +1,172 @@
+package performer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+)
+
+// ApplyFunc imports a single performer.
+type ApplyFunc func(ctx context.Context, input jsonschema.Performer) error
+
+// stopper stops a timer. It returns false if the timer has already fired.
+type stopper interface {
+	Stop() bool
+}
+
+// afterFunc calls f in its own goroutine once d has elapsed.
+type afterFunc func(d time.Duration, f func()) stopper
+
+func timeAfterFunc(d time.Duration, f func()) stopper {
+	return time.AfterFunc(d, f)
+}
+
+// coalescedImport is the state of the imports of a single performer.
+type coalescedImport struct {
+	// applyMutex serializes the imports of the performer
+	applyMutex sync.Mutex
+
+	// the following are guarded by the Coalescer mutex
+	input jsonschema.Performer
+	timer stopper
+	// generation is incremented each time an input is added
+	generation uint64
+	// applied is the generation of the last applied input
+	applied uint64
+}
+
+// Coalescer debounces repeated imports of the same performer. Imports are
+// keyed by the performer checksum. An import is applied once no other
+// import of the same performer has been added within the window, and only
+// the latest input is applied. Imports of the same performer are applied
+// one at a time, and an input is not applied once a later input has been
+// applied.
+type Coalescer struct {
+	window    time.Duration
+	apply     ApplyFunc
+	ctx       context.Context
+	afterFunc afterFunc
+
+	mutex   sync.Mutex
+	imports map[string]*coalescedImport
+	errors  []string
+
+	// wg counts the scheduled imports, including those that have fired and
+	// are in progress
+	wg sync.WaitGroup
+}
+
+// NewCoalescer creates a new Coalescer that calls apply for each coalesced
+// import. Flush must be called once all imports have been added.
+func NewCoalescer(ctx context.Context, window time.Duration, apply ApplyFunc) *Coalescer {
+	return &Coalescer{
+		window:    window,
+		apply:     apply,
+		ctx:       ctx,
+		afterFunc: timeAfterFunc,
+		imports:   make(map[string]*coalescedImport),
+	}
+}
+
+// Add queues the input to be imported, replacing any pending import of the
+// same performer.
+func (c *Coalescer) Add(input jsonschema.Performer) {
+	key := Checksum(input.Name, input.Disambiguation)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ci := c.imports[key]
+	if ci == nil {
+		ci = &coalescedImport{}
+		c.imports[key] = ci
+	}
+
+	if ci.timer != nil && ci.timer.Stop() {
+		c.wg.Done()
+	}
+
+	ci.generation++
+	ci.input = input
+
+	generation := ci.generation
+	c.wg.Add(1)
+	ci.timer = c.afterFunc(c.window, func() {
+		defer c.wg.Done()
+		c.fire(key, ci, generation)
+	})
+}
+
+// Flush applies all pending imports immediately and waits for in-progress
+// imports to complete. It returns an error if any of the imports failed.
+func (c *Coalescer) Flush() error {
+	type pending struct {
+		key        string
+		ci         *coalescedImport
+		generation uint64
+	}
+
+	var toApply []pending
+
+	c.mutex.Lock()
+	for key, ci := range c.imports {
+		// imports whose timers have fired are applied by the timer
+		if ci.timer != nil && ci.timer.Stop() {
+			c.wg.Done()
+			toApply = append(toApply, pending{key, ci, ci.generation})
+		}
+		ci.timer = nil
+	}
+	c.mutex.Unlock()
+
+	for _, p := range toApply {
+		c.fire(p.key, p.ci, p.generation)
+	}
+
+	c.wg.Wait()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	errs := c.errors
+	c.errors = nil
+	if len(errs) > 0 {
+		return fmt.Errorf("error importing performers: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// fire applies the input of the provided generation, unless it has been
+// replaced by a later input.
+func (c *Coalescer) fire(key string, ci *coalescedImport, generation uint64) {
+	ci.applyMutex.Lock()
+	defer ci.applyMutex.Unlock()
+
+	c.mutex.Lock()
+	if ci.generation != generation || ci.applied >= generation {
+		c.mutex.Unlock()
+		return
+	}
+	input := ci.input
+	ci.applied = generation
+	c.mutex.Unlock()
+
+	err := c.apply(c.ctx, input)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err != nil {
+		c.errors = append(c.errors, fmt.Sprintf("%s: %v", input.Name, err))
+	}
+
+	// remove the state once no later input has been added
+	if ci.generation == generation && c.imports[key] == ci {
+		delete(c.imports, key)
+	}
+}
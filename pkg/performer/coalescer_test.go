@@ -0,0 +1,198 @@
+package performer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+type testApplier struct {
+	mutex   sync.Mutex
+	applied map[string][]string
+}
+
+func (a *testApplier) apply(ctx context.Context, input jsonschema.Performer) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.applied == nil {
+		a.applied = make(map[string][]string)
+	}
+	a.applied[input.Name] = append(a.applied[input.Name], input.URL)
+
+	if input.Name == performerNameErr {
+		return errors.New("apply error")
+	}
+	return nil
+}
+
+func TestCoalescer(t *testing.T) {
+	a := &testApplier{}
+	c := NewCoalescer(testCtx, time.Hour, a.apply)
+
+	c.Add(jsonschema.Performer{Name: performerName, URL: "1"})
+	c.Add(jsonschema.Performer{Name: existingPerformerName, URL: "1"})
+	c.Add(jsonschema.Performer{Name: performerName, URL: "2"})
+	c.Add(jsonschema.Performer{Name: performerName, URL: "3"})
+
+	err := c.Flush()
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string][]string{
+		performerName:         {"3"},
+		existingPerformerName: {"1"},
+	}, a.applied)
+}
+
+func TestCoalescerDisambiguation(t *testing.T) {
+	a := &testApplier{}
+	c := NewCoalescer(testCtx, time.Hour, a.apply)
+
+	c.Add(jsonschema.Performer{Name: performerName, Disambiguation: "first", URL: "1"})
+	c.Add(jsonschema.Performer{Name: performerName, Disambiguation: "second", URL: "2"})
+
+	err := c.Flush()
+	assert.Nil(t, err)
+
+	// performers with different disambiguations are not coalesced
+	assert.ElementsMatch(t, []string{"1", "2"}, a.applied[performerName])
+}
+
+// fakeTimers records the timers started by a Coalescer, so that tests can
+// fire them.
+type fakeTimers struct {
+	mutex  sync.Mutex
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	mutex   sync.Mutex
+	f       func()
+	stopped bool
+	fired   bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.fired || t.stopped {
+		return false
+	}
+	t.stopped = true
+	return true
+}
+
+func (t *fakeTimer) fire() {
+	t.mutex.Lock()
+	if t.fired || t.stopped {
+		t.mutex.Unlock()
+		return
+	}
+	t.fired = true
+	t.mutex.Unlock()
+
+	t.f()
+}
+
+func (ft *fakeTimers) afterFunc(d time.Duration, f func()) stopper {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+
+	t := &fakeTimer{f: f}
+	ft.timers = append(ft.timers, t)
+	return t
+}
+
+func (ft *fakeTimers) timer(n int) *fakeTimer {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+
+	return ft.timers[n]
+}
+
+func newTestCoalescer(apply ApplyFunc) (*Coalescer, *fakeTimers) {
+	ft := &fakeTimers{}
+	c := NewCoalescer(testCtx, time.Hour, apply)
+	c.afterFunc = ft.afterFunc
+	return c, ft
+}
+
+func TestCoalescerWindow(t *testing.T) {
+	a := &testApplier{}
+	c, ft := newTestCoalescer(a.apply)
+
+	c.Add(jsonschema.Performer{Name: performerName, URL: "1"})
+	ft.timer(0).fire()
+	c.Add(jsonschema.Performer{Name: performerName, URL: "2"})
+
+	err := c.Flush()
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"1", "2"}, a.applied[performerName])
+}
+
+func TestCoalescerSerialized(t *testing.T) {
+	a := &testApplier{}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var running int32
+	apply := func(ctx context.Context, input jsonschema.Performer) error {
+		if atomic.AddInt32(&running, 1) > 1 {
+			t.Errorf("imports of the same performer applied concurrently")
+		}
+		defer atomic.AddInt32(&running, -1)
+
+		if input.URL == "1" {
+			close(started)
+			<-release
+		}
+		return a.apply(ctx, input)
+	}
+
+	c, ft := newTestCoalescer(apply)
+
+	// the first import is slow
+	c.Add(jsonschema.Performer{Name: performerName, URL: "1"})
+	go ft.timer(0).fire()
+	<-started
+
+	// the second import fires while the first is being applied, and must
+	// wait for it
+	c.Add(jsonschema.Performer{Name: performerName, URL: "2"})
+	fired := make(chan struct{})
+	go func() {
+		ft.timer(1).fire()
+		close(fired)
+	}()
+
+	// the second import is replaced before it is applied
+	c.Add(jsonschema.Performer{Name: performerName, URL: "3"})
+
+	close(release)
+	<-fired
+
+	err := c.Flush()
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"1", "3"}, a.applied[performerName])
+}
+
+func TestCoalescerError(t *testing.T) {
+	a := &testApplier{}
+	c := NewCoalescer(testCtx, time.Hour, a.apply)
+
+	c.Add(jsonschema.Performer{Name: performerNameErr})
+	c.Add(jsonschema.Performer{Name: performerName})
+
+	err := c.Flush()
+	assert.NotNil(t, err)
+	assert.Len(t, a.applied, 2)
+}
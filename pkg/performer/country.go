@@ -0,0 +1,33 @@
+package performer
+
+import (
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// InvalidCountryBehaviour determines what happens when the input country is
+// not a known country.
+type InvalidCountryBehaviour string
+
+const (
+	// InvalidCountryIgnore reports a warning and leaves the country
+	// unchanged. This is the default.
+	InvalidCountryIgnore InvalidCountryBehaviour = "IGNORE"
+	// InvalidCountryFail fails the import.
+	InvalidCountryFail InvalidCountryBehaviour = "FAIL"
+)
+
+// validateCountry returns an error if the input country is unknown and
+// InvalidCountryBehaviour is InvalidCountryFail.
+func (i *Importer) validateCountry() error {
+	if !i.CanonicalizeCountry || i.InvalidCountryBehaviour != InvalidCountryFail || i.Input.Country == "" {
+		return nil
+	}
+
+	if _, valid := utils.ParseCountry(i.Input.Country); !valid {
+		return fmt.Errorf("unknown country %q", i.Input.Country)
+	}
+
+	return nil
+}
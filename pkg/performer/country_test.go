@@ -0,0 +1,52 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImporterPreImportCountry(t *testing.T) {
+	tests := []struct {
+		name         string
+		country      string
+		canonicalize bool
+		behaviour    InvalidCountryBehaviour
+		want         string
+		wantErr      bool
+		wantWarnings int
+	}{
+		{"verbatim", "United States", false, "", "United States", false, 0},
+		{"verbatim unknown", "Atlantis", false, InvalidCountryFail, "Atlantis", false, 0},
+		{"name", "United States", true, "", "US", false, 0},
+		{"alpha-2", "us", true, "", "US", false, 0},
+		{"alpha-3", "USA", true, "", "US", false, 0},
+		{"unknown", "Atlantis", true, "", "Atlantis", false, 1},
+		{"unknown fail", "Atlantis", true, InvalidCountryFail, "", true, 0},
+		{"empty", "", true, InvalidCountryFail, "", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				Input: jsonschema.Performer{
+					Name:    performerName,
+					Country: tt.country,
+				},
+				CanonicalizeCountry:     tt.canonicalize,
+				InvalidCountryBehaviour: tt.behaviour,
+			}
+
+			err := i.PreImport(testCtx)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, i.performer.Country)
+			assert.Len(t, i.Warnings(), tt.wantWarnings)
+		})
+	}
+}
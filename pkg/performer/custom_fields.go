@@ -0,0 +1,92 @@
+package performer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// ErrEmptyCustomFieldName is returned when a custom field has an empty name.
+var ErrEmptyCustomFieldName = errors.New("custom field name must not be empty")
+
+func validateCustomFields(fields map[string]string) error {
+	if _, found := fields[""]; found {
+		return ErrEmptyCustomFieldName
+	}
+
+	return nil
+}
+
+// copyCustomFields returns a copy of fields. Nil and empty maps are
+// preserved, since an empty map clears the custom fields.
+func copyCustomFields(fields map[string]string) map[string]string {
+	if fields == nil {
+		return nil
+	}
+
+	ret := make(map[string]string, len(fields))
+	for k, v := range fields {
+		ret[k] = v
+	}
+
+	return ret
+}
+
+// postImportCustomFields sets the custom fields of the performer. Omitted
+// custom fields are left unchanged, and an empty map clears them. When
+// merging, empty custom fields are left unchanged, and the input fields are
+// added to the existing fields unless MergeRelationships is
+// RelationshipUpdateModeSet.
+func (i *Importer) postImportCustomFields(ctx context.Context, id int) error {
+	fields, set, err := i.customFieldsToSet(ctx, id)
+	if err != nil || !set {
+		return err
+	}
+
+	if err := i.ReaderWriter.UpdateCustomFields(ctx, id, fields); err != nil {
+		return fmt.Errorf("error setting performer custom fields: %v", err)
+	}
+
+	i.changed = append(i.changed, "custom_fields")
+
+	return nil
+}
+
+// customFieldsToSet returns the custom fields to set on the performer. It
+// returns false if the custom fields are left unchanged.
+func (i *Importer) customFieldsToSet(ctx context.Context, id int) (map[string]string, bool, error) {
+	fields := i.performer.CustomFields
+
+	if i.fieldMask != nil {
+		if !i.fieldMask["custom_fields"] {
+			return nil, false, nil
+		}
+	} else if fields == nil {
+		return nil, false, nil
+	}
+
+	// a created performer has no existing custom fields to replace
+	if len(fields) == 0 && (!i.updated || i.merging()) {
+		return nil, false, nil
+	}
+
+	if i.updated && i.merging() && i.mergeRelationships() == models.RelationshipUpdateModeAdd {
+		existing, err := i.ReaderWriter.GetCustomFields(ctx, id)
+		if err != nil {
+			return nil, false, fmt.Errorf("error getting performer custom fields: %v", err)
+		}
+
+		merged := copyCustomFields(existing)
+		if merged == nil {
+			merged = make(map[string]string)
+		}
+		for k, v := range fields {
+			merged[k] = v
+		}
+		fields = merged
+	}
+
+	return fields, true, nil
+}
@@ -0,0 +1,138 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var customFields = map[string]string{
+	"pronouns":    "they/them",
+	"internal_id": "42",
+}
+
+func TestImporterPostImportCustomFields(t *testing.T) {
+	existing := map[string]string{
+		"pronouns": "she/her",
+		"source":   "scene",
+	}
+
+	tests := []struct {
+		name          string
+		mergeStrategy MergeStrategy
+		fields        map[string]string
+		updated       bool
+		// want is the fields set on the performer, or nil if unchanged
+		want map[string]string
+	}{
+		{"set", "", customFields, false, customFields},
+		{"overwrite", "", customFields, true, customFields},
+		{"omitted", "", nil, true, nil},
+		{"empty clears", "", map[string]string{}, true, map[string]string{}},
+		{"empty on create", "", map[string]string{}, false, nil},
+		{"merge", MergeStrategyMerge, customFields, true, map[string]string{
+			"pronouns":    "they/them",
+			"internal_id": "42",
+			"source":      "scene",
+		}},
+		{"merge empty", MergeStrategyMerge, map[string]string{}, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+
+			i := Importer{
+				ReaderWriter:  readerWriter,
+				MergeStrategy: tt.mergeStrategy,
+				Input: jsonschema.Performer{
+					Name:         performerName,
+					CustomFields: tt.fields,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+
+			i.updated = tt.updated
+
+			// updated performers have their tags replaced
+			readerWriter.On("UpdateTags", testCtx, performerID, []int(nil)).Return(nil).Maybe()
+			readerWriter.On("GetCustomFields", testCtx, performerID).Return(existing, nil).Maybe()
+			if tt.want != nil {
+				readerWriter.On("UpdateCustomFields", testCtx, performerID, tt.want).Return(nil).Once()
+			}
+
+			err = i.PostImport(testCtx, performerID)
+			assert.Nil(t, err)
+
+			if tt.want != nil {
+				assert.Contains(t, i.Changed(), "custom_fields")
+			} else {
+				assert.NotContains(t, i.Changed(), "custom_fields")
+			}
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImporterPreImportCustomFieldsEmptyName(t *testing.T) {
+	i := Importer{
+		Input: jsonschema.Performer{
+			Name: performerName,
+			CustomFields: map[string]string{
+				"": "value",
+			},
+		},
+	}
+
+	err := i.PreImport(testCtx)
+	assert.ErrorIs(t, err, ErrEmptyCustomFieldName)
+}
+
+func TestCustomFieldsRoundTrip(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	input := map[string]string{
+		"pronouns": "they/them",
+		"empty":    "",
+		"notes":    "line 1\nline 2",
+	}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		Input: jsonschema.Performer{
+			Name:         performerName,
+			CustomFields: input,
+		},
+	}
+
+	var stored map[string]string
+	readerWriter.On("UpdateCustomFields", testCtx, performerID, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(2).(map[string]string)
+	}).Return(nil).Once()
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	err = i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+
+	readerWriter.On("GetImage", testCtx, performerID).Return(nil, nil).Once()
+	readerWriter.On("GetStashIDs", testCtx, performerID).Return(nil, nil).Once()
+	readerWriter.On("GetCustomFields", testCtx, performerID).Return(stored, nil).Once()
+
+	json, err := ToJSON(testCtx, readerWriter, &models.Performer{
+		ID:   performerID,
+		Name: performerName,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, input, json.CustomFields)
+
+	readerWriter.AssertExpectations(t)
+}
@@ -0,0 +1,48 @@
+package performer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+// DefaultDateLayouts are the layouts accepted for performer dates if none
+// are configured, in addition to the formats accepted by
+// utils.ParseDateStringAsTime. Partial dates are set to the first day of the
+// year or month. Layouts that are ambiguous between day and month order are
+// deliberately omitted.
+var DefaultDateLayouts = []string{
+	"2006/01/02",
+	"2006.01.02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	"2006-01",
+	"2006/01",
+	"January 2006",
+	"Jan 2006",
+	"2006",
+}
+
+// ParseDate parses s using the formats accepted by
+// utils.ParseDateStringAsTime, then each of layouts in order. Month and day
+// names are matched case-insensitively by time.Parse.
+func ParseDate(s string, layouts []string) (*models.Date, error) {
+	s = strings.TrimSpace(s)
+
+	if t, err := utils.ParseDateStringAsTime(s); err == nil {
+		return &models.Date{Time: t}, nil
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &models.Date{Time: t}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unrecognised date format %q", s)
+}
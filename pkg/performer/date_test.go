@@ -0,0 +1,43 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		input   string
+		layouts []string
+		want    string
+		wantErr bool
+	}{
+		{"1990-05-01", nil, "1990-05-01", false},
+		{"1990-05-01T10:00:00Z", nil, "1990-05-01", false},
+		{"1990/05/01", DefaultDateLayouts, "1990-05-01", false},
+		{"May 1, 1990", DefaultDateLayouts, "1990-05-01", false},
+		{" 1 may 1990 ", DefaultDateLayouts, "1990-05-01", false},
+		{"1990-05", DefaultDateLayouts, "1990-05-01", false},
+		{"May 1990", DefaultDateLayouts, "1990-05-01", false},
+		{"1990", DefaultDateLayouts, "1990-01-01", false},
+		{"1990/05/01", nil, "", true},
+		{"01/05/1990", DefaultDateLayouts, "", true},
+		{"not a date", DefaultDateLayouts, "", true},
+		{"05-01-1990", []string{"01-02-2006"}, "1990-05-01", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDate(tt.input, tt.layouts)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			if assert.Nil(t, err) {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
@@ -7,40 +7,48 @@ import (
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/models/json"
 	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 	"github.com/stashapp/stash/pkg/utils"
 )
 
 type ImageStashIDGetter interface {
 	GetImage(ctx context.Context, performerID int) ([]byte, error)
+	GetCustomFields(ctx context.Context, performerID int) (map[string]string, error)
 	models.StashIDLoader
 }
 
 // ToJSON converts a Performer object into its JSON equivalent.
 func ToJSON(ctx context.Context, reader ImageStashIDGetter, performer *models.Performer) (*jsonschema.Performer, error) {
 	newPerformerJSON := jsonschema.Performer{
-		Name:          performer.Name,
-		Gender:        performer.Gender.String(),
-		URL:           performer.URL,
-		Ethnicity:     performer.Ethnicity,
-		Country:       performer.Country,
-		EyeColor:      performer.EyeColor,
-		Height:        performer.Height,
-		Measurements:  performer.Measurements,
-		FakeTits:      performer.FakeTits,
-		CareerLength:  performer.CareerLength,
-		Tattoos:       performer.Tattoos,
-		Piercings:     performer.Piercings,
-		Aliases:       performer.Aliases,
-		Twitter:       performer.Twitter,
-		Instagram:     performer.Instagram,
-		Favorite:      performer.Favorite,
-		Details:       performer.Details,
-		HairColor:     performer.HairColor,
-		IgnoreAutoTag: performer.IgnoreAutoTag,
-		CreatedAt:     json.JSONTime{Time: performer.CreatedAt},
-		UpdatedAt:     json.JSONTime{Time: performer.UpdatedAt},
+		Name:           performer.Name,
+		SortName:       performer.SortName,
+		SortNameLocale: performer.SortNameLocale,
+		Disambiguation: performer.Disambiguation,
+		Gender:         performer.Gender.String(),
+		URL:            performer.URL,
+		Ethnicity:      performer.Ethnicity,
+		Country:        performer.Country,
+		EyeColor:       performer.EyeColor,
+		Height:         performer.Height,
+		Measurements:   performer.Measurements,
+		FakeTits:       performer.FakeTits,
+		CareerLength:   performer.CareerLength,
+		Tattoos:        performer.Tattoos,
+		Piercings:      performer.Piercings,
+		Aliases:        performer.Aliases,
+		Twitter:        performer.Twitter,
+		Instagram:      performer.Instagram,
+		Favorite:       performer.Favorite,
+		Details:        performer.Details,
+		HairColor:      performer.HairColor,
+		IgnoreAutoTag:  performer.IgnoreAutoTag,
+		PropagateTags:  performer.PropagateTags,
+		CreatedAt:      json.JSONTime{Time: performer.CreatedAt},
+		UpdatedAt:      json.JSONTime{Time: performer.UpdatedAt},
 	}
 
+	newPerformerJSON.URLs = exportURLs(performer)
+
 	if performer.Birthdate != nil {
 		newPerformerJSON.Birthdate = performer.Birthdate.String()
 	}
@@ -75,9 +83,31 @@ func ToJSON(ctx context.Context, reader ImageStashIDGetter, performer *models.Pe
 
 	newPerformerJSON.StashIDs = ret
 
+	customFields, err := reader.GetCustomFields(ctx, performer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting performer custom fields: %v", err)
+	}
+
+	if len(customFields) > 0 {
+		newPerformerJSON.CustomFields = copyCustomFields(customFields)
+	}
+
 	return &newPerformerJSON, nil
 }
 
+// exportURLs returns the urls of the performer, starting with the url, with
+// duplicate and empty URLs omitted.
+func exportURLs(performer *models.Performer) []string {
+	var ret []string
+	for _, u := range append([]string{performer.URL}, performer.URLs...) {
+		if u != "" {
+			ret = stringslice.StrAppendUnique(ret, u)
+		}
+	}
+
+	return ret
+}
+
 func GetIDs(performers []*models.Performer) []int {
 	var results []int
 	for _, performer := range performers {
@@ -1,9 +1,9 @@
 package performer
 
 import (
+	"encoding/base64"
 	"errors"
 
-	"github.com/stashapp/stash/pkg/hash/md5"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/models/json"
 	"github.com/stashapp/stash/pkg/models/jsonschema"
@@ -21,25 +21,29 @@ const (
 )
 
 const (
-	performerName = "testPerformer"
-	url           = "url"
-	aliases       = "aliases"
-	careerLength  = "careerLength"
-	country       = "country"
-	ethnicity     = "ethnicity"
-	eyeColor      = "eyeColor"
-	fakeTits      = "fakeTits"
-	gender        = "gender"
-	height        = "height"
-	instagram     = "instagram"
-	measurements  = "measurements"
-	piercings     = "piercings"
-	tattoos       = "tattoos"
-	twitter       = "twitter"
-	details       = "details"
-	hairColor     = "hairColor"
+	performerName  = "testPerformer"
+	sortName       = "sortName"
+	sortLocale     = "sv"
+	disambiguation = "disambiguation"
+	url            = "url"
+	aliases        = "aliases"
+	careerLength   = "careerLength"
+	country        = "country"
+	ethnicity      = "ethnicity"
+	eyeColor       = "eyeColor"
+	fakeTits       = "fakeTits"
+	gender         = "FEMALE"
+	height         = "height"
+	instagram      = "instagram"
+	measurements   = "measurements"
+	piercings      = "piercings"
+	tattoos        = "tattoos"
+	twitter        = "twitter"
+	details        = "details"
+	hairColor      = "hairColor"
 
 	autoTagIgnored = true
+	tagsPropagated = true
 )
 
 var (
@@ -47,7 +51,12 @@ var (
 	weight = 60
 )
 
-var imageBytes = []byte("imageBytes")
+// image is a 1x1 PNG
+const image = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAAAAAA6fptVAAAAD0lEQVR4nAACAP3/AgADAAAGAAMh/KwGAAAAAElFTkSuQmCC"
+
+var urls = []string{url, "otherURL"}
+
+var imageBytes, _ = base64.StdEncoding.DecodeString(image)
 
 var stashID = models.StashID{
 	StashID:  "StashID",
@@ -57,8 +66,6 @@ var stashIDs = []models.StashID{
 	stashID,
 }
 
-const image = "aW1hZ2VCeXRlcw=="
-
 var birthDate = models.NewDate("2001-01-01")
 var deathDate = models.NewDate("2021-02-02")
 
@@ -69,33 +76,38 @@ var (
 
 func createFullPerformer(id int, name string) *models.Performer {
 	return &models.Performer{
-		ID:            id,
-		Name:          name,
-		Checksum:      md5.FromString(name),
-		URL:           url,
-		Aliases:       aliases,
-		Birthdate:     &birthDate,
-		CareerLength:  careerLength,
-		Country:       country,
-		Ethnicity:     ethnicity,
-		EyeColor:      eyeColor,
-		FakeTits:      fakeTits,
-		Favorite:      true,
-		Gender:        gender,
-		Height:        height,
-		Instagram:     instagram,
-		Measurements:  measurements,
-		Piercings:     piercings,
-		Tattoos:       tattoos,
-		Twitter:       twitter,
-		CreatedAt:     createTime,
-		UpdatedAt:     updateTime,
-		Rating:        &rating,
-		Details:       details,
-		DeathDate:     &deathDate,
-		HairColor:     hairColor,
-		Weight:        &weight,
-		IgnoreAutoTag: autoTagIgnored,
+		ID:             id,
+		Name:           name,
+		Checksum:       Checksum(name, disambiguation),
+		SortName:       sortName,
+		SortNameLocale: sortLocale,
+		Disambiguation: disambiguation,
+		URL:            url,
+		URLs:           urls,
+		Aliases:        aliases,
+		Birthdate:      &birthDate,
+		CareerLength:   careerLength,
+		Country:        country,
+		Ethnicity:      ethnicity,
+		EyeColor:       eyeColor,
+		FakeTits:       fakeTits,
+		Favorite:       true,
+		Gender:         gender,
+		Height:         height,
+		Instagram:      instagram,
+		Measurements:   measurements,
+		Piercings:      piercings,
+		Tattoos:        tattoos,
+		Twitter:        twitter,
+		CreatedAt:      createTime,
+		UpdatedAt:      updateTime,
+		Rating:         &rating,
+		Details:        details,
+		DeathDate:      &deathDate,
+		HairColor:      hairColor,
+		Weight:         &weight,
+		IgnoreAutoTag:  autoTagIgnored,
+		PropagateTags:  tagsPropagated,
 	}
 }
 
@@ -109,23 +121,27 @@ func createEmptyPerformer(id int) models.Performer {
 
 func createFullJSONPerformer(name string, image string) *jsonschema.Performer {
 	return &jsonschema.Performer{
-		Name:         name,
-		URL:          url,
-		Aliases:      aliases,
-		Birthdate:    birthDate.String(),
-		CareerLength: careerLength,
-		Country:      country,
-		Ethnicity:    ethnicity,
-		EyeColor:     eyeColor,
-		FakeTits:     fakeTits,
-		Favorite:     true,
-		Gender:       gender,
-		Height:       height,
-		Instagram:    instagram,
-		Measurements: measurements,
-		Piercings:    piercings,
-		Tattoos:      tattoos,
-		Twitter:      twitter,
+		Name:           name,
+		URL:            url,
+		URLs:           urls,
+		Aliases:        aliases,
+		Birthdate:      birthDate.String(),
+		SortName:       sortName,
+		SortNameLocale: sortLocale,
+		Disambiguation: disambiguation,
+		CareerLength:   careerLength,
+		Country:        country,
+		Ethnicity:      ethnicity,
+		EyeColor:       eyeColor,
+		FakeTits:       fakeTits,
+		Favorite:       true,
+		Gender:         gender,
+		Height:         height,
+		Instagram:      instagram,
+		Measurements:   measurements,
+		Piercings:      piercings,
+		Tattoos:        tattoos,
+		Twitter:        twitter,
 		CreatedAt: json.JSONTime{
 			Time: createTime,
 		},
@@ -142,6 +158,7 @@ func createFullJSONPerformer(name string, image string) *jsonschema.Performer {
 			stashID,
 		},
 		IgnoreAutoTag: autoTagIgnored,
+		PropagateTags: tagsPropagated,
 	}
 }
 
@@ -165,10 +182,13 @@ type testScenario struct {
 var scenarios []testScenario
 
 func initTestTable() {
+	fullJSON := createFullJSONPerformer(performerName, image)
+	fullJSON.CustomFields = customFields
+
 	scenarios = []testScenario{
 		{
 			*createFullPerformer(performerID, performerName),
-			createFullJSONPerformer(performerName, image),
+			fullJSON,
 			false,
 		},
 		{
@@ -198,6 +218,9 @@ func TestToJSON(t *testing.T) {
 	mockPerformerReader.On("GetStashIDs", testCtx, performerID).Return(stashIDs, nil).Once()
 	mockPerformerReader.On("GetStashIDs", testCtx, noImageID).Return(nil, nil).Once()
 
+	mockPerformerReader.On("GetCustomFields", testCtx, performerID).Return(customFields, nil).Once()
+	mockPerformerReader.On("GetCustomFields", testCtx, noImageID).Return(nil, nil).Once()
+
 	for i, s := range scenarios {
 		tag := s.input
 		json, err := ToJSON(testCtx, mockPerformerReader, &tag)
@@ -214,3 +237,27 @@ func TestToJSON(t *testing.T) {
 
 	mockPerformerReader.AssertExpectations(t)
 }
+
+func TestExportURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		urls []string
+		want []string
+	}{
+		{"none", "", nil, nil},
+		{"legacy", url, nil, []string{url}},
+		{"multiple", url, urls, urls},
+		{"url not in urls", "first", urls, append([]string{"first"}, urls...)},
+		{"duplicates", url, []string{url, "", "otherURL", url, "otherURL"}, urls},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exportURLs(&models.Performer{
+				URL:  tt.url,
+				URLs: tt.urls,
+			}))
+		})
+	}
+}
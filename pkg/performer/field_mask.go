@@ -0,0 +1,224 @@
+package performer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+)
+
+const fieldMaskName = "_fields"
+
+// performerJSONFieldNames returns the json names of the fields of
+// jsonschema.Performer, in declaration order.
+func performerJSONFieldNames() []string {
+	t := reflect.TypeOf(jsonschema.Performer{})
+	var ret []string
+	for f := 0; f < t.NumField(); f++ {
+		name := strings.Split(t.Field(f).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			ret = append(ret, name)
+		}
+	}
+
+	return ret
+}
+
+// fieldMask is a set of json field names.
+type fieldMask map[string]bool
+
+func newFieldMask(fields []string) (fieldMask, error) {
+	valid := make(map[string]bool)
+	for _, name := range performerJSONFieldNames() {
+		valid[name] = true
+	}
+
+	ret := make(fieldMask)
+	for _, f := range fields {
+		if !valid[f] || f == fieldMaskName {
+			return nil, fmt.Errorf("unknown field %q in %s", f, fieldMaskName)
+		}
+		ret[f] = true
+	}
+
+	return ret, nil
+}
+
+// presentFieldMask returns a mask containing the fields present in the
+// input. If the input was not decoded from JSON, the fields that are not
+// empty are present. Removing tags updates the tags, so remove_tags implies
+// tags.
+func presentFieldMask(input jsonschema.Performer) fieldMask {
+	ret := make(fieldMask)
+
+	v := reflect.ValueOf(input)
+	t := v.Type()
+	for f := 0; f < t.NumField(); f++ {
+		name := strings.Split(t.Field(f).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || name == fieldMaskName {
+			continue
+		}
+
+		present := input.Present[name]
+		if input.Present == nil {
+			present = !v.Field(f).IsZero()
+		}
+
+		if present {
+			ret[name] = true
+		}
+	}
+
+	if ret["remove_tags"] {
+		ret["tags"] = true
+	}
+
+	return ret
+}
+
+// newFullFieldMask returns a mask containing all fields.
+func newFullFieldMask() fieldMask {
+	ret := make(fieldMask)
+	for _, name := range performerJSONFieldNames() {
+		ret[name] = true
+	}
+
+	return ret
+}
+
+// without returns a copy of the mask without the provided fields.
+func (m fieldMask) without(names ...string) fieldMask {
+	ret := make(fieldMask)
+	for name := range m {
+		ret[name] = true
+	}
+	for _, name := range names {
+		delete(ret, name)
+	}
+
+	return ret
+}
+
+// apply returns a copy of input with all fields not in the mask set to
+// their zero value. The name is always retained, since it is used to find
+// the existing performer.
+func (m fieldMask) apply(input jsonschema.Performer) jsonschema.Performer {
+	ret := jsonschema.Performer{
+		Name:   input.Name,
+		Fields: input.Fields,
+	}
+
+	src := reflect.ValueOf(input)
+	dest := reflect.ValueOf(&ret).Elem()
+	t := src.Type()
+	for f := 0; f < t.NumField(); f++ {
+		name := strings.Split(t.Field(f).Tag.Get("json"), ",")[0]
+		if m[name] {
+			dest.Field(f).Set(src.Field(f))
+		}
+	}
+
+	return ret
+}
+
+// partial returns a PerformerPartial setting only the fields of p that are
+// in the mask.
+func (m fieldMask) partial(p models.Performer) models.PerformerPartial {
+	partial := models.NewPerformerPartial()
+
+	if m["name"] {
+		partial.Name = models.NewOptionalString(p.Name)
+		partial.Checksum = models.NewOptionalString(p.Checksum)
+	}
+	if m["disambiguation"] {
+		partial.Disambiguation = models.NewOptionalString(p.Disambiguation)
+	}
+	if m["sort_name"] {
+		partial.SortName = models.NewOptionalString(p.SortName)
+	}
+	if m["sort_name_locale"] {
+		partial.SortNameLocale = models.NewOptionalString(p.SortNameLocale)
+	}
+	if m["gender"] {
+		partial.Gender = models.NewOptionalString(p.Gender.String())
+	}
+	// the url is the first of the urls, so they are always set together
+	if m["url"] || m["urls"] {
+		partial.URL = models.NewOptionalString(p.URL)
+		urls := p.URLs
+		partial.URLs = &urls
+	}
+	if m["twitter"] {
+		partial.Twitter = models.NewOptionalString(p.Twitter)
+	}
+	if m["instagram"] {
+		partial.Instagram = models.NewOptionalString(p.Instagram)
+	}
+	if m["birthdate"] {
+		partial.Birthdate = models.NewOptionalDatePtr(p.Birthdate)
+	}
+	if m["ethnicity"] {
+		partial.Ethnicity = models.NewOptionalString(p.Ethnicity)
+	}
+	if m["country"] {
+		partial.Country = models.NewOptionalString(p.Country)
+	}
+	if m["eye_color"] {
+		partial.EyeColor = models.NewOptionalString(p.EyeColor)
+	}
+	if m["height"] {
+		partial.Height = models.NewOptionalString(p.Height)
+	}
+	if m["measurements"] {
+		partial.Measurements = models.NewOptionalString(p.Measurements)
+	}
+	if m["fake_tits"] {
+		partial.FakeTits = models.NewOptionalString(p.FakeTits)
+	}
+	if m["career_length"] {
+		partial.CareerLength = models.NewOptionalString(p.CareerLength)
+	}
+	if m["tattoos"] {
+		partial.Tattoos = models.NewOptionalString(p.Tattoos)
+	}
+	if m["piercings"] {
+		partial.Piercings = models.NewOptionalString(p.Piercings)
+	}
+	if m["aliases"] {
+		partial.Aliases = models.NewOptionalString(p.Aliases)
+	}
+	if m["favorite"] {
+		partial.Favorite = models.NewOptionalBool(p.Favorite)
+	}
+	if m["created_at"] {
+		partial.CreatedAt = models.NewOptionalTime(p.CreatedAt)
+	}
+	if m["updated_at"] {
+		partial.UpdatedAt = models.NewOptionalTime(p.UpdatedAt)
+	}
+	if m["rating"] {
+		partial.Rating = models.NewOptionalIntPtr(p.Rating)
+	}
+	if m["details"] {
+		partial.Details = models.NewOptionalString(p.Details)
+	}
+	if m["death_date"] {
+		partial.DeathDate = models.NewOptionalDatePtr(p.DeathDate)
+	}
+	if m["hair_color"] {
+		partial.HairColor = models.NewOptionalString(p.HairColor)
+	}
+	if m["weight"] {
+		partial.Weight = models.NewOptionalIntPtr(p.Weight)
+	}
+	if m["ignore_auto_tag"] {
+		partial.IgnoreAutoTag = models.NewOptionalBool(p.IgnoreAutoTag)
+	}
+	if m["propagate_tags"] {
+		partial.PropagateTags = models.NewOptionalBool(p.PropagateTags)
+	}
+
+	return partial
+}
@@ -0,0 +1,60 @@
+package performer
+
+import (
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// InvalidGenderBehaviour determines what happens when the input gender is
+// not a known gender.
+type InvalidGenderBehaviour string
+
+const (
+	// InvalidGenderIgnore reports a warning and ignores the input gender.
+	// The gender is set to DefaultGender, or left unset. This is the
+	// default.
+	InvalidGenderIgnore InvalidGenderBehaviour = "IGNORE"
+	// InvalidGenderFail fails the import.
+	InvalidGenderFail InvalidGenderBehaviour = "FAIL"
+)
+
+// genderSynonyms maps legacy and common gender values to their GenderEnum
+// values. Keys are lower case.
+var genderSynonyms = map[string]models.GenderEnum{
+	"m":                 models.GenderEnumMale,
+	"man":               models.GenderEnumMale,
+	"f":                 models.GenderEnumFemale,
+	"woman":             models.GenderEnumFemale,
+	"trans male":        models.GenderEnumTransgenderMale,
+	"trans man":         models.GenderEnumTransgenderMale,
+	"transgender man":   models.GenderEnumTransgenderMale,
+	"ftm":               models.GenderEnumTransgenderMale,
+	"trans female":      models.GenderEnumTransgenderFemale,
+	"trans woman":       models.GenderEnumTransgenderFemale,
+	"transgender woman": models.GenderEnumTransgenderFemale,
+	"mtf":               models.GenderEnumTransgenderFemale,
+	"nonbinary":         models.GenderEnumNonBinary,
+	"enby":              models.GenderEnumNonBinary,
+	"genderqueer":       models.GenderEnumNonBinary,
+	"intersexual":       models.GenderEnumIntersex,
+}
+
+// ParseGender returns the gender represented by s. s may be a GenderEnum
+// value in any case, using spaces or hyphens in place of underscores, or one
+// of a small set of synonyms such as "woman". It returns false if s is not
+// a known gender.
+func ParseGender(s string) (models.GenderEnum, bool) {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+
+	if g, found := genderSynonyms[s]; found {
+		return g, true
+	}
+
+	g := models.GenderEnum(strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(s)))
+	if g.IsValid() {
+		return g, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,36 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGender(t *testing.T) {
+	tests := []struct {
+		input     string
+		want      models.GenderEnum
+		wantValid bool
+	}{
+		{"FEMALE", models.GenderEnumFemale, true},
+		{"female", models.GenderEnumFemale, true},
+		{" Male ", models.GenderEnumMale, true},
+		{"transgender female", models.GenderEnumTransgenderFemale, true},
+		{"Non-Binary", models.GenderEnumNonBinary, true},
+		{"woman", models.GenderEnumFemale, true},
+		{"Trans  Man", models.GenderEnumTransgenderMale, true},
+		{"F", models.GenderEnumFemale, true},
+		{"Femal", "", false},
+		{"", "", false},
+		{"asdf", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, valid := ParseGender(tt.input)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantValid, valid)
+		})
+	}
+}
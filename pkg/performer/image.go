@@ -0,0 +1,420 @@
+package performer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	stdimage "image"
+	"image/gif"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"time"
+
+	// register the decoders used to validate image headers
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/stashapp/stash/pkg/utils"
+)
+
+const (
+	// DefaultImageFetchTimeout is the default time allowed to fetch an image
+	// from a URL, including reading the response.
+	DefaultImageFetchTimeout = 60 * time.Second
+
+	// DefaultImageMaxSize is the default maximum decoded size of an image.
+	DefaultImageMaxSize = 10 * 1024 * 1024
+
+	// imageHeaderSize is the number of bytes decoded to check the header of
+	// an image deferred to the ImageQueue.
+	imageHeaderSize = 3 * 1024
+)
+
+// DefaultImageFormats are the MIME types of the images accepted by default.
+var DefaultImageFormats = []string{"image/jpeg", "image/png", "image/webp"}
+
+var (
+	// ErrImageTooLarge is returned when an image exceeds the maximum size.
+	ErrImageTooLarge = errors.New("image too large")
+	// ErrImageDecode is returned when an image cannot be decoded.
+	ErrImageDecode = errors.New("invalid image")
+	// ErrImageUnsupportedFormat is returned when an image is not in one of
+	// the accepted formats.
+	ErrImageUnsupportedFormat = errors.New("unsupported image format")
+	// ErrImageMIMEMismatch is returned when the MIME type declared by an
+	// image data URI does not match the detected format.
+	ErrImageMIMEMismatch = errors.New("image format does not match declared MIME type")
+	// ErrImageAnimated is returned when an image is animated and animated
+	// images are not accepted.
+	ErrImageAnimated = errors.New("animated images are not supported")
+	// ErrImageFetch is returned when an image cannot be read from a path
+	// or URL.
+	ErrImageFetch = errors.New("error fetching image")
+)
+
+// ImageLimits constrains the images accepted on import. The zero value
+// accepts still JPEG, PNG and WebP images of up to DefaultImageMaxSize
+// bytes.
+type ImageLimits struct {
+	// MaxSize is the maximum decoded size of an image in bytes. Zero means
+	// DefaultImageMaxSize. A negative value means no limit.
+	MaxSize int
+	// Formats lists the accepted MIME types, as detected from the image
+	// data. Defaults to DefaultImageFormats.
+	Formats []string
+	// StrictMIME checks that the MIME type declared by an image data URI
+	// matches the detected format.
+	StrictMIME bool
+	// AllowAnimated accepts animated PNG, WebP and GIF images.
+	AllowAnimated bool
+}
+
+func (l ImageLimits) maxSize() int {
+	if l.MaxSize == 0 {
+		return DefaultImageMaxSize
+	}
+
+	return l.MaxSize
+}
+
+func (l ImageLimits) formats() []string {
+	if len(l.Formats) == 0 {
+		return DefaultImageFormats
+	}
+
+	return l.Formats
+}
+
+// checkEncodedSize returns ErrImageTooLarge if the base64 encoded image
+// would exceed the maximum size once decoded. It does not decode the image.
+func (l ImageLimits) checkEncodedSize(image string) error {
+	if l.maxSize() < 0 {
+		return nil
+	}
+
+	// ignore the data URI prefix, if present
+	if i := strings.Index(image, ","); i != -1 && strings.HasPrefix(image, "data:") {
+		image = image[i+1:]
+	}
+
+	size := base64.StdEncoding.DecodedLen(len(image)) - (len(image) - len(strings.TrimRight(image, "=")))
+	return l.checkSize(size)
+}
+
+func (l ImageLimits) checkSize(size int) error {
+	if maxSize := l.maxSize(); maxSize >= 0 && size > maxSize {
+		return fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrImageTooLarge, size, maxSize)
+	}
+
+	return nil
+}
+
+// checkFormat checks that data is an image in one of the accepted formats,
+// that its header can be decoded and, unless AllowAnimated is set, that it
+// is not animated.
+func (l ImageLimits) checkFormat(data []byte) error {
+	contentType, err := l.checkContentType(data)
+	if err != nil {
+		return err
+	}
+
+	// formats without a registered decoder are accepted unchecked
+	if _, _, err := stdimage.DecodeConfig(bytes.NewReader(data)); err != nil && !errors.Is(err, stdimage.ErrFormat) {
+		return fmt.Errorf("%w: %s: %v", ErrImageDecode, contentType, err)
+	}
+
+	return l.checkAnimated(contentType, data)
+}
+
+// checkHeader is checkFormat for the first bytes of an image. The header is
+// not decoded, as it may be truncated, and animated images are only
+// detected if their animation is declared in the provided bytes.
+func (l ImageLimits) checkHeader(header []byte) error {
+	contentType, err := l.checkContentType(header)
+	if err != nil {
+		return err
+	}
+
+	return l.checkAnimated(contentType, header)
+}
+
+// checkContentType returns the content type detected from data, or
+// ErrImageUnsupportedFormat if it is not one of the accepted formats.
+func (l ImageLimits) checkContentType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+
+	for _, f := range l.formats() {
+		if strings.EqualFold(f, contentType) {
+			return contentType, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrImageUnsupportedFormat, contentType)
+}
+
+func (l ImageLimits) checkAnimated(contentType string, data []byte) error {
+	if !l.AllowAnimated && isAnimated(contentType, data) {
+		return fmt.Errorf("%w: %s", ErrImageAnimated, contentType)
+	}
+
+	return nil
+}
+
+// isAnimated returns true if data is an animated PNG, WebP or GIF image.
+func isAnimated(contentType string, data []byte) bool {
+	switch contentType {
+	case "image/png":
+		return isAnimatedPNG(data)
+	case "image/webp":
+		return isAnimatedWebP(data)
+	case "image/gif":
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		return err == nil && len(g.Image) > 1
+	}
+
+	return false
+}
+
+// isAnimatedPNG returns true if the PNG image contains an animation control
+// chunk before its image data.
+func isAnimatedPNG(data []byte) bool {
+	const signatureLen = 8
+
+	for offset := signatureLen; offset+8 <= len(data); {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		switch string(data[offset+4 : offset+8]) {
+		case "acTL":
+			return true
+		case "IDAT", "IEND":
+			return false
+		}
+
+		// chunk length, type, data and crc
+		offset += 12 + length
+	}
+
+	return false
+}
+
+// isAnimatedWebP returns true if the extended WebP header of the image has
+// the animation flag set.
+func isAnimatedWebP(data []byte) bool {
+	const (
+		headerLen     = 12
+		animationFlag = 0x02
+	)
+
+	if len(data) < headerLen+9 || string(data[headerLen:headerLen+4]) != "VP8X" {
+		return false
+	}
+
+	return data[headerLen+8]&animationFlag != 0
+}
+
+// checkMIME returns ErrImageMIMEMismatch if StrictMIME is set and the MIME
+// type declared by the data URI of image does not match the format detected
+// from data. Images without a declared MIME type are not checked.
+func (l ImageLimits) checkMIME(image string, data []byte) error {
+	if !l.StrictMIME {
+		return nil
+	}
+
+	declared := declaredMIME(image)
+	if declared == "" {
+		return nil
+	}
+
+	detected := http.DetectContentType(data)
+	if !strings.EqualFold(declared, detected) {
+		return fmt.Errorf("%w: declared %s, detected %s", ErrImageMIMEMismatch, declared, detected)
+	}
+
+	return nil
+}
+
+// declaredMIME returns the MIME type of a data URI of the form
+// "data:image/png;base64,...", or an empty string if image is not a data URI.
+func declaredMIME(image string) string {
+	if !strings.HasPrefix(image, "data:") {
+		return ""
+	}
+
+	end := strings.IndexAny(image, ";,")
+	if end == -1 {
+		return ""
+	}
+
+	return image[len("data:"):end]
+}
+
+// decodeImage decodes the base64 encoded image, and checks it against the
+// limits. The returned errors wrap ErrImageTooLarge, ErrImageDecode,
+// ErrImageUnsupportedFormat or ErrImageAnimated.
+func (l ImageLimits) decodeImage(image string) ([]byte, error) {
+	if err := l.checkEncodedSize(image); err != nil {
+		return nil, err
+	}
+
+	data, err := utils.ProcessBase64Image(image)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageDecode, err)
+	}
+
+	if err := l.checkSize(len(data)); err != nil {
+		return nil, err
+	}
+
+	if err := l.checkFormat(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// decodeImageHeader decodes the first bytes of the base64 encoded image,
+// and checks them and the encoded size against the limits. The rest of the
+// image is not decoded.
+func (l ImageLimits) decodeImageHeader(image string) ([]byte, error) {
+	if err := l.checkEncodedSize(image); err != nil {
+		return nil, err
+	}
+
+	// ignore the data URI prefix, if present
+	encoded := image
+	if i := strings.Index(image, ","); i != -1 && strings.HasPrefix(image, "data:") {
+		encoded = image[i+1:]
+	}
+
+	if n := base64.StdEncoding.EncodedLen(imageHeaderSize); len(encoded) > n {
+		encoded = encoded[:n]
+	}
+
+	header, err := utils.GetDataFromBase64String(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageDecode, err)
+	}
+
+	if err := l.checkHeader(header); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// ImageFetcher reads performer images referenced by a path or URL rather
+// than embedded in the input.
+type ImageFetcher struct {
+	// FS is used to read image paths, which are relative to its root. If
+	// nil, image paths are rejected.
+	FS fs.FS
+	// Client is used to fetch image URLs. If nil, http.DefaultClient is
+	// used.
+	Client *http.Client
+	// Timeout is the time allowed to fetch an image URL. Defaults to
+	// DefaultImageFetchTimeout.
+	Timeout time.Duration
+}
+
+// readPath reads the image at path, and checks it against the limits.
+func (f *ImageFetcher) readPath(path string, l ImageLimits) ([]byte, error) {
+	if f.FS == nil {
+		return nil, fmt.Errorf("%w: reading image paths is not supported", ErrImageFetch)
+	}
+
+	file, err := f.FS.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageFetch, err)
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err == nil {
+		if err := l.checkSize(int(info.Size())); err != nil {
+			return nil, err
+		}
+	}
+
+	return l.readImage(file)
+}
+
+// fetchURL fetches the image at u, and checks it against the limits.
+// Responses that do not declare an image content type are rejected.
+func (f *ImageFetcher) fetchURL(ctx context.Context, u string, l ImageLimits) ([]byte, error) {
+	timeout := f.Timeout
+	if timeout <= 0 {
+		timeout = DefaultImageFetchTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil || (req.URL.Scheme != "http" && req.URL.Scheme != "https") {
+		return nil, fmt.Errorf("%w: invalid image URL %q", ErrImageFetch, u)
+	}
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("%w: http error %d", ErrImageFetch, resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%w: %s", ErrImageUnsupportedFormat, contentType)
+	}
+
+	if resp.ContentLength > 0 {
+		if err := l.checkSize(int(resp.ContentLength)); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := l.readImage(resp.Body)
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageFetch, ctx.Err())
+	}
+
+	return data, err
+}
+
+// readImage reads an image from r, reading no more than one byte past the
+// maximum size. Data that is not detected as an image is rejected.
+func (l ImageLimits) readImage(r io.Reader) ([]byte, error) {
+	if maxSize := l.maxSize(); maxSize >= 0 {
+		r = io.LimitReader(r, int64(maxSize)+1)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageFetch, err)
+	}
+
+	if err := l.checkSize(len(data)); err != nil {
+		return nil, err
+	}
+
+	if contentType := http.DetectContentType(data); !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%w: %s", ErrImageUnsupportedFormat, contentType)
+	}
+
+	if err := l.checkFormat(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
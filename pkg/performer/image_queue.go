@@ -0,0 +1,108 @@
+package performer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+type ImageUpdater interface {
+	UpdateImage(ctx context.Context, performerID int, image []byte) error
+}
+
+type queuedImage struct {
+	performerID int
+	image       string
+	limits      ImageLimits
+}
+
+// ImageQueue defers decoding and storing performer images until the
+// performer metadata has been imported, so that metadata can be imported
+// without waiting on large images. Images imported within a transaction are
+// only queued once it has been committed. The queued images are written by
+// Wait, each in its own transaction.
+type ImageQueue struct {
+	ctx        context.Context
+	txnManager txn.Manager
+	writer     ImageUpdater
+
+	mutex sync.Mutex
+	queue []queuedImage
+}
+
+// NewImageQueue creates a new ImageQueue. Wait must be called once all
+// images have been added.
+func NewImageQueue(ctx context.Context, txnManager txn.Manager, writer ImageUpdater) *ImageQueue {
+	return &ImageQueue{
+		ctx:        ctx,
+		txnManager: txnManager,
+		writer:     writer,
+	}
+}
+
+// Add queues the base64 encoded image to be set on the performer with the
+// provided ID. The image is checked against the limits once it has been
+// decoded, before it is written.
+func (q *ImageQueue) Add(performerID int, image string, limits ImageLimits) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.queue = append(q.queue, queuedImage{
+		performerID: performerID,
+		image:       image,
+		limits:      limits,
+	})
+}
+
+// Wait writes the queued images in the order they were added, and blocks
+// until all of them have been written. It must not be called within a
+// transaction. It returns an error if any of the images failed.
+func (q *ImageQueue) Wait() error {
+	q.mutex.Lock()
+	queue := q.queue
+	q.queue = nil
+	q.mutex.Unlock()
+
+	var errs []string
+	for _, img := range queue {
+		if err := q.write(q.ctx, img); err != nil {
+			errs = append(errs, fmt.Sprintf("performer %d: %v", img.performerID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("error setting performer images: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+func (q *ImageQueue) write(ctx context.Context, img queuedImage) error {
+	imageData, err := img.limits.decodeImage(img.image)
+	if err != nil {
+		return err
+	}
+
+	return txn.WithTxn(ctx, q.txnManager, func(ctx context.Context) error {
+		return q.writer.UpdateImage(ctx, img.performerID, imageData)
+	})
+}
+
+// queueImage adds the input image to the ImageQueue. Within a transaction,
+// the image is only queued once the transaction has been committed, so that
+// the queue does not write the image of a performer that was rolled back.
+func (i *Importer) queueImage(ctx context.Context, id int) {
+	q, image, limits := i.ImageQueue, i.image, i.ImageLimits
+	if !txn.InTxn(ctx) {
+		q.Add(id, image, limits)
+		return
+	}
+
+	txn.AddPostCommitHook(ctx, func(ctx context.Context) error {
+		q.Add(id, image, limits)
+		return nil
+	})
+}
@@ -0,0 +1,128 @@
+package performer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stashapp/stash/pkg/txn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImageQueue(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	readerWriter.On("UpdateImage", mock.Anything, performerID, imageBytes).Return(nil).Once()
+	readerWriter.On("UpdateImage", mock.Anything, errImageID, imageBytes).Return(errors.New("UpdateImage error")).Once()
+
+	q := NewImageQueue(testCtx, &mocks.TxnManager{}, readerWriter)
+	q.Add(performerID, image, ImageLimits{})
+	q.Add(errImageID, image, ImageLimits{})
+	q.Add(noImageID, invalidImage, ImageLimits{})
+
+	err := q.Wait()
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImageQueueLimits(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	// images are checked in full before they are written
+	q := NewImageQueue(testCtx, &mocks.TxnManager{}, readerWriter)
+	q.Add(performerID, image, ImageLimits{MaxSize: 5})
+	q.Add(errImageID, "iVBORw0KGgowMDAw", ImageLimits{})
+
+	err := q.Wait()
+	assert.ErrorContains(t, err, ErrImageTooLarge.Error())
+	assert.ErrorContains(t, err, ErrImageDecode.Error())
+
+	readerWriter.AssertNotCalled(t, "UpdateImage", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImageQueueNoErrors(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	readerWriter.On("UpdateImage", mock.Anything, performerID, imageBytes).Return(nil).Once()
+
+	q := NewImageQueue(testCtx, &mocks.TxnManager{}, readerWriter)
+	q.Add(performerID, image, ImageLimits{})
+
+	err := q.Wait()
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterDeferredImage(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+	q := NewImageQueue(testCtx, &mocks.TxnManager{}, readerWriter)
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		ImageQueue:   q,
+		Input: jsonschema.Performer{
+			Name:  performerName,
+			Image: image,
+		},
+	}
+
+	readerWriter.On("UpdateImage", mock.Anything, performerID, imageBytes).Return(nil).Once()
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Nil(t, i.imageData)
+
+	err = i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+
+	err = q.Wait()
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterDeferredImageTxn(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+	q := NewImageQueue(testCtx, &mocks.TxnManager{}, readerWriter)
+
+	postImport := func(id int, fail bool) error {
+		i := Importer{
+			ReaderWriter: readerWriter,
+			ImageQueue:   q,
+			Input: jsonschema.Performer{
+				Name:  performerName,
+				Image: image,
+			},
+		}
+
+		return txn.WithTxn(testCtx, &mocks.TxnManager{}, func(ctx context.Context) error {
+			if err := i.PreImport(ctx); err != nil {
+				return err
+			}
+			if err := i.PostImport(ctx, id); err != nil {
+				return err
+			}
+
+			if fail {
+				return errors.New("rollback")
+			}
+			return nil
+		})
+	}
+
+	// only the image of the committed performer is written
+	assert.Error(t, postImport(errImageID, true))
+	assert.NoError(t, postImport(performerID, false))
+
+	readerWriter.On("UpdateImage", mock.Anything, performerID, imageBytes).Return(nil).Once()
+
+	err := q.Wait()
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
@@ -0,0 +1,55 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pngChunk(chunkType string, data []byte) []byte {
+	length := len(data)
+	ret := []byte{byte(length >> 24), byte(length >> 16), byte(length >> 8), byte(length)}
+	ret = append(ret, chunkType...)
+	ret = append(ret, data...)
+	// crc is not checked
+	return append(ret, 0, 0, 0, 0)
+}
+
+func webpHeader(chunkType string, flags byte) []byte {
+	ret := []byte("RIFF\x00\x00\x00\x00WEBP")
+	ret = append(ret, chunkType...)
+	return append(ret, 10, 0, 0, 0, flags, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+}
+
+func TestIsAnimated(t *testing.T) {
+	const pngSignature = "\x89PNG\r\n\x1a\n"
+
+	ihdr := pngChunk("IHDR", make([]byte, 13))
+	actl := pngChunk("acTL", make([]byte, 8))
+	idat := pngChunk("IDAT", nil)
+
+	apng := append(append(append([]byte(pngSignature), ihdr...), actl...), idat...)
+	stillPNG := append(append([]byte(pngSignature), ihdr...), idat...)
+	lateACTL := append(append(append([]byte(pngSignature), ihdr...), idat...), actl...)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		want        bool
+	}{
+		{"png", "image/png", stillPNG, false},
+		{"apng", "image/png", apng, true},
+		{"png actl after idat", "image/png", lateACTL, false},
+		{"png truncated", "image/png", apng[:20], false},
+		{"webp lossy", "image/webp", webpHeader("VP8 ", 0), false},
+		{"webp extended", "image/webp", webpHeader("VP8X", 0x10), false},
+		{"webp animated", "image/webp", webpHeader("VP8X", 0x12), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isAnimated(tt.contentType, tt.data))
+		})
+	}
+}
@@ -3,11 +3,15 @@ package performer
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/stashapp/stash/pkg/hash/md5"
 	"github.com/stashapp/stash/pkg/models"
 	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/sliceutil/intslice"
 	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
 	"github.com/stashapp/stash/pkg/tag"
 	"github.com/stashapp/stash/pkg/utils"
@@ -15,225 +19,1890 @@ import (
 
 type NameFinderCreatorUpdater interface {
 	NameFinderCreator
+	Find(ctx context.Context, id int) (*models.Performer, error)
+	FindByStashID(ctx context.Context, stashID models.StashID) ([]*models.Performer, error)
+	FindByAlias(ctx context.Context, alias string, nocase bool) ([]*models.Performer, error)
+	GetTagIDs(ctx context.Context, performerID int) ([]int, error)
+	GetImage(ctx context.Context, performerID int) ([]byte, error)
+	GetStashIDs(ctx context.Context, performerID int) ([]models.StashID, error)
 	Update(ctx context.Context, updatedPerformer *models.Performer) error
+	UpdatePartial(ctx context.Context, id int, updatedPerformer models.PerformerPartial) (*models.Performer, error)
 	UpdateTags(ctx context.Context, performerID int, tagIDs []int) error
 	UpdateImage(ctx context.Context, performerID int, image []byte) error
 	UpdateStashIDs(ctx context.Context, performerID int, stashIDs []models.StashID) error
+	GetAttachments(ctx context.Context, performerID int) ([]models.PerformerAttachment, error)
+	UpdateAttachments(ctx context.Context, performerID int, attachments []models.PerformerAttachment) error
+	GetCustomFields(ctx context.Context, performerID int) (map[string]string, error)
+	UpdateCustomFields(ctx context.Context, performerID int, fields map[string]string) error
+}
+
+// PostImportHook is notified once a performer has been imported.
+type PostImportHook interface {
+	// AfterImport is called at the end of PostImport with the ID of the
+	// imported performer. created is true if the performer was created,
+	// and false if an existing performer was updated.
+	AfterImport(ctx context.Context, performerID int, created bool) error
+}
+
+// ChangeReportingUpdater is implemented by writers that can report whether
+// an update changed the stored performer.
+type ChangeReportingUpdater interface {
+	UpdateIfChanged(ctx context.Context, updatedPerformer *models.Performer) (bool, error)
+}
+
+type TagFinderCreatorUpdater interface {
+	tag.NameFinderCreator
+	tag.RelationshipGetter
+	tag.Queryer
+	UpdateParentTags(ctx context.Context, tagID int, parentIDs []int) error
+	UpdateAliases(ctx context.Context, tagID int, aliases []string) error
+}
+
+// ImportMode determines how the timestamps of imported performers are set.
+type ImportMode string
+
+const (
+	// ImportModeRestore sets the created and updated times from the input,
+	// so that a backup is restored exactly. Times missing from the input
+	// are set to the current time. This is the default.
+	ImportModeRestore ImportMode = "RESTORE"
+	// ImportModeSync treats the import as a change to the performer. New
+	// performers take the created time from the input, and existing
+	// performers keep their created time. The updated time is always set
+	// to the current time.
+	ImportModeSync ImportMode = "SYNC"
+)
+
+// StashIDPolicy determines how the input stash IDs are applied to an
+// existing performer.
+type StashIDPolicy string
+
+const (
+	// StashIDPolicyReplace replaces the existing stash IDs with the input
+	// stash IDs.
+	StashIDPolicyReplace StashIDPolicy = "REPLACE"
+	// StashIDPolicyMerge merges the input stash IDs into the existing stash
+	// IDs by endpoint. An input stash ID replaces the existing stash ID for
+	// the same endpoint, and existing stash IDs for other endpoints are
+	// kept.
+	StashIDPolicyMerge StashIDPolicy = "MERGE"
+)
+
+// MergeStrategy determines how the input is applied to an existing
+// performer.
+type MergeStrategy string
+
+const (
+	// MergeStrategyReplace replaces the existing performer with the input.
+	// This is the default.
+	MergeStrategyReplace MergeStrategy = "REPLACE"
+	// MergeStrategyMerge only sets the fields that are not empty in the
+	// input, leaving the other fields of the existing performer unchanged.
+	MergeStrategyMerge MergeStrategy = "MERGE"
+	// MergeStrategyPartial only sets the fields that are present in the
+	// input, so that explicitly empty fields are cleared and omitted fields
+	// are left unchanged. Fields of inputs that were not decoded from JSON
+	// are present if they are not empty.
+	MergeStrategyPartial MergeStrategy = "PARTIAL"
+)
+
+// StashIDNameBehaviour determines what happens when the canonical name of a
+// performer's stash ID differs from the input name.
+type StashIDNameBehaviour string
+
+const (
+	// StashIDNameWarn keeps the input name and reports a warning. This is
+	// the default.
+	StashIDNameWarn StashIDNameBehaviour = "WARN"
+	// StashIDNameAdopt sets the performer name to the canonical name, and
+	// adds the input name to the performer aliases.
+	StashIDNameAdopt StashIDNameBehaviour = "ADOPT"
+)
+
+// ImportErrors are the errors recorded while importing a performer with
+// ContinueOnError set.
+type ImportErrors []error
+
+func (e ImportErrors) Error() string {
+	var s []string
+	for _, err := range e {
+		s = append(s, err.Error())
+	}
+	return strings.Join(s, "; ")
 }
 
 type Importer struct {
 	ReaderWriter        NameFinderCreatorUpdater
-	TagWriter           tag.NameFinderCreator
+	TagWriter           TagFinderCreatorUpdater
 	Input               jsonschema.Performer
 	MissingRefBehaviour models.ImportMissingRefEnum
 
-	ID        int
-	performer models.Performer
-	imageData []byte
+	// ImageQueue, if set, defers decoding and storing the performer image
+	// until after the import. The image is added to the queue by PostImport,
+	// or once the transaction it was called in has been committed.
+	ImageQueue *ImageQueue
+
+	// Batch, if set, is used to detect multiple records in the same import
+	// run that resolve to the same performer.
+	Batch *Batch
+
+	// URLCanonicalizer, if set, is used to normalise the performer URLs.
+	URLCanonicalizer *utils.URLCanonicalizer
+
+	// SortMissingTags creates missing tags in name order, so that tag IDs
+	// are assigned deterministically regardless of the input order.
+	SortMissingTags bool
+
+	// Enrich, if set, is called after the input has been mapped to the
+	// performer, and may fill in missing fields, for example from a scraper.
+	// Errors are reported as warnings unless EnrichFatal is true.
+	Enrich      func(ctx context.Context, performer *models.Performer) error
+	EnrichFatal bool
+
+	// PostImportHook, if set, is called at the end of PostImport. It is not
+	// called for skipped performers or in a DryRun. Errors are reported as
+	// warnings unless PostImportHookFatal is true.
+	PostImportHook      PostImportHook
+	PostImportHookFatal bool
+
+	// TagCollection, if set, is the name of a parent tag that all imported
+	// tags are added to. The collection tag is resolved according to
+	// MissingRefBehaviour.
+	TagCollection string
+
+	// StashIDNameFetcher, if set, returns the canonical name of the
+	// performer identified by a stash ID. It is called for the input stash
+	// IDs in order until a name is returned. StashIDNameBehaviour determines
+	// what happens if the name differs from the input name.
+	StashIDNameFetcher   func(ctx context.Context, stashID models.StashID) (string, error)
+	StashIDNameBehaviour StashIDNameBehaviour
+
+	// TagPrefix, if set, is prepended to the names and aliases of the input
+	// tags, both when finding and creating them. It is not applied to
+	// TagCollection.
+	TagPrefix string
+
+	// DefaultTagParent, if set, is the name of the parent of the tags
+	// created during import that have no parents in the input TagParents.
+	// TagParentMissingRefBehaviour determines what happens if a parent does
+	// not exist. If empty, it defaults to ImportMissingRefEnumFail.
+	DefaultTagParent             string
+	TagParentMissingRefBehaviour models.ImportMissingRefEnum
+
+	// CaseInsensitiveMatch matches existing performers by name ignoring
+	// case. If multiple performers match, an exact case match is preferred,
+	// then the lowest ID.
+	CaseInsensitiveMatch bool
+
+	// SkipUnchanged compares the input with the existing performer before
+	// updating it. If the fields, tags, stash IDs, image, attachments and
+	// custom fields that would be written are identical, nothing is written
+	// and the import is reported as skipped. The updated time is not
+	// compared. Images queued in ImageQueue cannot be compared, so are
+	// always considered changed.
+	SkipUnchanged bool
+
+	// MatchAliases matches existing performers whose aliases contain the
+	// input name, if no performer matches by stash ID or name. If the
+	// alias is shared by multiple performers, the import fails under the
+	// Fail MissingRefBehaviour. Otherwise, a warning is reported and no
+	// performer is matched.
+	MatchAliases bool
+
+	// RatingScale is the scale of the input ratings, which are converted to
+	// the internal RatingScale5. Defaults to RatingScale5.
+	RatingScale RatingScale
+
+	// CanonicalizeCountry converts the input country to its ISO 3166-1
+	// alpha-2 code. See utils.ParseCountry.
+	//
+	// InvalidCountryBehaviour determines what happens when the country is
+	// not a known country. It is ignored unless CanonicalizeCountry is set.
+	CanonicalizeCountry     bool
+	InvalidCountryBehaviour InvalidCountryBehaviour
+
+	// DefaultGender, if set, is used when the input gender is empty or
+	// invalid and InvalidGenderBehaviour is InvalidGenderIgnore. It does not
+	// replace the gender of an existing performer.
+	DefaultGender models.GenderEnum
+
+	// InvalidGenderBehaviour determines what happens when the input gender
+	// is not a known gender or synonym. See ParseGender.
+	InvalidGenderBehaviour InvalidGenderBehaviour
+
+	// DateLayouts are the accepted layouts of the birth and death dates.
+	// Defaults to DefaultDateLayouts. See ParseDate.
+	//
+	// StrictDates fails the import if a date cannot be parsed. Otherwise,
+	// the date is left unset and a warning is reported.
+	DateLayouts []string
+	StrictDates bool
+
+	// Mode determines the timestamp policy. Defaults to ImportModeRestore.
+	Mode ImportMode
+
+	// MergeStrategy determines how the input is applied to an existing
+	// performer. Defaults to MergeStrategyReplace. It is ignored if the
+	// input specifies its fields.
+	//
+	// MergeRelationships determines how the tags, stash IDs and image are
+	// applied under MergeStrategyMerge. RelationshipUpdateModeAdd, the
+	// default, adds the tags to the existing ones, merges the stash IDs
+	// using StashIDPolicyMerge, and only sets the image if the performer
+	// has none. RelationshipUpdateModeSet replaces them. Empty input values
+	// are left unchanged in both cases.
+	MergeStrategy      MergeStrategy
+	MergeRelationships models.RelationshipUpdateMode
+
+	// StashIDPolicy determines how the input stash IDs are applied to an
+	// existing performer. If empty, it is determined by MergeStrategy and
+	// MergeRelationships, defaulting to StashIDPolicyReplace. The input
+	// stash IDs are always deduplicated by endpoint, with later stash IDs
+	// replacing earlier ones.
+	StashIDPolicy StashIDPolicy
+
+	// ImageLimits constrains the accepted performer images.
+	ImageLimits ImageLimits
+
+	// ImageFetcher, if set, reads the image referenced by the input image
+	// path or URL, if the input does not contain an image. If nil, image
+	// paths and URLs are rejected. Fetched images are not deferred to the
+	// ImageQueue.
+	ImageFetcher *ImageFetcher
+
+	// AttachmentLimits constrains the accepted performer attachments.
+	// AttachmentErrorBehaviour determines what happens to attachments that
+	// cannot be decoded or exceed the limits.
+	AttachmentLimits         AttachmentLimits
+	AttachmentErrorBehaviour AttachmentErrorBehaviour
+
+	// NormalizeBodyModifications normalizes the tattoos and piercings
+	// fields. See NormalizeBodyModification.
+	NormalizeBodyModifications bool
+
+	// RawAliases imports the aliases as they are in the input. Otherwise,
+	// the aliases are normalized. See NormalizeAliases.
+	RawAliases bool
+
+	// DryRun skips all writes. Missing tags are not created, and Create,
+	// Update and PostImport record the changes that would be made in the
+	// Preview returned by Plan instead of writing them. PreImport still
+	// validates the input. See also Preview.
+	DryRun bool
+
+	// ContinueOnError records errors importing the tags, image and
+	// attachments, and continues the import without the failed values.
+	// Tags are created individually, so that a tag that cannot be created
+	// does not prevent the others from being created. The recorded errors
+	// are returned by PostImport as ImportErrors once the rest of the
+	// performer has been imported.
+	ContinueOnError bool
+
+	ID        int
+	performer models.Performer
+	// canonicalName is the adopted stash ID name, if any
+	canonicalName string
+	// image is the base64 encoded primary image
+	image     string
+	imageData []byte
+
+	attachments []models.PerformerAttachment
+
+	tags       []*models.Tag
+	removeTags []*models.Tag
+
+	// missingTags are the tags that would be created by a DryRun import
+	missingTags []string
+	// plan is the preview recorded by a DryRun import
+	plan Preview
+	// changes records the changes made by the import
+	changes ImportChanges
+
+	// fieldMask is set if the input specifies its authoritative fields
+	fieldMask fieldMask
+	// defaultedGender is true if the performer gender was set to
+	// DefaultGender
+	defaultedGender bool
+	// clearRating is true if the input explicitly has no rating
+	clearRating bool
+
+	changed  []string
+	warnings []string
+	// errs are the errors recorded under ContinueOnError
+	errs    []error
+	skipped bool
+	// unchanged is true if the writer reported that the update did not
+	// change the existing performer
+	unchanged bool
+	// updated is true if an existing performer was updated
+	updated bool
+	// claimedID is the ID of the performer claimed in the Batch by this
+	// importer, so that it can be released if the import is rolled back
+	claimedID int
+	// createdTagNames are the names and aliases of the tags created by this
+	// importer, so that they can be forgotten by the Batch if the import is
+	// rolled back
+	createdTagNames []string
+}
+
+func (i *Importer) PreImport(ctx context.Context) error {
+	i.missingTags = nil
+	i.canonicalName = ""
+	i.errs = nil
+	i.plan = Preview{}
+	i.changes = ImportChanges{}
+
+	if len(i.Input.Fields) > 0 {
+		mask, err := newFieldMask(i.Input.Fields)
+		if err != nil {
+			return err
+		}
+
+		i.fieldMask = mask
+		i.Input = mask.apply(i.Input)
+	} else if i.MergeStrategy == MergeStrategyPartial {
+		i.fieldMask = presentFieldMask(i.Input)
+	}
+
+	if err := i.validateGender(); err != nil {
+		return err
+	}
+
+	if err := i.validateCountry(); err != nil {
+		return err
+	}
+
+	if err := i.validateDates(); err != nil {
+		return err
+	}
+
+	if err := validateCustomFields(i.Input.CustomFields); err != nil {
+		return err
+	}
+
+	i.performer = i.performerJSONToPerformer(i.Input)
+
+	locale, err := CanonicalSortNameLocale(i.performer.SortNameLocale)
+	if err != nil {
+		return err
+	}
+	i.performer.SortNameLocale = locale
+
+	if err := i.validateMeasurements(); err != nil {
+		return err
+	}
+
+	if err := i.enrich(ctx); err != nil {
+		return err
+	}
+
+	if err := i.checkStashIDName(ctx); err != nil {
+		return err
+	}
+
+	if err := i.continueOnError(ctx, i.populateRemoveTags(ctx)); err != nil {
+		return err
+	}
+
+	if err := i.continueOnError(ctx, i.populateTags(ctx)); err != nil {
+		return err
+	}
+
+	i.image = i.primaryImage()
+
+	if err := i.processImage(ctx); err != nil {
+		i.image = ""
+		i.imageData = nil
+		if err := i.continueOnError(ctx, err); err != nil {
+			return err
+		}
+	}
+
+	return i.processAttachments(ctx)
+}
+
+// defaultGender returns the gender used when the input gender is empty or
+// invalid. DefaultGender only applies under InvalidGenderIgnore.
+func (i *Importer) defaultGender() models.GenderEnum {
+	if i.InvalidGenderBehaviour != "" && i.InvalidGenderBehaviour != InvalidGenderIgnore {
+		return ""
+	}
+
+	return i.DefaultGender
+}
+
+// keepExistingGender returns p with the gender of existing if the gender of
+// p was defaulted and existing has a gender.
+func (i *Importer) keepExistingGender(p models.Performer, existing models.Performer) models.Performer {
+	if i.defaultedGender && existing.Gender != "" {
+		p.Gender = existing.Gender
+	}
+
+	return p
+}
+
+// validateGender returns an error if the input gender is invalid and
+// InvalidGenderBehaviour is InvalidGenderFail.
+func (i *Importer) validateGender() error {
+	if i.InvalidGenderBehaviour != InvalidGenderFail || i.Input.Gender == "" {
+		return nil
+	}
+
+	if _, valid := ParseGender(i.Input.Gender); !valid {
+		return fmt.Errorf("invalid gender %q: must be one of %v", i.Input.Gender, models.AllGenderEnum)
+	}
+
+	return nil
+}
+
+// validateDates returns an error if StrictDates is set and one of the input
+// dates cannot be parsed.
+func (i *Importer) validateDates() error {
+	if !i.StrictDates {
+		return nil
+	}
+
+	dates := []struct {
+		name  string
+		value string
+	}{
+		{"birthdate", i.Input.Birthdate},
+		{"death_date", i.Input.DeathDate},
+	}
+
+	for _, d := range dates {
+		if d.value == "" {
+			continue
+		}
+
+		if _, err := ParseDate(d.value, i.dateLayouts()); err != nil {
+			return fmt.Errorf("invalid %s: %v", d.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (i *Importer) dateLayouts() []string {
+	if i.DateLayouts == nil {
+		return DefaultDateLayouts
+	}
+
+	return i.DateLayouts
+}
+
+// parseDate parses the named date field, reporting a warning if it cannot
+// be parsed. It returns nil if value is empty or invalid.
+func (i *Importer) parseDate(name string, value string) *models.Date {
+	if value == "" {
+		return nil
+	}
+
+	d, err := ParseDate(value, i.dateLayouts())
+	if err != nil {
+		i.addWarning("ignoring invalid %s: %v", name, err)
+		return nil
+	}
+
+	return d
+}
+
+// continueOnError returns err, unless ContinueOnError is set, in which case
+// err is recorded and nil is returned. Errors are never recorded once ctx is
+// done, so that a cancelled import stops.
+func (i *Importer) continueOnError(ctx context.Context, err error) error {
+	if err == nil || !i.ContinueOnError || ctx.Err() != nil {
+		return err
+	}
+
+	i.errs = append(i.errs, err)
+	return nil
+}
+
+// importErrors returns the errors recorded under ContinueOnError, or nil if
+// there are none.
+func (i *Importer) importErrors() error {
+	if len(i.errs) == 0 {
+		return nil
+	}
+
+	return append(ImportErrors(nil), i.errs...)
+}
+
+// processImage decodes and validates the image. If the image is deferred to
+// the ImageQueue, only its size is checked. If the input contains no image,
+// the image is read from the input image path or URL.
+func (i *Importer) processImage(ctx context.Context) error {
+	i.imageData = nil
+	if len(i.image) == 0 {
+		return i.fetchImage(ctx)
+	}
+
+	var data []byte
+	var err error
+	if i.ImageQueue != nil {
+		// deferred images are decoded in full when they are written
+		data, err = i.ImageLimits.decodeImageHeader(i.image)
+	} else {
+		data, err = i.ImageLimits.decodeImage(i.image)
+	}
+	if err != nil {
+		return err
+	}
+
+	// a mismatched MIME type fails the import under the Fail behaviour.
+	// Otherwise, the detected format is trusted.
+	if err := i.ImageLimits.checkMIME(i.image, data); err != nil {
+		if i.MissingRefBehaviour == models.ImportMissingRefEnumFail {
+			return err
+		}
+
+		i.addWarning("%v", err)
+	}
+
+	if i.ImageQueue == nil {
+		i.imageData = data
+	}
+
+	return nil
+}
+
+// fetchImage reads the image referenced by the input image path or URL.
+// The path takes precedence over the URL.
+func (i *Importer) fetchImage(ctx context.Context) error {
+	if i.Input.ImagePath == "" && i.Input.ImageURL == "" {
+		return nil
+	}
+
+	if i.ImageFetcher == nil {
+		return fmt.Errorf("%w: image paths and URLs are not enabled", ErrImageFetch)
+	}
+
+	var data []byte
+	var err error
+	if i.Input.ImagePath != "" {
+		data, err = i.ImageFetcher.readPath(i.Input.ImagePath, i.ImageLimits)
+	} else {
+		data, err = i.ImageFetcher.fetchURL(ctx, i.Input.ImageURL, i.ImageLimits)
+	}
+	if err != nil {
+		return err
+	}
+
+	i.imageData = data
+	return nil
+}
+
+// validateMeasurements checks that the input measurements can be parsed in
+// the input unit system. Measurements that cannot be parsed are reported as
+// a warning. Only the measurements string is stored, so the structured
+// measurements are not kept.
+func (i *Importer) validateMeasurements() error {
+	units := MeasurementUnits(i.Input.MeasurementUnits)
+	if units != "" && !units.IsValid() {
+		return fmt.Errorf("invalid measurement units %q", units)
+	}
+
+	if i.Input.Measurements == "" {
+		return nil
+	}
+
+	if _, err := ParseMeasurements(i.Input.Measurements, units); err != nil {
+		i.addWarning("%v", err)
+	}
+
+	return nil
+}
+
+// primaryImage returns the image to set on the performer. If multiple images
+// are provided, the one at PrimaryImage is used, falling back to the first
+// if the index is out of range.
+func (i *Importer) primaryImage() string {
+	images := i.Input.Images
+	if len(images) == 0 {
+		return i.Input.Image
+	}
+
+	index := i.Input.PrimaryImage
+	if index < 0 || index >= len(images) {
+		i.addWarning("primary image index %d out of range for %d images, using first image", index, len(images))
+		index = 0
+	}
+
+	return images[index]
+}
+
+func (i *Importer) populateTags(ctx context.Context) error {
+	if len(i.Input.Tags) > 0 {
+
+		tags, err := i.importTags(ctx, i.prefixTags(i.Input.Tags))
+		if err != nil {
+			return err
+		}
+
+		i.tags = tags
+
+		if err := i.ensureTagCollection(ctx, tags); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureTagCollection adds the TagCollection tag as a parent of each of the
+// provided tags, if it is not already.
+func (i *Importer) ensureTagCollection(ctx context.Context, tags []*models.Tag) error {
+	if i.TagCollection == "" {
+		return nil
+	}
+
+	collection, err := i.importTags(ctx, []string{i.TagCollection})
+	if err != nil {
+		return fmt.Errorf("error importing tag collection: %v", err)
+	}
+
+	// ignored, or missing in a dry run
+	if len(collection) == 0 || i.DryRun {
+		return nil
+	}
+
+	collectionID := collection[0].ID
+
+	for _, t := range tags {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if t.ID == collectionID {
+			continue
+		}
+
+		parents, err := i.TagWriter.FindByChildTagID(ctx, t.ID)
+		if err != nil {
+			return fmt.Errorf("error getting parent tags of %q: %v", t.Name, err)
+		}
+
+		var parentIDs []int
+		for _, p := range parents {
+			parentIDs = append(parentIDs, p.ID)
+		}
+
+		if intslice.IntInclude(parentIDs, collectionID) {
+			continue
+		}
+
+		parentIDs = append(parentIDs, collectionID)
+		if err := tag.ValidateHierarchy(ctx, t, parentIDs, nil, i.TagWriter); err != nil {
+			return fmt.Errorf("error adding tag %q to collection %q: %v", t.Name, i.TagCollection, err)
+		}
+
+		if err := i.TagWriter.UpdateParentTags(ctx, t.ID, parentIDs); err != nil {
+			return fmt.Errorf("error adding tag %q to collection %q: %v", t.Name, i.TagCollection, err)
+		}
+	}
+
+	return nil
+}
+
+func (i *Importer) enrich(ctx context.Context) error {
+	if i.Enrich == nil {
+		return nil
+	}
+
+	if err := i.Enrich(ctx, &i.performer); err != nil {
+		if i.EnrichFatal {
+			return fmt.Errorf("error enriching performer: %v", err)
+		}
+
+		i.addWarning("error enriching performer: %v", err)
+	}
+
+	return nil
+}
+
+// checkStashIDName compares the canonical name of the performer's stash ID
+// with the input name, applying StashIDNameBehaviour if they differ. Errors
+// fetching the name are reported as warnings.
+func (i *Importer) checkStashIDName(ctx context.Context) error {
+	if i.StashIDNameFetcher == nil {
+		return nil
+	}
+
+	for _, stashID := range i.Input.StashIDs {
+		name, err := i.StashIDNameFetcher(ctx, stashID)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+
+			i.addWarning("error fetching name for stash ID %s: %v", stashID.StashID, err)
+			continue
+		}
+
+		if name == "" {
+			continue
+		}
+
+		if name == i.performer.Name {
+			return nil
+		}
+
+		if i.StashIDNameBehaviour != StashIDNameAdopt {
+			i.addWarning("name %q differs from stash ID name %q", i.performer.Name, name)
+			return nil
+		}
+
+		i.performer.Aliases = addAlias(i.performer.Aliases, i.performer.Name)
+		i.performer.Name = name
+		i.performer.Checksum = Checksum(name, i.performer.Disambiguation)
+		i.canonicalName = name
+		if i.fieldMask != nil {
+			i.fieldMask["name"] = true
+			i.fieldMask["aliases"] = true
+		}
+		i.addWarning("using stash ID name %q, adding %q to aliases", name, i.Input.Name)
+		return nil
+	}
+
+	return nil
+}
+
+// addAlias adds alias to the comma separated aliases, if not already
+// present.
+func addAlias(aliases string, alias string) string {
+	if aliases == "" {
+		return alias
+	}
+
+	for _, a := range strings.Split(aliases, ",") {
+		if strings.EqualFold(strings.TrimSpace(a), alias) {
+			return aliases
+		}
+	}
+
+	return aliases + ", " + alias
+}
+
+// populateRemoveTags resolves the tags to be unlinked from the performer.
+// Tags that do not exist cannot be associated, so they are ignored.
+func (i *Importer) populateRemoveTags(ctx context.Context) error {
+	if len(i.Input.RemoveTags) == 0 {
+		return nil
+	}
+
+	for _, name := range i.Input.RemoveTags {
+		if stringslice.StrInclude(i.Input.Tags, name) {
+			return fmt.Errorf("tag %q is present in both tags and remove_tags", name)
+		}
+	}
+
+	tags, err := i.TagWriter.FindByNames(ctx, i.prefixTags(i.Input.RemoveTags), false)
+	if err != nil {
+		return err
+	}
+
+	i.removeTags = tags
+
+	return nil
+}
+
+// importTags returns the tags with the provided names, handling missing
+// tags according to MissingRefBehaviour. Tags found or created earlier in
+// the Batch are not looked up again, and the missing tags are looked up by
+// alias and created in a single call each, so that the number of calls
+// does not depend on the number of tags.
+func (i *Importer) importTags(ctx context.Context, names []string) ([]*models.Tag, error) {
+	tagWriter := i.TagWriter
+	missingRefBehaviour := i.MissingRefBehaviour
+
+	names = uniqueTagNames(names)
+
+	tags, knownMissing, uncached := i.Batch.cachedTags(names)
+
+	// FindByNames may return the tags it found along with an error. In that
+	// case, the found tags are used. Unresolved tags are only ignored if
+	// MissingRefBehaviour is Ignore, since they may exist.
+	var missingTags []string
+	var lookupErr error
+	if len(uncached) > 0 {
+		var found []*models.Tag
+		found, lookupErr = tagWriter.FindByNames(ctx, uncached, false)
+		if lookupErr != nil {
+			if len(found) == 0 {
+				return nil, fmt.Errorf("error finding tags: %v", lookupErr)
+			}
+
+			i.addWarning("error finding some tags: %v", lookupErr)
+		}
+
+		byName := make(map[string]*models.Tag)
+		for _, t := range found {
+			byName[t.Name] = t
+		}
+		i.Batch.addTags(byName)
+
+		tags = append(tags, found...)
+		missingTags = stringslice.StrFilter(uncached, func(name string) bool {
+			return byName[name] == nil
+		})
+	}
+
+	if len(missingTags) > 0 || len(knownMissing) > 0 {
+		allMissing := stringslice.StrFilter(names, func(name string) bool {
+			return stringslice.StrInclude(missingTags, name) || stringslice.StrInclude(knownMissing, name)
+		})
+
+		if lookupErr != nil && missingRefBehaviour != models.ImportMissingRefEnumIgnore {
+			return nil, fmt.Errorf("error finding tags [%s]: %v", strings.Join(allMissing, ", "), lookupErr)
+		}
+
+		if missingRefBehaviour == models.ImportMissingRefEnumFail {
+			return nil, fmt.Errorf("tags [%s] not found", strings.Join(allMissing, ", "))
+		}
+
+		if missingRefBehaviour == models.ImportMissingRefEnumCreate {
+			// names known to be missing have already been looked up by
+			// alias
+			aliasTags, err := findTagsByAlias(ctx, tagWriter, missingTags)
+			if err != nil {
+				return nil, err
+			}
+			i.Batch.addTags(aliasTags)
+
+			for _, name := range missingTags {
+				if t := aliasTags[name]; t != nil {
+					tags = appendUniqueTags(tags, []*models.Tag{t})
+				}
+			}
+
+			missingTags = stringslice.StrFilter(allMissing, func(name string) bool {
+				return aliasTags[name] == nil
+			})
+			i.Batch.addMissingTags(missingTags)
+
+			aliases := i.tagAliases()
+			missingTags = omitTagAliases(missingTags, aliases)
+			if i.SortMissingTags {
+				sort.Strings(missingTags)
+			}
+
+			if i.DryRun {
+				i.missingTags = append(i.missingTags, missingTags...)
+				return tags, nil
+			}
+
+			createdTags, err := i.createMissingTags(ctx, missingTags, aliases)
+			if err != nil {
+				return nil, fmt.Errorf("error creating tags: %w", err)
+			}
+
+			i.recordCreatedTags(createdTags, aliases)
+
+			tags = appendUniqueTags(tags, createdTags)
+			i.changes.TagsCreated += len(createdTags)
+
+			if err := i.setCreatedTagParents(ctx, createdTags); err != nil {
+				return nil, err
+			}
+		}
+
+		// ignore if MissingRefBehaviour set to Ignore
+	}
+
+	return tags, nil
+}
+
+// recordCreatedTags records the created tags in the Batch under their names
+// and aliases, so that subsequent records use them rather than creating
+// them again.
+func (i *Importer) recordCreatedTags(created []*models.Tag, aliases map[string][]string) {
+	byName := make(map[string]*models.Tag)
+	for _, t := range created {
+		byName[t.Name] = t
+		i.createdTagNames = append(i.createdTagNames, t.Name)
+		for _, alias := range aliases[t.Name] {
+			byName[alias] = t
+			i.createdTagNames = append(i.createdTagNames, alias)
+		}
+	}
+
+	i.Batch.addTags(byName)
+}
+
+// uniqueTagNames returns names with surrounding whitespace trimmed and
+// empty names removed. Names that differ only in case are treated as the
+// same tag, consistent with tag name uniqueness, so only the first is kept.
+func uniqueTagNames(names []string) []string {
+	var ret []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for _, existing := range ret {
+			if strings.EqualFold(existing, name) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			ret = append(ret, name)
+		}
+	}
+
+	return ret
+}
+
+// findTagsByAlias returns the tags with an alias matching one of names,
+// keyed by name. If tagWriter implements tag.AliasFinder, the tags are found
+// in a single call.
+func findTagsByAlias(ctx context.Context, tagWriter tag.Queryer, names []string) (map[string]*models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	if af, ok := tagWriter.(tag.AliasFinder); ok {
+		ret, err := af.FindByAliases(ctx, names)
+		if err != nil {
+			return nil, fmt.Errorf("error finding tags by alias: %v", err)
+		}
+
+		return ret, nil
+	}
+
+	ret := make(map[string]*models.Tag)
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		t, err := tag.ByAlias(ctx, tagWriter, name)
+		if err != nil {
+			return nil, fmt.Errorf("error finding tag by alias %q: %v", name, err)
+		}
+
+		if t != nil {
+			ret[name] = t
+		}
+	}
+
+	return ret, nil
+}
+
+// appendUniqueTags appends the tags in add to tags, omitting tags already
+// present.
+func appendUniqueTags(tags []*models.Tag, add []*models.Tag) []*models.Tag {
+	for _, t := range add {
+		found := false
+		for _, existing := range tags {
+			if existing.ID == t.ID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags
+}
+
+// prefixTags returns names with TagPrefix prepended.
+func (i *Importer) prefixTags(names []string) []string {
+	if i.TagPrefix == "" {
+		return names
+	}
+
+	return stringslice.StrMap(names, func(name string) string {
+		return i.TagPrefix + name
+	})
+}
+
+// tagAliases returns the input tag aliases, with TagPrefix prepended to
+// both the tag names and the aliases.
+func (i *Importer) tagAliases() map[string][]string {
+	if i.TagPrefix == "" {
+		return i.Input.TagAliases
+	}
+
+	ret := make(map[string][]string)
+	for name, aliases := range i.Input.TagAliases {
+		ret[i.TagPrefix+name] = i.prefixTags(aliases)
+	}
+
+	return ret
+}
+
+// omitTagAliases returns names without the names that are aliases of
+// another name in names.
+func omitTagAliases(names []string, aliases map[string][]string) []string {
+	aliasOf := make(map[string]bool)
+	for _, name := range names {
+		for _, alias := range aliases[name] {
+			if alias != name {
+				aliasOf[alias] = true
+			}
+		}
+	}
+
+	return stringslice.StrFilter(names, func(name string) bool {
+		return !aliasOf[name]
+	})
+}
+
+// createMissingTags creates the missing tags. If ContinueOnError is set,
+// each tag is created separately, and the tags that fail to be created are
+// recorded and omitted.
+func (i *Importer) createMissingTags(ctx context.Context, names []string, aliases map[string][]string) ([]*models.Tag, error) {
+	if !i.ContinueOnError {
+		return createTags(ctx, i.TagWriter, names, aliases)
+	}
+
+	var ret []*models.Tag
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		created, err := createTags(ctx, i.TagWriter, []string{name}, aliases)
+		if err != nil {
+			i.errs = append(i.errs, fmt.Errorf("error creating tag %q: %v", name, err))
+			continue
+		}
+
+		ret = append(ret, created...)
+	}
+
+	return ret, nil
+}
+
+// createTags creates tags with the provided names. If aliases are provided
+// for a name, they are set on the created tag. If tagWriter implements
+// tag.ManyCreator, the tags are created in a single call.
+func createTags(ctx context.Context, tagWriter TagFinderCreatorUpdater, names []string, aliases map[string][]string) ([]*models.Tag, error) {
+	var ret []*models.Tag
+	if mc, ok := tagWriter.(tag.ManyCreator); ok {
+		var newTags []models.Tag
+		for _, name := range names {
+			newTags = append(newTags, *models.NewTag(name))
+		}
+
+		var err error
+		ret, err = mc.CreateMany(ctx, newTags)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			created, err := tagWriter.Create(ctx, *models.NewTag(name))
+			if err != nil {
+				return nil, err
+			}
+
+			ret = append(ret, created)
+		}
+	}
+
+	for idx, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if len(aliases[name]) > 0 {
+			if err := tagWriter.UpdateAliases(ctx, ret[idx].ID, aliases[name]); err != nil {
+				return nil, fmt.Errorf("error setting aliases of tag %q: %v", name, err)
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+func (i *Importer) PostImport(ctx context.Context, id int) error {
+	if i.skipped {
+		return nil
+	}
+
+	if i.DryRun {
+		if len(i.image) > 0 || len(i.imageData) > 0 {
+			i.plan.Image = PreviewImageActionSet
+		}
+		return nil
+	}
+
+	if err := i.continueOnError(ctx, i.postImportTags(ctx, id)); err != nil {
+		return err
+	}
+
+	if err := i.continueOnError(ctx, i.postImportImage(ctx, id)); err != nil {
+		return err
+	}
+
+	if err := i.continueOnError(ctx, i.postImportStashIDs(ctx, id)); err != nil {
+		return err
+	}
+
+	if i.setsAttachments() {
+		if err := i.ReaderWriter.UpdateAttachments(ctx, id, i.attachments); err != nil {
+			err = fmt.Errorf("error setting performer attachments: %v", err)
+			if err := i.continueOnError(ctx, err); err != nil {
+				return err
+			}
+		} else {
+			i.changed = append(i.changed, "attachments")
+		}
+	}
+
+	if err := i.continueOnError(ctx, i.postImportCustomFields(ctx, id)); err != nil {
+		return err
+	}
+
+	if err := i.continueOnError(ctx, i.runPostImportHook(ctx, id)); err != nil {
+		return err
+	}
+
+	return i.importErrors()
+}
+
+func (i *Importer) runPostImportHook(ctx context.Context, id int) error {
+	if i.PostImportHook == nil {
+		return nil
+	}
 
-	tags []*models.Tag
+	if err := i.PostImportHook.AfterImport(ctx, id, !i.updated); err != nil {
+		if i.PostImportHookFatal {
+			return fmt.Errorf("error running post import hook: %w", err)
+		}
+
+		i.addWarning("error running post import hook: %v", err)
+	}
+
+	return nil
 }
 
-func (i *Importer) PreImport(ctx context.Context) error {
-	i.performer = performerJSONToPerformer(i.Input)
+// merging returns true if the input is being merged into an existing
+// performer according to MergeStrategyMerge.
+func (i *Importer) merging() bool {
+	return i.MergeStrategy == MergeStrategyMerge && i.fieldMask == nil
+}
 
-	if err := i.populateTags(ctx); err != nil {
-		return err
+// mergeRelationships returns how the relationships are applied to an
+// updated performer when merging.
+func (i *Importer) mergeRelationships() models.RelationshipUpdateMode {
+	if i.MergeRelationships == "" {
+		return models.RelationshipUpdateModeAdd
 	}
 
-	var err error
-	if len(i.Input.Image) > 0 {
-		i.imageData, err = utils.ProcessBase64Image(i.Input.Image)
+	return i.MergeRelationships
+}
+
+// postImportImage sets the performer image, unless an existing image is
+// being kept when merging.
+func (i *Importer) postImportImage(ctx context.Context, id int) error {
+	if len(i.image) == 0 && len(i.imageData) == 0 {
+		return nil
+	}
+
+	if i.updated && i.merging() && i.mergeRelationships() == models.RelationshipUpdateModeAdd {
+		existing, err := i.ReaderWriter.GetImage(ctx, id)
 		if err != nil {
-			return fmt.Errorf("invalid image: %v", err)
+			return fmt.Errorf("error getting performer image: %v", err)
+		}
+
+		if len(existing) > 0 {
+			return nil
+		}
+	}
+
+	if i.ImageQueue != nil && len(i.image) > 0 {
+		i.queueImage(ctx, id)
+	}
+
+	if len(i.imageData) > 0 {
+		if err := i.ReaderWriter.UpdateImage(ctx, id, i.imageData); err != nil {
+			return fmt.Errorf("error setting performer image: %v", err)
 		}
+
+		i.changed = append(i.changed, "image")
+		i.changes.ImageSet = true
 	}
 
 	return nil
 }
 
-func (i *Importer) populateTags(ctx context.Context) error {
-	if len(i.Input.Tags) > 0 {
+// stashIDPolicy returns how the input stash IDs are applied to an existing
+// performer.
+func (i *Importer) stashIDPolicy() StashIDPolicy {
+	switch {
+	case i.StashIDPolicy != "":
+		return i.StashIDPolicy
+	case i.merging() && i.mergeRelationships() == models.RelationshipUpdateModeAdd:
+		return StashIDPolicyMerge
+	}
+
+	return StashIDPolicyReplace
+}
+
+// postImportStashIDs sets the performer stash IDs according to
+// stashIDPolicy.
+func (i *Importer) postImportStashIDs(ctx context.Context, id int) error {
+	if !i.setsStashIDs() {
+		return nil
+	}
 
-		tags, err := importTags(ctx, i.TagWriter, i.Input.Tags, i.MissingRefBehaviour)
+	stashIDs := mergeStashIDs(nil, i.Input.StashIDs)
+	changed := true
+	if i.updated && i.stashIDPolicy() == StashIDPolicyMerge {
+		existing, err := i.ReaderWriter.GetStashIDs(ctx, id)
 		if err != nil {
-			return err
+			return fmt.Errorf("error getting stash ids: %v", err)
 		}
 
-		i.tags = tags
+		stashIDs = mergeStashIDs(existing, stashIDs)
+		changed = !stashIDsEqual(existing, stashIDs)
+	}
+
+	if err := i.ReaderWriter.UpdateStashIDs(ctx, id, stashIDs); err != nil {
+		return fmt.Errorf("error setting stash id: %v", err)
 	}
 
+	i.changed = append(i.changed, "stash_ids")
+	i.changes.StashIDsChanged = changed
+
 	return nil
 }
 
-func importTags(ctx context.Context, tagWriter tag.NameFinderCreator, names []string, missingRefBehaviour models.ImportMissingRefEnum) ([]*models.Tag, error) {
-	tags, err := tagWriter.FindByNames(ctx, names, false)
-	if err != nil {
-		return nil, err
-	}
+// setsStashIDs returns true if the import sets the performer stash IDs.
+func (i *Importer) setsStashIDs() bool {
+	return len(i.Input.StashIDs) > 0 || i.fieldMask["stash_ids"]
+}
 
-	var pluckedNames []string
-	for _, tag := range tags {
-		pluckedNames = append(pluckedNames, tag.Name)
+func stashIDsEqual(a []models.StashID, b []models.StashID) bool {
+	if len(a) != len(b) {
+		return false
 	}
 
-	missingTags := stringslice.StrFilter(names, func(name string) bool {
-		return !stringslice.StrInclude(pluckedNames, name)
-	})
-
-	if len(missingTags) > 0 {
-		if missingRefBehaviour == models.ImportMissingRefEnumFail {
-			return nil, fmt.Errorf("tags [%s] not found", strings.Join(missingTags, ", "))
+	for i := range a {
+		if a[i] != b[i] {
+			return false
 		}
+	}
 
-		if missingRefBehaviour == models.ImportMissingRefEnumCreate {
-			createdTags, err := createTags(ctx, tagWriter, missingTags)
-			if err != nil {
-				return nil, fmt.Errorf("error creating tags: %v", err)
-			}
+	return true
+}
 
-			tags = append(tags, createdTags...)
+// mergeStashIDs merges the stash IDs in add into stashIDs by endpoint. A
+// stash ID in add replaces the stash ID of the same endpoint in place, and
+// is otherwise appended. Multiple stash IDs for an endpoint in add are
+// reduced to the last.
+func mergeStashIDs(stashIDs []models.StashID, add []models.StashID) []models.StashID {
+	ret := append([]models.StashID(nil), stashIDs...)
+	for _, s := range add {
+		found := false
+		for idx := range ret {
+			if ret[idx].Endpoint == s.Endpoint {
+				ret[idx] = s
+				found = true
+				break
+			}
 		}
 
-		// ignore if MissingRefBehaviour set to Ignore
+		if !found {
+			ret = append(ret, s)
+		}
 	}
 
-	return tags, nil
+	return ret
 }
 
-func createTags(ctx context.Context, tagWriter tag.NameFinderCreator, names []string) ([]*models.Tag, error) {
-	var ret []*models.Tag
-	for _, name := range names {
-		newTag := *models.NewTag(name)
+// tagUpdateMode returns how the input tags are applied to the performer.
+// A full import uses RelationshipUpdateModeSet, replacing the existing tags
+// with the input tags, so that tags added outside of the import are removed.
+// A merge import, where the input specifies tags to remove, uses
+// RelationshipUpdateModeAdd, adding the input tags to the existing tags.
+// When merging according to MergeStrategyMerge, MergeRelationships is used.
+func (i *Importer) tagUpdateMode() models.RelationshipUpdateMode {
+	if len(i.Input.RemoveTags) > 0 {
+		return models.RelationshipUpdateModeAdd
+	}
 
-		created, err := tagWriter.Create(ctx, newTag)
-		if err != nil {
-			return nil, err
-		}
+	if i.merging() {
+		return i.mergeRelationships()
+	}
+
+	return models.RelationshipUpdateModeSet
+}
 
-		ret = append(ret, created)
+// postImportTags sets the performer's tags according to tagUpdateMode.
+func (i *Importer) postImportTags(ctx context.Context, id int) error {
+	tagIDs, set, err := i.tagIDsToSet(ctx, id)
+	if err != nil || !set {
+		return err
 	}
 
-	return ret, nil
+	if err := i.ReaderWriter.UpdateTags(ctx, id, tagIDs); err != nil {
+		return fmt.Errorf("failed to associate tags: %v", err)
+	}
+
+	i.changed = append(i.changed, "tags")
+
+	return nil
 }
 
-func (i *Importer) PostImport(ctx context.Context, id int) error {
-	if len(i.tags) > 0 {
-		var tagIDs []int
-		for _, t := range i.tags {
-			tagIDs = append(tagIDs, t.ID)
-		}
-		if err := i.ReaderWriter.UpdateTags(ctx, id, tagIDs); err != nil {
-			return fmt.Errorf("failed to associate tags: %v", err)
-		}
+// tagIDsToSet returns the tag IDs to set on the performer according to
+// tagUpdateMode. It returns false if the tags are left unchanged.
+func (i *Importer) tagIDsToSet(ctx context.Context, id int) ([]int, bool, error) {
+	// tags are not authoritative in the input
+	if i.fieldMask != nil && !i.fieldMask["tags"] {
+		return nil, false, nil
 	}
 
-	if len(i.imageData) > 0 {
-		if err := i.ReaderWriter.UpdateImage(ctx, id, i.imageData); err != nil {
-			return fmt.Errorf("error setting performer image: %v", err)
-		}
+	// empty input tags leave the existing tags unchanged when merging
+	if i.merging() && len(i.Input.Tags) == 0 && len(i.Input.RemoveTags) == 0 {
+		return nil, false, nil
+	}
+
+	var tagIDs []int
+	for _, t := range i.tags {
+		tagIDs = intslice.IntAppendUnique(tagIDs, t.ID)
 	}
 
-	if len(i.Input.StashIDs) > 0 {
-		if err := i.ReaderWriter.UpdateStashIDs(ctx, id, i.Input.StashIDs); err != nil {
-			return fmt.Errorf("error setting stash id: %v", err)
+	switch i.tagUpdateMode() {
+	case models.RelationshipUpdateModeAdd:
+		existingIDs, err := i.ReaderWriter.GetTagIDs(ctx, id)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get existing tags: %v", err)
+		}
+
+		var removeIDs []int
+		for _, t := range i.removeTags {
+			removeIDs = append(removeIDs, t.ID)
+		}
+
+		tagIDs = intslice.IntExclude(intslice.IntAppendUniques(existingIDs, tagIDs), removeIDs)
+	default:
+		// a created performer has no existing tags to replace
+		if len(tagIDs) == 0 && !i.updated {
+			return nil, false, nil
 		}
 	}
 
-	return nil
+	return tagIDs, true, nil
 }
 
+// Name returns the name of the performer. This is the canonical name if it
+// was adopted in PreImport, otherwise the input name.
 func (i *Importer) Name() string {
+	if i.canonicalName != "" {
+		return i.canonicalName
+	}
 	return i.Input.Name
 }
 
+// Changed returns the parts of the performer that were written by the import.
+func (i *Importer) Changed() []string {
+	return i.changed
+}
+
+// Warnings returns the non-fatal problems encountered during the import.
+func (i *Importer) Warnings() []string {
+	return i.warnings
+}
+
+// Skipped returns true if the import did not write the performer.
+func (i *Importer) Skipped() bool {
+	return i.skipped
+}
+
+// Unchanged returns true if the existing performer and its related data
+// were identical to the input, so were not updated. Without SkipUnchanged,
+// this is only reported if the ReaderWriter implements
+// ChangeReportingUpdater and the performer is updated in full, in which case
+// the related data is compared once the writer reports the performer
+// unchanged.
+func (i *Importer) Unchanged() bool {
+	return i.unchanged
+}
+
+func (i *Importer) addWarning(format string, args ...interface{}) {
+	i.warnings = append(i.warnings, fmt.Sprintf(format, args...))
+}
+
+// claimID registers the performer ID with the batch, and applies the
+// batch's DuplicateRecordPolicy if another record already resolved to it.
+func (i *Importer) claimID(id int) error {
+	if i.Batch == nil {
+		return nil
+	}
+
+	other, dupe := i.Batch.claim(id, i.Name())
+	if !dupe {
+		i.claimedID = id
+		return nil
+	}
+
+	switch i.Batch.DuplicateRecordPolicy {
+	case DuplicateRecordPolicyFail:
+		return fmt.Errorf("performer %d was already imported from record %q", id, other)
+	case DuplicateRecordPolicyFirst:
+		i.addWarning("skipping record: performer %d was already imported from record %q", id, other)
+		i.skipped = true
+	default:
+		i.addWarning("overwriting performer %d previously imported from record %q", id, other)
+	}
+
+	return nil
+}
+
+// FindExistingID returns the ID of the existing performer that the input
+// resolves to, or nil if there is none. Performers sharing any of the input
+// stash IDs are matched first, so that performers renamed upstream are not
+// duplicated. Otherwise the performer is found by name, then by alias if
+// MatchAliases is set. If the stash ID name was adopted, a performer with
+// the input name is used if no performer has the canonical name, so that it
+// is renamed. Name and alias matches must have the same disambiguation as
+// the input, so that performers sharing a name are kept distinct.
+//
+// If a performer with the same name was created earlier in the Batch, it is
+// only matched if it has the same disambiguation. Otherwise, a separate
+// performer is created.
 func (i *Importer) FindExistingID(ctx context.Context) (*int, error) {
-	const nocase = false
-	existing, err := i.ReaderWriter.FindByNames(ctx, []string{i.Name()}, nocase)
+	if i.Batch != nil {
+		created := i.Batch.createdIDs(i.Name())
+		if id, found := created[i.performer.Disambiguation]; found {
+			if err := i.claimID(id); err != nil {
+				return nil, err
+			}
+			return &id, nil
+		}
+	}
+
+	if id, err := i.findByStashIDs(ctx); err != nil || id != nil {
+		return id, err
+	}
+
+	names := []string{i.Name()}
+	if i.canonicalName != "" {
+		names = append(names, i.Input.Name)
+	}
+
+	existing, err := i.ReaderWriter.FindByNames(ctx, names, i.CaseInsensitiveMatch)
 	if err != nil {
 		return nil, err
 	}
 
+	existing = i.sameDisambiguation(existing)
+
 	if len(existing) > 0 {
-		id := existing[0].ID
+		id := selectExisting(existing, names)
+		if err := i.claimID(id); err != nil {
+			return nil, err
+		}
 		return &id, nil
 	}
 
+	return i.findByAlias(ctx)
+}
+
+// findByAlias returns the ID of the performer with the input name as an
+// alias, if MatchAliases is set.
+func (i *Importer) findByAlias(ctx context.Context) (*int, error) {
+	if !i.MatchAliases {
+		return nil, nil
+	}
+
+	existing, err := i.ReaderWriter.FindByAlias(ctx, i.Name(), i.CaseInsensitiveMatch)
+	if err != nil {
+		return nil, fmt.Errorf("error finding performer by alias: %v", err)
+	}
+
+	existing = i.sameDisambiguation(existing)
+
+	if len(existing) == 0 {
+		return nil, nil
+	}
+
+	if len(existing) > 1 {
+		var names []string
+		for _, p := range existing {
+			names = append(names, fmt.Sprintf("%q (%d)", p.Name, p.ID))
+		}
+
+		if i.MissingRefBehaviour == models.ImportMissingRefEnumFail {
+			return nil, fmt.Errorf("alias %q is ambiguous: shared by performers %s", i.Name(), strings.Join(names, ", "))
+		}
+
+		i.addWarning("not matching by ambiguous alias %q: shared by performers %s", i.Name(), strings.Join(names, ", "))
+		return nil, nil
+	}
+
+	id := existing[0].ID
+	if err := i.claimID(id); err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// selectExisting returns the ID of the performer to use from the performers
+// matching the names. Performers matching an earlier name are preferred,
+// and exact case matches are preferred to case-insensitive matches. Ties are
+// broken by the lowest ID.
+func selectExisting(existing []*models.Performer, names []string) int {
+	rank := func(p *models.Performer) int {
+		for i, name := range names {
+			if p.Name == name {
+				return i
+			}
+		}
+		return len(names)
+	}
+
+	best := existing[0]
+	for _, p := range existing[1:] {
+		r, bestRank := rank(p), rank(best)
+		if r < bestRank || (r == bestRank && p.ID < best.ID) {
+			best = p
+		}
+	}
+
+	return best.ID
+}
+
+// findByStashIDs returns the ID of the first performer sharing one of the
+// input stash IDs, or nil if there is none.
+func (i *Importer) findByStashIDs(ctx context.Context) (*int, error) {
+	for _, stashID := range i.Input.StashIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		existing, err := i.ReaderWriter.FindByStashID(ctx, stashID)
+		if err != nil {
+			return nil, fmt.Errorf("error finding performer by stash ID %s: %v", stashID.StashID, err)
+		}
+
+		if len(existing) > 0 {
+			id := existing[0].ID
+			if err := i.claimID(id); err != nil {
+				return nil, err
+			}
+			return &id, nil
+		}
+	}
+
 	return nil, nil
 }
 
+// sameDisambiguation returns the performers with the same disambiguation
+// as the input.
+func (i *Importer) sameDisambiguation(performers []*models.Performer) []*models.Performer {
+	var ret []*models.Performer
+	for _, p := range performers {
+		if p.Disambiguation == i.performer.Disambiguation {
+			ret = append(ret, p)
+		}
+	}
+
+	return ret
+}
+
 func (i *Importer) Create(ctx context.Context) (*int, error) {
+	if i.DryRun {
+		i.plan.Action = PreviewActionCreate
+		id := 0
+		return &id, nil
+	}
+
 	err := i.ReaderWriter.Create(ctx, &i.performer)
 	if err != nil {
 		return nil, fmt.Errorf("error creating performer: %v", err)
 	}
 
+	i.changed = append(i.changed, "performer")
+
 	id := i.performer.ID
+	i.changes.ID = id
+	i.changes.Created = true
+	if i.Batch != nil {
+		i.Batch.recordCreated(i.Name(), i.performer.Disambiguation, id)
+	}
+
+	if err := i.claimID(id); err != nil {
+		return nil, err
+	}
+
 	return &id, nil
 }
 
 func (i *Importer) Update(ctx context.Context, id int) error {
+	if i.DryRun {
+		i.plan.Action = PreviewActionUpdate
+		if i.skipped {
+			i.plan.Action = PreviewActionSkip
+		}
+		i.plan.ID = id
+		return nil
+	}
+
+	if i.skipped {
+		return nil
+	}
+
 	performer := i.performer
-	performer.ID = id
-	err := i.ReaderWriter.Update(ctx, &performer)
+	if i.merging() || i.SkipUnchanged || i.defaultedGender {
+		existing, err := i.ReaderWriter.Find(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error finding existing performer: %v", err)
+		}
+		if existing == nil {
+			return fmt.Errorf("existing performer with id %d not found", id)
+		}
+
+		i.performer = i.keepExistingGender(i.performer, *existing)
+		performer = i.performer
+
+		if i.SkipUnchanged {
+			unchanged, err := i.matchesExisting(ctx, id, *existing)
+			if err != nil {
+				return fmt.Errorf("error comparing existing performer: %v", err)
+			}
+
+			if unchanged {
+				i.skipped = true
+				i.unchanged = true
+				i.changes.ID = id
+				i.changes.Skipped = true
+				return nil
+			}
+		}
+
+		if i.merging() {
+			performer = i.mergeInput(*existing, i.performer)
+		}
+	}
+
+	var err error
+	changed := true
+	if mask := i.updateMask(); mask != nil {
+		// only update the fields specified in the input
+		_, err = i.ReaderWriter.UpdatePartial(ctx, id, mask.partial(performer))
+	} else {
+		performer.ID = id
+		if cu, ok := i.ReaderWriter.(ChangeReportingUpdater); ok {
+			changed, err = cu.UpdateIfChanged(ctx, &performer)
+		} else {
+			err = i.ReaderWriter.Update(ctx, &performer)
+		}
+	}
+
 	if err != nil {
 		return fmt.Errorf("error updating existing performer: %v", err)
 	}
 
+	i.updated = true
+	i.changes.ID = id
+
+	if !changed {
+		// the performer is only unchanged if its related data would not be
+		// changed by PostImport either
+		match, err := i.relatedMatch(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error comparing existing performer: %v", err)
+		}
+
+		if match {
+			i.skipped = true
+			i.unchanged = true
+			i.changes.Skipped = true
+			return nil
+		}
+	}
+
+	i.changes.Updated = true
+	if changed {
+		i.changed = append(i.changed, "performer")
+	}
+
 	return nil
 }
 
-func performerJSONToPerformer(performerJSON jsonschema.Performer) models.Performer {
-	checksum := md5.FromString(performerJSON.Name)
+// mergeInput merges input into existing using mergePerformer. Unlike other
+// fields, the rating is also cleared if the input explicitly has no rating.
+func (i *Importer) mergeInput(existing models.Performer, input models.Performer) models.Performer {
+	ret := mergePerformer(existing, input)
+	if i.clearRating {
+		ret.Rating = nil
+	}
+
+	return ret
+}
+
+// mergePerformer returns existing with the fields that are set in input
+// overwritten.
+func mergePerformer(existing models.Performer, input models.Performer) models.Performer {
+	ret := existing
 
-	newPerformer := models.Performer{
-		Name:          performerJSON.Name,
-		Checksum:      checksum,
-		Gender:        models.GenderEnum(performerJSON.Gender),
-		URL:           performerJSON.URL,
-		Ethnicity:     performerJSON.Ethnicity,
-		Country:       performerJSON.Country,
-		EyeColor:      performerJSON.EyeColor,
-		Height:        performerJSON.Height,
-		Measurements:  performerJSON.Measurements,
-		FakeTits:      performerJSON.FakeTits,
-		CareerLength:  performerJSON.CareerLength,
-		Tattoos:       performerJSON.Tattoos,
-		Piercings:     performerJSON.Piercings,
-		Aliases:       performerJSON.Aliases,
-		Twitter:       performerJSON.Twitter,
-		Instagram:     performerJSON.Instagram,
-		Details:       performerJSON.Details,
-		HairColor:     performerJSON.HairColor,
-		Favorite:      performerJSON.Favorite,
-		IgnoreAutoTag: performerJSON.IgnoreAutoTag,
-		CreatedAt:     performerJSON.CreatedAt.GetTime(),
-		UpdatedAt:     performerJSON.UpdatedAt.GetTime(),
-	}
-
-	if performerJSON.Birthdate != "" {
-		d, err := utils.ParseDateStringAsTime(performerJSON.Birthdate)
-		if err == nil {
-			newPerformer.Birthdate = &models.Date{
-				Time: d,
-			}
-		}
-	}
-	if performerJSON.Rating != 0 {
-		newPerformer.Rating = &performerJSON.Rating
-	}
-	if performerJSON.DeathDate != "" {
-		d, err := utils.ParseDateStringAsTime(performerJSON.DeathDate)
-		if err == nil {
-			newPerformer.DeathDate = &models.Date{
-				Time: d,
-			}
+	src := reflect.ValueOf(input)
+	dest := reflect.ValueOf(&ret).Elem()
+	t := src.Type()
+	for f := 0; f < t.NumField(); f++ {
+		if t.Field(f).Name == "ID" {
+			continue
+		}
+
+		if v := src.Field(f); !v.IsZero() {
+			dest.Field(f).Set(v)
+		}
+	}
+
+	return ret
+}
+
+// updateMask returns the fields to set when updating an existing performer,
+// or nil if all fields should be replaced. In sync mode, the timestamps are
+// excluded so that the created time is preserved and the updated time is
+// set to the current time.
+func (i *Importer) updateMask() fieldMask {
+	if i.Mode != ImportModeSync {
+		return i.fieldMask
+	}
+
+	mask := i.fieldMask
+	if mask == nil {
+		mask = newFullFieldMask()
+	}
+
+	return mask.without("created_at", "updated_at")
+}
+
+// Checksum returns the checksum of a performer with the provided name and
+// disambiguation. The disambiguation is only included if set, so that the
+// checksums of existing performers are unchanged.
+func Checksum(name string, disambiguation string) string {
+	if disambiguation == "" {
+		return md5.FromString(name)
+	}
+
+	return md5.FromString(name + "\x00" + disambiguation)
+}
+
+// performerURLs returns the urls of the input, with the single URL field
+// first. URLs are canonicalized if URLCanonicalizer is set, and duplicate
+// and empty URLs are omitted.
+func (i *Importer) performerURLs(performerJSON jsonschema.Performer) []string {
+	var ret []string
+	for _, u := range append([]string{performerJSON.URL}, performerJSON.URLs...) {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+
+		if i.URLCanonicalizer != nil {
+			u = i.URLCanonicalizer.Canonicalize(u)
 		}
+
+		ret = stringslice.StrAppendUnique(ret, u)
+	}
+
+	return ret
+}
+
+func (i *Importer) performerJSONToPerformer(performerJSON jsonschema.Performer) models.Performer {
+	checksum := Checksum(performerJSON.Name, performerJSON.Disambiguation)
+
+	newPerformer := models.Performer{
+		Name:           performerJSON.Name,
+		SortName:       performerJSON.SortName,
+		SortNameLocale: performerJSON.SortNameLocale,
+		Disambiguation: performerJSON.Disambiguation,
+		Checksum:       checksum,
+		URL:            performerJSON.URL,
+		Ethnicity:      performerJSON.Ethnicity,
+		Country:        performerJSON.Country,
+		EyeColor:       performerJSON.EyeColor,
+		Height:         performerJSON.Height,
+		Measurements:   performerJSON.Measurements,
+		FakeTits:       performerJSON.FakeTits,
+		CareerLength:   performerJSON.CareerLength,
+		Tattoos:        performerJSON.Tattoos,
+		Piercings:      performerJSON.Piercings,
+		Aliases:        performerJSON.Aliases,
+		Twitter:        performerJSON.Twitter,
+		Instagram:      performerJSON.Instagram,
+		Details:        performerJSON.Details,
+		HairColor:      performerJSON.HairColor,
+		Favorite:       performerJSON.Favorite,
+		IgnoreAutoTag:  performerJSON.IgnoreAutoTag,
+		PropagateTags:  performerJSON.PropagateTags,
+		CustomFields:   copyCustomFields(performerJSON.CustomFields),
+		CreatedAt:      performerJSON.CreatedAt.GetTime(),
+		UpdatedAt:      performerJSON.UpdatedAt.GetTime(),
 	}
 
+	newPerformer.Birthdate = i.parseDate("birthdate", performerJSON.Birthdate)
+	newPerformer.Rating = i.performerRating(performerJSON.Rating)
+	// an explicit zero or null rating clears the rating of a merged
+	// performer, whereas an absent rating leaves it unchanged
+	i.clearRating = newPerformer.Rating == nil && performerJSON.Present["rating"]
+	newPerformer.DeathDate = i.parseDate("death_date", performerJSON.DeathDate)
+
 	if performerJSON.Weight != 0 {
 		newPerformer.Weight = &performerJSON.Weight
 	}
 
+	if performerJSON.Gender != "" {
+		gender, valid := ParseGender(performerJSON.Gender)
+		switch {
+		case valid:
+			newPerformer.Gender = gender
+		case i.defaultGender() != "":
+			i.addWarning("invalid gender %q, using %s", performerJSON.Gender, i.DefaultGender)
+		default:
+			i.addWarning("invalid gender %q, leaving unset", performerJSON.Gender)
+		}
+	}
+
+	i.defaultedGender = false
+	if newPerformer.Gender == "" && i.defaultGender() != "" {
+		newPerformer.Gender = i.defaultGender()
+		i.defaultedGender = true
+	}
+
+	if i.CanonicalizeCountry && performerJSON.Country != "" {
+		if country, valid := utils.ParseCountry(performerJSON.Country); valid {
+			newPerformer.Country = country
+		} else {
+			i.addWarning("unknown country %q, leaving unchanged", performerJSON.Country)
+		}
+	}
+
+	if i.Mode == ImportModeSync {
+		newPerformer.UpdatedAt = time.Now()
+	}
+
+	if !i.RawAliases {
+		newPerformer.Aliases = NormalizeAliases(newPerformer.Aliases, newPerformer.Name)
+	}
+
+	if i.NormalizeBodyModifications {
+		newPerformer.Tattoos = NormalizeBodyModification(newPerformer.Tattoos)
+		newPerformer.Piercings = NormalizeBodyModification(newPerformer.Piercings)
+	}
+
+	newPerformer.URLs = i.performerURLs(performerJSON)
+	if len(newPerformer.URLs) > 0 {
+		newPerformer.URL = newPerformer.URLs[0]
+	}
+
 	return newPerformer
 }
@@ -0,0 +1,404 @@
+package performer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+// DefaultImportBatchSize is the default number of performers whose existing
+// performers are looked up together by ImportAll.
+const DefaultImportBatchSize = 100
+
+// ImportAllOptions configures ImportAll.
+type ImportAllOptions struct {
+	// Importer is the template for the importer of each performer. Its
+	// Input is replaced with each input. If Batch is nil, a new Batch is
+	// shared by the run.
+	Importer Importer
+
+	// DuplicateBehaviour determines what happens to existing performers.
+	// They are updated unless it is ImportDuplicateEnumIgnore or
+	// ImportDuplicateEnumFail. See models.PerformImport.
+	DuplicateBehaviour models.ImportDuplicateEnum
+
+	// BatchSize is the number of inputs whose existing performers are
+	// looked up together. Defaults to DefaultImportBatchSize.
+	BatchSize int
+
+	// TxnManager, if set, imports each performer in its own transaction.
+	// Otherwise, ImportAll must be called within a transaction.
+	TxnManager txn.Manager
+
+	// Progress, if set, is called after each performer is imported, with
+	// the number of performers processed so far, the performer name and the
+	// error importing it, if any.
+	Progress func(n int, name string, err error)
+}
+
+// ImportAllErrors are the errors importing performers in ImportAll, keyed by
+// performer name.
+type ImportAllErrors map[string]error
+
+func (e ImportAllErrors) Error() string {
+	var names []string
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var s []string
+	for _, name := range names {
+		s = append(s, fmt.Sprintf("%s: %v", name, e[name]))
+	}
+	return strings.Join(s, "; ")
+}
+
+func (e ImportAllErrors) add(name string, err error) {
+	if existing, found := e[name]; found {
+		err = append(ImportErrors{existing}, err)
+	}
+
+	e[name] = err
+}
+
+// ImportAll imports the performers received from inputs until it is closed.
+// Each performer is imported using a copy of opts.Importer, so behaves as if
+// imported individually. Existing performers are looked up by name in
+// batches of opts.BatchSize. The tags of each batch are looked up together,
+// and the tags found or created are recorded in the Batch for the rest of
+// the run.
+//
+// Each performer is imported using models.PerformImport, and the result of
+// each import is returned in the order received, as for the import task.
+//
+// Errors importing individual performers do not stop the run, and are
+// returned as ImportAllErrors. Performers imported with errors under
+// ContinueOnError are also reported. If ctx is cancelled, the results so far
+// and the context error are returned.
+func ImportAll(ctx context.Context, inputs <-chan jsonschema.Performer, opts ImportAllOptions) ([]*models.ImportResult, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultImportBatchSize
+	}
+
+	batch := opts.Importer.Batch
+	if batch == nil {
+		batch = &Batch{}
+	}
+
+	r := &bulkImport{
+		opts:      opts,
+		batch:     batch,
+		performer: &prefetchedPerformerReaderWriter{NameFinderCreatorUpdater: opts.Importer.ReaderWriter},
+		errs:      make(ImportAllErrors),
+	}
+
+	for {
+		inputBatch, err := receiveBatch(ctx, inputs, batchSize)
+		if err != nil {
+			return r.results, err
+		}
+
+		if len(inputBatch) == 0 {
+			break
+		}
+
+		if err := r.importBatch(ctx, inputBatch); err != nil {
+			return r.results, err
+		}
+	}
+
+	if len(r.errs) > 0 {
+		return r.results, r.errs
+	}
+
+	return r.results, nil
+}
+
+// receiveBatch receives up to size inputs. It returns fewer inputs once
+// inputs is closed.
+func receiveBatch(ctx context.Context, inputs <-chan jsonschema.Performer, size int) ([]jsonschema.Performer, error) {
+	var ret []jsonschema.Performer
+	for len(ret) < size {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case input, ok := <-inputs:
+			if !ok {
+				return ret, nil
+			}
+			ret = append(ret, input)
+		}
+	}
+
+	return ret, nil
+}
+
+type bulkImport struct {
+	opts      ImportAllOptions
+	batch     *Batch
+	performer *prefetchedPerformerReaderWriter
+
+	processed int
+	results   []*models.ImportResult
+	errs      ImportAllErrors
+}
+
+func (r *bulkImport) withTxn(ctx context.Context, fn txn.TxnFunc) error {
+	if r.opts.TxnManager == nil {
+		return fn(ctx)
+	}
+
+	return txn.WithTxn(ctx, r.opts.TxnManager, fn)
+}
+
+func (r *bulkImport) importBatch(ctx context.Context, inputs []jsonschema.Performer) error {
+	var names []string
+	for _, input := range inputs {
+		names = append(names, input.Name)
+	}
+
+	if err := r.withTxn(ctx, func(ctx context.Context) error {
+		if err := r.performer.prefetch(ctx, names, r.opts.Importer.CaseInsensitiveMatch); err != nil {
+			return fmt.Errorf("error finding existing performers: %w", err)
+		}
+
+		return r.prefetchTags(ctx, inputs)
+	}); err != nil {
+		return err
+	}
+
+	for _, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		i := r.opts.Importer
+		i.Input = input
+		i.Batch = r.batch
+		i.ReaderWriter = r.performer
+
+		result, err := r.importOne(ctx, &i)
+		r.results = append(r.results, result)
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			r.errs.add(i.Name(), err)
+		}
+
+		r.processed++
+		if r.opts.Progress != nil {
+			r.opts.Progress(r.processed, i.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// prefetchTags looks up the tags of all inputs together, recording them in
+// the Batch so that records do not look them up individually. Under
+// ImportMissingRefEnumCreate, the missing tags are also looked up by alias.
+// They are created by the first record using them, in a single call, so
+// that they are rolled back with that record.
+func (r *bulkImport) prefetchTags(ctx context.Context, inputs []jsonschema.Performer) error {
+	i := r.opts.Importer
+
+	var names []string
+	for _, input := range inputs {
+		names = append(names, i.prefixTags(input.Tags)...)
+	}
+	if i.TagCollection != "" {
+		names = append(names, i.TagCollection)
+	}
+
+	_, _, uncached := r.batch.cachedTags(uniqueTagNames(names))
+	if len(uncached) == 0 {
+		return nil
+	}
+
+	found, err := i.TagWriter.FindByNames(ctx, uncached, false)
+	if err != nil {
+		// leave the tags to be looked up by each record, which handles the
+		// error according to MissingRefBehaviour
+		return nil
+	}
+
+	byName := make(map[string]*models.Tag)
+	for _, t := range found {
+		byName[t.Name] = t
+	}
+	r.batch.addTags(byName)
+
+	if i.MissingRefBehaviour != models.ImportMissingRefEnumCreate {
+		return nil
+	}
+
+	missing := stringslice.StrFilter(uncached, func(name string) bool {
+		return byName[name] == nil
+	})
+
+	aliasTags, err := findTagsByAlias(ctx, i.TagWriter, missing)
+	if err != nil {
+		return err
+	}
+	r.batch.addTags(aliasTags)
+
+	r.batch.addMissingTags(stringslice.StrFilter(missing, func(name string) bool {
+		return aliasTags[name] == nil
+	}))
+
+	return nil
+}
+
+// importOne imports a single performer in its own transaction. Errors
+// recorded under ContinueOnError are returned, but do not roll back the
+// transaction. The returned result is never nil.
+func (r *bulkImport) importOne(ctx context.Context, i *Importer) (*models.ImportResult, error) {
+	var result *models.ImportResult
+	err := i.WithTxn(ctx, r.opts.TxnManager, func(ctx context.Context) error {
+		var err error
+		result, err = models.PerformImport(ctx, i, r.opts.DuplicateBehaviour)
+		return err
+	})
+
+	if result == nil {
+		// the transaction failed to start
+		result = &models.ImportResult{Action: models.ImportActionFailed}
+	}
+
+	return result, err
+}
+
+// prefetchedPerformerReaderWriter answers FindByNames from performers
+// prefetched by name. Lookups including names that were not prefetched, or
+// whose performers have since been written, are passed through.
+type prefetchedPerformerReaderWriter struct {
+	NameFinderCreatorUpdater
+
+	mutex  sync.Mutex
+	nocase bool
+	// fetched is the set of prefetched names
+	fetched    map[string]bool
+	performers []*models.Performer
+}
+
+func (rw *prefetchedPerformerReaderWriter) nameKey(name string) string {
+	if rw.nocase {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+func (rw *prefetchedPerformerReaderWriter) prefetch(ctx context.Context, names []string, nocase bool) error {
+	performers, err := rw.NameFinderCreatorUpdater.FindByNames(ctx, names, nocase)
+	if err != nil {
+		return err
+	}
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	rw.nocase = nocase
+	rw.fetched = make(map[string]bool)
+	for _, name := range names {
+		rw.fetched[rw.nameKey(name)] = true
+	}
+	rw.performers = performers
+
+	return nil
+}
+
+func (rw *prefetchedPerformerReaderWriter) FindByNames(ctx context.Context, names []string, nocase bool) ([]*models.Performer, error) {
+	if ret, ok := rw.findPrefetched(names, nocase); ok {
+		return ret, nil
+	}
+
+	return rw.NameFinderCreatorUpdater.FindByNames(ctx, names, nocase)
+}
+
+func (rw *prefetchedPerformerReaderWriter) findPrefetched(names []string, nocase bool) ([]*models.Performer, bool) {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	if rw.fetched == nil || nocase != rw.nocase {
+		return nil, false
+	}
+
+	keys := make(map[string]bool)
+	for _, name := range names {
+		key := rw.nameKey(name)
+		if !rw.fetched[key] {
+			return nil, false
+		}
+		keys[key] = true
+	}
+
+	var ret []*models.Performer
+	for _, p := range rw.performers {
+		if keys[rw.nameKey(p.Name)] {
+			ret = append(ret, p)
+		}
+	}
+
+	return ret, true
+}
+
+// invalidate removes the names of the written performer from the
+// prefetched names, so that subsequent lookups see the change.
+func (rw *prefetchedPerformerReaderWriter) invalidate(id int, name string) {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	delete(rw.fetched, rw.nameKey(name))
+
+	var performers []*models.Performer
+	for _, p := range rw.performers {
+		if p.ID == id {
+			delete(rw.fetched, rw.nameKey(p.Name))
+			continue
+		}
+		performers = append(performers, p)
+	}
+	rw.performers = performers
+}
+
+func (rw *prefetchedPerformerReaderWriter) Create(ctx context.Context, newPerformer *models.Performer) error {
+	if err := rw.NameFinderCreatorUpdater.Create(ctx, newPerformer); err != nil {
+		return err
+	}
+
+	rw.invalidate(newPerformer.ID, newPerformer.Name)
+	return nil
+}
+
+func (rw *prefetchedPerformerReaderWriter) Update(ctx context.Context, updatedPerformer *models.Performer) error {
+	rw.invalidate(updatedPerformer.ID, updatedPerformer.Name)
+	return rw.NameFinderCreatorUpdater.Update(ctx, updatedPerformer)
+}
+
+// UpdateIfChanged implements ChangeReportingUpdater if the wrapped writer
+// does, and otherwise always updates the performer.
+func (rw *prefetchedPerformerReaderWriter) UpdateIfChanged(ctx context.Context, updatedPerformer *models.Performer) (bool, error) {
+	cu, ok := rw.NameFinderCreatorUpdater.(ChangeReportingUpdater)
+	if !ok {
+		return true, rw.Update(ctx, updatedPerformer)
+	}
+
+	rw.invalidate(updatedPerformer.ID, updatedPerformer.Name)
+	return cu.UpdateIfChanged(ctx, updatedPerformer)
+}
+
+func (rw *prefetchedPerformerReaderWriter) UpdatePartial(ctx context.Context, id int, updatedPerformer models.PerformerPartial) (*models.Performer, error) {
+	rw.invalidate(id, updatedPerformer.Name.Value)
+	return rw.NameFinderCreatorUpdater.UpdatePartial(ctx, id, updatedPerformer)
+}
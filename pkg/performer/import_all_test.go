@@ -0,0 +1,312 @@
+package performer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingPerformerWriter is an in-memory performer store that counts the
+// calls made to it. Methods not used when importing performers without
+// images or stash IDs are not implemented.
+type countingPerformerWriter struct {
+	NameFinderCreatorUpdater
+	calls      int
+	nextID     int
+	performers map[string]*models.Performer
+}
+
+func newCountingPerformerWriter(existing ...string) *countingPerformerWriter {
+	ret := &countingPerformerWriter{
+		performers: make(map[string]*models.Performer),
+	}
+	for _, name := range existing {
+		ret.nextID++
+		ret.performers[name] = &models.Performer{ID: ret.nextID, Name: name}
+	}
+	return ret
+}
+
+func (w *countingPerformerWriter) FindByNames(ctx context.Context, names []string, nocase bool) ([]*models.Performer, error) {
+	w.calls++
+	var ret []*models.Performer
+	for _, name := range names {
+		if p, found := w.performers[name]; found {
+			ret = append(ret, p)
+		}
+	}
+	return ret, nil
+}
+
+func (w *countingPerformerWriter) Create(ctx context.Context, newPerformer *models.Performer) error {
+	w.calls++
+	w.nextID++
+	newPerformer.ID = w.nextID
+	p := *newPerformer
+	w.performers[p.Name] = &p
+	return nil
+}
+
+func (w *countingPerformerWriter) Update(ctx context.Context, updatedPerformer *models.Performer) error {
+	w.calls++
+	p := *updatedPerformer
+	w.performers[p.Name] = &p
+	return nil
+}
+
+func (w *countingPerformerWriter) UpdateTags(ctx context.Context, performerID int, tagIDs []int) error {
+	w.calls++
+	return nil
+}
+
+// storingTagWriter is a countingTagWriter that finds the tags created in
+// it.
+type storingTagWriter struct {
+	*countingTagWriter
+	tags map[string]*models.Tag
+}
+
+func newStoringTagWriter() *storingTagWriter {
+	return &storingTagWriter{
+		countingTagWriter: &countingTagWriter{},
+		tags:              make(map[string]*models.Tag),
+	}
+}
+
+func (w *storingTagWriter) FindByNames(ctx context.Context, names []string, nocase bool) ([]*models.Tag, error) {
+	w.calls++
+	var ret []*models.Tag
+	for _, name := range names {
+		if t, found := w.tags[name]; found {
+			ret = append(ret, t)
+		}
+	}
+	return ret, nil
+}
+
+func (w *storingTagWriter) Create(ctx context.Context, newTag models.Tag) (*models.Tag, error) {
+	created, err := w.countingTagWriter.Create(ctx, newTag)
+	if err == nil {
+		w.tags[created.Name] = created
+	}
+	return created, err
+}
+
+func importAllInputs(n int, tags []string) []jsonschema.Performer {
+	var ret []jsonschema.Performer
+	for i := 0; i < n; i++ {
+		ret = append(ret, jsonschema.Performer{
+			Name: fmt.Sprintf("performer%d", i),
+			Tags: tags,
+		})
+	}
+	return ret
+}
+
+func sendAll(inputs []jsonschema.Performer) <-chan jsonschema.Performer {
+	ret := make(chan jsonschema.Performer, len(inputs))
+	for _, input := range inputs {
+		ret <- input
+	}
+	close(ret)
+	return ret
+}
+
+func TestImportAll(t *testing.T) {
+	performerWriter := newCountingPerformerWriter("existing")
+	tagWriter := &countingTagWriter{}
+
+	inputs := []jsonschema.Performer{
+		{Name: "p1", Tags: []string{"t1", "t2"}},
+		{Name: "existing", Tags: []string{"t2"}},
+		{Name: "invalid", Image: "not an image"},
+		{Name: "p2", Tags: []string{"t1"}, Gender: "Femal"},
+	}
+
+	type progress struct {
+		n    int
+		name string
+		err  bool
+	}
+	var got []progress
+
+	results, err := ImportAll(testCtx, sendAll(inputs), ImportAllOptions{
+		Importer: Importer{
+			ReaderWriter:        performerWriter,
+			TagWriter:           tagWriter,
+			MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+		},
+		BatchSize: 3,
+		Progress: func(n int, name string, err error) {
+			got = append(got, progress{n, name, err != nil})
+		},
+	})
+
+	assert.Equal(t, []progress{
+		{1, "p1", false},
+		{2, "existing", false},
+		{3, "invalid", true},
+		{4, "p2", false},
+	}, got)
+
+	// the results are those of importing each performer individually
+	var actions []models.ImportAction
+	for _, result := range results {
+		actions = append(actions, result.Action)
+		assert.Contains(t, result.Durations, models.ImportStageTotalDuration)
+	}
+	assert.Equal(t, []models.ImportAction{
+		models.ImportActionCreated,
+		models.ImportActionUpdated,
+		models.ImportActionFailed,
+		models.ImportActionCreated,
+	}, actions)
+	assert.Len(t, results[3].Warnings, 1)
+	assert.Contains(t, results[3].Durations, models.ImportStageCreate)
+
+	errs, ok := err.(ImportAllErrors)
+	if assert.True(t, ok, "expected ImportAllErrors, got %v", err) {
+		assert.Len(t, errs, 1)
+		assert.Error(t, errs["invalid"])
+		assert.True(t, strings.HasPrefix(err.Error(), "invalid: "))
+	}
+
+	assert.Len(t, performerWriter.performers, 3)
+	assert.Contains(t, performerWriter.performers, "p1")
+	assert.Contains(t, performerWriter.performers, "p2")
+
+	// both tags are created once, and found in the cache afterwards
+	assert.Equal(t, 2, tagWriter.nextID)
+}
+
+func TestImportAllTagCalls(t *testing.T) {
+	w := &countingTagWriter{}
+
+	_, err := ImportAll(testCtx, sendAll(importAllInputs(100, missingTagNames(10))), ImportAllOptions{
+		Importer: Importer{
+			ReaderWriter:        newCountingPerformerWriter(),
+			TagWriter:           countingBatchTagWriter{countingManyCreatorTagWriter{w}},
+			MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+		},
+	})
+
+	assert.NoError(t, err)
+
+	// the tags are looked up by name and alias once for the batch, and
+	// created once by the first record
+	assert.Equal(t, 3, w.calls)
+	assert.Equal(t, 10, w.nextID)
+}
+
+func TestImportAllSkipExisting(t *testing.T) {
+	performerWriter := newCountingPerformerWriter("existing")
+
+	_, err := ImportAll(testCtx, sendAll([]jsonschema.Performer{
+		{Name: "existing", Details: "updated"},
+	}), ImportAllOptions{
+		Importer: Importer{
+			ReaderWriter: performerWriter,
+			TagWriter:    &countingTagWriter{},
+		},
+		DuplicateBehaviour: models.ImportDuplicateEnumIgnore,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", performerWriter.performers["existing"].Details)
+}
+
+func TestImportAllDuplicateNames(t *testing.T) {
+	performerWriter := newCountingPerformerWriter()
+
+	// the second record must see the performer created by the first, even
+	// though both were looked up in the same batch
+	_, err := ImportAll(testCtx, sendAll([]jsonschema.Performer{
+		{Name: "p1"},
+		{Name: "p1", Details: "updated"},
+	}), ImportAllOptions{
+		Importer: Importer{
+			ReaderWriter: performerWriter,
+			TagWriter:    &countingTagWriter{},
+			Batch: &Batch{
+				DuplicateRecordPolicy: DuplicateRecordPolicyLast,
+			},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, performerWriter.nextID)
+	assert.Equal(t, "updated", performerWriter.performers["p1"].Details)
+}
+
+func TestImportAllCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(testCtx)
+	cancel()
+
+	inputs := make(chan jsonschema.Performer)
+	_, err := ImportAll(ctx, inputs, ImportAllOptions{
+		Importer: Importer{
+			ReaderWriter: newCountingPerformerWriter(),
+			TagWriter:    &countingTagWriter{},
+		},
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkImportAll(b *testing.B) {
+	const performers = 100
+	tags := []string{"tag1", "tag2", "tag3"}
+
+	importer := func(performerWriter *countingPerformerWriter, tagWriter *storingTagWriter) Importer {
+		return Importer{
+			ReaderWriter:        performerWriter,
+			TagWriter:           tagWriter,
+			MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+		}
+	}
+
+	b.Run("PerRecord", func(b *testing.B) {
+		var calls int
+		for n := 0; n < b.N; n++ {
+			performerWriter := newCountingPerformerWriter()
+			tagWriter := newStoringTagWriter()
+			batch := &Batch{}
+
+			for _, input := range importAllInputs(performers, tags) {
+				i := importer(performerWriter, tagWriter)
+				i.Input = input
+				i.Batch = batch
+				if err := i.Import(testCtx, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			calls += performerWriter.calls + tagWriter.calls
+		}
+
+		b.ReportMetric(float64(calls)/float64(b.N), "db-calls/op")
+	})
+
+	b.Run("ImportAll", func(b *testing.B) {
+		var calls int
+		for n := 0; n < b.N; n++ {
+			performerWriter := newCountingPerformerWriter()
+			tagWriter := newStoringTagWriter()
+
+			if _, err := ImportAll(testCtx, sendAll(importAllInputs(performers, tags)), ImportAllOptions{
+				Importer: importer(performerWriter, tagWriter),
+			}); err != nil {
+				b.Fatal(err)
+			}
+
+			calls += performerWriter.calls + tagWriter.calls
+		}
+
+		b.ReportMetric(float64(calls)/float64(b.N), "db-calls/op")
+	})
+}
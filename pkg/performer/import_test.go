@@ -2,17 +2,26 @@ package performer
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/mock"
 
 	"github.com/stashapp/stash/pkg/hash/md5"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/json"
 	"github.com/stashapp/stash/pkg/models/jsonschema"
 	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+	"github.com/stashapp/stash/pkg/utils"
 	"github.com/stretchr/testify/assert"
 
 	"testing"
+	"time"
 )
 
 const invalidImage = "aW1hZ2VCeXRlcw&&"
@@ -21,6 +30,7 @@ const (
 	existingPerformerID = 100
 	existingTagID       = 105
 	errTagsID           = 106
+	errAttachmentsID    = 107
 
 	existingPerformerName = "existingPerformerName"
 	performerNameErr      = "performerNameErr"
@@ -32,6 +42,10 @@ const (
 
 var testCtx = context.Background()
 
+func intPtr(i int) *int {
+	return &i
+}
+
 func TestImporterName(t *testing.T) {
 	i := Importer{
 		Input: jsonschema.Performer{
@@ -60,237 +74,2316 @@ func TestImporterPreImport(t *testing.T) {
 
 	assert.Nil(t, err)
 	expectedPerformer := *createFullPerformer(0, performerName)
-	expectedPerformer.Checksum = md5.FromString(performerName)
+	expectedPerformer.Checksum = Checksum(performerName, disambiguation)
 	assert.Equal(t, expectedPerformer, i.performer)
 }
 
-func TestImporterPreImportWithTag(t *testing.T) {
-	tagReaderWriter := &mocks.TagReaderWriter{}
-
+func TestImporterPreImportCanonicalizeURL(t *testing.T) {
 	i := Importer{
-		TagWriter:           tagReaderWriter,
-		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+		URLCanonicalizer: &utils.URLCanonicalizer{
+			TrackingParams: utils.DefaultTrackingParams,
+		},
 		Input: jsonschema.Performer{
-			Tags: []string{
-				existingTagName,
-			},
+			Name: performerName,
+			URL:  "http://Example.com:80/performer/?utm_source=test",
 		},
 	}
 
-	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagName}, false).Return([]*models.Tag{
-		{
-			ID:   existingTagID,
-			Name: existingTagName,
-		},
-	}, nil).Once()
-	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagErr}, false).Return(nil, errors.New("FindByNames error")).Once()
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/performer", i.performer.URL)
+}
+
+func TestImporterPreImportURLs(t *testing.T) {
+	const (
+		homepage = "https://example.com"
+		imdb     = "https://imdb.com/name/nm0000001"
+	)
+
+	tests := []struct {
+		name    string
+		url     string
+		urls    []string
+		wantURL string
+		want    []string
+	}{
+		{"none", "", nil, "", nil},
+		{"legacy", homepage, nil, homepage, []string{homepage}},
+		{"multiple", "", []string{homepage, imdb}, homepage, []string{homepage, imdb}},
+		{"legacy first", imdb, []string{homepage}, imdb, []string{imdb, homepage}},
+		{"duplicates", homepage, []string{imdb, homepage, " ", imdb}, homepage, []string{homepage, imdb}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				Input: jsonschema.Performer{
+					Name: performerName,
+					URL:  tt.url,
+					URLs: tt.urls,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantURL, i.performer.URL)
+			assert.Equal(t, tt.want, i.performer.URLs)
+		})
+	}
+}
+
+func TestImporterFieldMask(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		Input:        *createFullJSONPerformer(performerName, image),
+	}
+	i.Input.Fields = []string{"url", "details", "stash_ids"}
+	i.Input.StashIDs = nil
 
 	err := i.PreImport(testCtx)
 	assert.Nil(t, err)
-	assert.Equal(t, existingTagID, i.tags[0].ID)
+	assert.Nil(t, i.imageData)
+	assert.Equal(t, performerName, i.performer.Name)
+	assert.Equal(t, url, i.performer.URL)
+	assert.Equal(t, details, i.performer.Details)
+	assert.Empty(t, i.performer.Aliases)
+	assert.Nil(t, i.performer.Birthdate)
+	assert.Nil(t, i.performer.Rating)
+	assert.False(t, i.performer.Favorite)
+
+	readerWriter.On("UpdatePartial", testCtx, performerID, mock.MatchedBy(func(p models.PerformerPartial) bool {
+		return p.URL.Set && p.URL.Value == url &&
+			p.Details.Set && p.Details.Value == details &&
+			!p.Name.Set && !p.Birthdate.Set && !p.Rating.Set && !p.Favorite.Set &&
+			p.UpdatedAt.Set
+	})).Return(nil, nil).Once()
+	// stash_ids is authoritative, so the empty value clears them
+	readerWriter.On("UpdateStashIDs", testCtx, performerID, []models.StashID(nil)).Return(nil).Once()
+
+	err = i.Update(testCtx, performerID)
+	assert.Nil(t, err)
 
-	i.Input.Tags = []string{existingTagErr}
+	err = i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+
+	i.Input.Fields = []string{"invalid"}
 	err = i.PreImport(testCtx)
 	assert.NotNil(t, err)
+}
 
-	tagReaderWriter.AssertExpectations(t)
+func loadJSONPerformer(t *testing.T, data string) jsonschema.Performer {
+	fsys := fstest.MapFS{
+		"performer.json": &fstest.MapFile{Data: []byte(data)},
+	}
+
+	ret, err := jsonschema.LoadPerformerFile(fsys, "performer.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return *ret
 }
 
-func TestImporterPreImportWithMissingTag(t *testing.T) {
-	tagReaderWriter := &mocks.TagReaderWriter{}
+func TestImporterUpdatePartial(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
 
 	i := Importer{
-		TagWriter: tagReaderWriter,
-		Input: jsonschema.Performer{
-			Tags: []string{
-				missingTagName,
-			},
-		},
-		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+		ReaderWriter:  readerWriter,
+		MergeStrategy: MergeStrategyPartial,
+		Input: loadJSONPerformer(t, `{
+			"name": "`+performerName+`",
+			"details": "`+details+`",
+			"country": "",
+			"birthdate": null,
+			"tags": []
+		}`),
 	}
 
-	tagReaderWriter.On("FindByNames", testCtx, []string{missingTagName}, false).Return(nil, nil).Times(3)
-	tagReaderWriter.On("Create", testCtx, mock.AnythingOfType("models.Tag")).Return(&models.Tag{
-		ID: existingTagID,
-	}, nil)
-
 	err := i.PreImport(testCtx)
-	assert.NotNil(t, err)
+	assert.Nil(t, err)
 
-	i.MissingRefBehaviour = models.ImportMissingRefEnumIgnore
-	err = i.PreImport(testCtx)
+	// omitted fields are left unchanged, explicitly empty fields are cleared
+	readerWriter.On("UpdatePartial", testCtx, performerID, mock.MatchedBy(func(p models.PerformerPartial) bool {
+		return p.Name.Set && p.Name.Value == performerName &&
+			p.Details.Set && p.Details.Value == details &&
+			p.Country.Set && p.Country.Value == "" &&
+			p.Birthdate.Set && p.Birthdate.Null &&
+			!p.Aliases.Set && !p.Rating.Set && !p.Favorite.Set && !p.Tattoos.Set && p.URLs == nil
+	})).Return(nil, nil).Once()
+	readerWriter.On("UpdateTags", testCtx, performerID, []int(nil)).Return(nil).Once()
+
+	err = i.Update(testCtx, performerID)
 	assert.Nil(t, err)
 
-	i.MissingRefBehaviour = models.ImportMissingRefEnumCreate
-	err = i.PreImport(testCtx)
+	err = i.PostImport(testCtx, performerID)
 	assert.Nil(t, err)
-	assert.Equal(t, existingTagID, i.tags[0].ID)
 
-	tagReaderWriter.AssertExpectations(t)
+	readerWriter.AssertExpectations(t)
 }
 
-func TestImporterPreImportWithMissingTagCreateErr(t *testing.T) {
-	tagReaderWriter := &mocks.TagReaderWriter{}
+func TestImporterUpdatePartialNotDecoded(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
 
+	// fields of inputs that were not decoded are present if not empty
 	i := Importer{
-		TagWriter: tagReaderWriter,
+		ReaderWriter:  readerWriter,
+		MergeStrategy: MergeStrategyPartial,
 		Input: jsonschema.Performer{
-			Tags: []string{
-				missingTagName,
-			},
+			Name:    performerName,
+			Details: details,
 		},
-		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
 	}
 
-	tagReaderWriter.On("FindByNames", testCtx, []string{missingTagName}, false).Return(nil, nil).Once()
-	tagReaderWriter.On("Create", testCtx, mock.AnythingOfType("models.Tag")).Return(nil, errors.New("Create error"))
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	readerWriter.On("UpdatePartial", testCtx, performerID, mock.MatchedBy(func(p models.PerformerPartial) bool {
+		return p.Name.Set && p.Details.Set && p.Details.Value == details &&
+			!p.Country.Set && !p.Birthdate.Set && !p.Aliases.Set
+	})).Return(nil, nil).Once()
+
+	err = i.Update(testCtx, performerID)
+	assert.Nil(t, err)
+
+	// omitted tags are left unchanged
+	err = i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportNormalizeBodyModifications(t *testing.T) {
+	i := Importer{
+		Input: jsonschema.Performer{
+			Name:      performerName,
+			Tattoos:   "None",
+			Piercings: " Nose,  ears ",
+		},
+	}
 
 	err := i.PreImport(testCtx)
-	assert.NotNil(t, err)
+	assert.Nil(t, err)
+	assert.Equal(t, "None", i.performer.Tattoos)
+	assert.Equal(t, " Nose,  ears ", i.performer.Piercings)
+
+	i.NormalizeBodyModifications = true
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, "", i.performer.Tattoos)
+	assert.Equal(t, "Nose, ears", i.performer.Piercings)
 }
 
-func TestImporterPostImport(t *testing.T) {
-	readerWriter := &mocks.PerformerReaderWriter{}
+func TestImporterPreImportEnrich(t *testing.T) {
+	enrichErr := errors.New("enrich error")
 
 	i := Importer{
-		ReaderWriter: readerWriter,
-		imageData:    imageBytes,
+		Input: jsonschema.Performer{
+			Name: performerName,
+		},
+		Enrich: func(ctx context.Context, p *models.Performer) error {
+			p.Birthdate = &birthDate
+			return nil
+		},
 	}
 
-	updatePerformerImageErr := errors.New("UpdateImage error")
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, &birthDate, i.performer.Birthdate)
 
-	readerWriter.On("UpdateImage", testCtx, performerID, imageBytes).Return(nil).Once()
-	readerWriter.On("UpdateImage", testCtx, errImageID, imageBytes).Return(updatePerformerImageErr).Once()
+	i.Enrich = func(ctx context.Context, p *models.Performer) error {
+		return enrichErr
+	}
 
-	err := i.PostImport(testCtx, performerID)
+	err = i.PreImport(testCtx)
 	assert.Nil(t, err)
+	assert.Len(t, i.Warnings(), 1)
 
-	err = i.PostImport(testCtx, errImageID)
+	i.EnrichFatal = true
+	err = i.PreImport(testCtx)
 	assert.NotNil(t, err)
+}
 
-	readerWriter.AssertExpectations(t)
+func TestImporterPreImportDefaultGender(t *testing.T) {
+	tests := []struct {
+		name          string
+		gender        string
+		behaviour     InvalidGenderBehaviour
+		defaultGender models.GenderEnum
+		want          models.GenderEnum
+		wantWarning   bool
+	}{
+		{"valid", string(models.GenderEnumMale), "", models.GenderEnumFemale, models.GenderEnumMale, false},
+		{"empty", "", "", models.GenderEnumFemale, models.GenderEnumFemale, false},
+		{"invalid", "invalid", "", models.GenderEnumFemale, models.GenderEnumFemale, true},
+		{"invalid ignore", "invalid", InvalidGenderIgnore, models.GenderEnumFemale, models.GenderEnumFemale, true},
+		{"empty no default", "", "", "", "", false},
+		{"empty fail", "", InvalidGenderFail, models.GenderEnumFemale, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				InvalidGenderBehaviour: tt.behaviour,
+				DefaultGender:          tt.defaultGender,
+				Input: jsonschema.Performer{
+					Name:   performerName,
+					Gender: tt.gender,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, i.performer.Gender)
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+		})
+	}
 }
 
-func TestImporterFindExistingID(t *testing.T) {
+func TestImporterUpdateDefaultGender(t *testing.T) {
+	tests := []struct {
+		name           string
+		mergeStrategy  MergeStrategy
+		existingGender models.GenderEnum
+		want           models.GenderEnum
+	}{
+		{"replace keeps existing", "", models.GenderEnumMale, models.GenderEnumMale},
+		{"merge keeps existing", MergeStrategyMerge, models.GenderEnumMale, models.GenderEnumMale},
+		{"replace sets default", "", "", models.GenderEnumFemale},
+		{"merge sets default", MergeStrategyMerge, "", models.GenderEnumFemale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+
+			existing := models.Performer{
+				ID:     performerID,
+				Name:   performerName,
+				Gender: tt.existingGender,
+			}
+
+			i := Importer{
+				ReaderWriter:  readerWriter,
+				MergeStrategy: tt.mergeStrategy,
+				DefaultGender: models.GenderEnumFemale,
+				Input: jsonschema.Performer{
+					Name: performerName,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+
+			readerWriter.On("Find", testCtx, performerID).Return(&existing, nil).Once()
+			readerWriter.On("Update", testCtx, mock.MatchedBy(func(p *models.Performer) bool {
+				return p.Gender == tt.want
+			})).Return(nil).Once()
+
+			err = i.Update(testCtx, performerID)
+			assert.Nil(t, err)
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImporterPreImportInvalidGender(t *testing.T) {
+	tests := []struct {
+		name        string
+		gender      string
+		behaviour   InvalidGenderBehaviour
+		want        models.GenderEnum
+		wantErr     bool
+		wantWarning bool
+	}{
+		{"valid", string(models.GenderEnumMale), InvalidGenderFail, models.GenderEnumMale, false, false},
+		{"synonym", "woman", InvalidGenderFail, models.GenderEnumFemale, false, false},
+		{"garbage unset", "Femal", "", "", false, true},
+		{"garbage fail", "Femal", InvalidGenderFail, "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				InvalidGenderBehaviour: tt.behaviour,
+				Input: jsonschema.Performer{
+					Name:   performerName,
+					Gender: tt.gender,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			if tt.wantErr {
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), tt.gender)
+				}
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, i.performer.Gender)
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+		})
+	}
+}
+
+func TestImporterPreImportDates(t *testing.T) {
+	tests := []struct {
+		name          string
+		birthdate     string
+		deathDate     string
+		strict        bool
+		wantBirthdate string
+		wantDeathDate string
+		wantErr       bool
+		wantWarning   bool
+	}{
+		{"iso", "1990-05-01", "2020-01-02", false, "1990-05-01", "2020-01-02", false, false},
+		{"slashed", "1990/05/01", "", false, "1990-05-01", "", false, false},
+		{"partial", "1990", "2020-01", false, "1990-01-01", "2020-01-01", false, false},
+		{"unparseable", "01/05/1990", "someday", false, "", "", false, true},
+		{"unparseable strict", "1990-05-01", "someday", true, "", "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				StrictDates: tt.strict,
+				Input: jsonschema.Performer{
+					Name:      performerName,
+					Birthdate: tt.birthdate,
+					DeathDate: tt.deathDate,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+
+			dateString := func(d *models.Date) string {
+				if d == nil {
+					return ""
+				}
+				return d.String()
+			}
+			assert.Equal(t, tt.wantBirthdate, dateString(i.performer.Birthdate))
+			assert.Equal(t, tt.wantDeathDate, dateString(i.performer.DeathDate))
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+		})
+	}
+}
+
+func TestImporterPreImportSortNameLocale(t *testing.T) {
+	tests := []struct {
+		name    string
+		locale  string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"canonicalized", "sv-se", "sv-SE", false},
+		{"invalid", "not a locale", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				Input: jsonschema.Performer{
+					Name:           performerName,
+					SortName:       sortName,
+					SortNameLocale: tt.locale,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Importer.PreImport() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil {
+				assert.Equal(t, sortName, i.performer.SortName)
+				assert.Equal(t, tt.want, i.performer.SortNameLocale)
+			}
+		})
+	}
+}
+
+func TestImporterPreImportStashIDName(t *testing.T) {
+	const canonicalName = "canonicalName"
+	errFetch := errors.New("fetch error")
+
+	tests := []struct {
+		name        string
+		behaviour   StashIDNameBehaviour
+		aliases     string
+		fetchName   string
+		fetchErr    error
+		wantName    string
+		wantAliases string
+		wantWarning bool
+	}{
+		{"same name", StashIDNameAdopt, "", performerName, nil, performerName, "", false},
+		{"no name", StashIDNameAdopt, "", "", nil, performerName, "", false},
+		{"warn", "", "", canonicalName, nil, performerName, "", true},
+		{"adopt", StashIDNameAdopt, "", canonicalName, nil, canonicalName, performerName, true},
+		{"adopt with aliases", StashIDNameAdopt, "alias", canonicalName, nil, canonicalName, "alias, " + performerName, true},
+		{"adopt existing alias", StashIDNameAdopt, "alias, " + performerName, canonicalName, nil, canonicalName, "alias, " + performerName, true},
+		{"fetch error", StashIDNameAdopt, "", "", errFetch, performerName, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				StashIDNameFetcher: func(ctx context.Context, stashID models.StashID) (string, error) {
+					return tt.fetchName, tt.fetchErr
+				},
+				StashIDNameBehaviour: tt.behaviour,
+				Input: jsonschema.Performer{
+					Name:     performerName,
+					Aliases:  tt.aliases,
+					StashIDs: []models.StashID{stashID},
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantName, i.performer.Name)
+			assert.Equal(t, tt.wantName, i.Name())
+			assert.Equal(t, md5.FromString(tt.wantName), i.performer.Checksum)
+			assert.Equal(t, tt.wantAliases, i.performer.Aliases)
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+		})
+	}
+}
+
+func TestImporterFindExistingIDStashIDName(t *testing.T) {
+	const canonicalName = "canonicalName"
 	readerWriter := &mocks.PerformerReaderWriter{}
 
 	i := Importer{
 		ReaderWriter: readerWriter,
+		StashIDNameFetcher: func(ctx context.Context, stashID models.StashID) (string, error) {
+			return canonicalName, nil
+		},
+		StashIDNameBehaviour: StashIDNameAdopt,
 		Input: jsonschema.Performer{
-			Name: performerName,
+			Name:     performerName,
+			StashIDs: []models.StashID{stashID},
 		},
 	}
 
-	errFindByNames := errors.New("FindByNames error")
-	readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return(nil, nil).Once()
-	readerWriter.On("FindByNames", testCtx, []string{existingPerformerName}, false).Return([]*models.Performer{
+	readerWriter.On("FindByStashID", testCtx, stashID).Return(nil, nil).Twice()
+
+	names := []string{canonicalName, performerName}
+	readerWriter.On("FindByNames", testCtx, names, false).Return([]*models.Performer{
 		{
-			ID: existingPerformerID,
+			ID:   existingPerformerID,
+			Name: performerName,
+		},
+	}, nil).Once()
+	readerWriter.On("FindByNames", testCtx, names, false).Return([]*models.Performer{
+		{
+			ID:   existingPerformerID,
+			Name: performerName,
+		},
+		{
+			ID:   performerID,
+			Name: canonicalName,
 		},
 	}, nil).Once()
-	readerWriter.On("FindByNames", testCtx, []string{performerNameErr}, false).Return(nil, errFindByNames).Once()
 
-	id, err := i.FindExistingID(testCtx)
-	assert.Nil(t, id)
+	err := i.PreImport(testCtx)
 	assert.Nil(t, err)
 
-	i.Input.Name = existingPerformerName
-	id, err = i.FindExistingID(testCtx)
-	assert.Equal(t, existingPerformerID, *id)
+	id, err := i.FindExistingID(testCtx)
 	assert.Nil(t, err)
+	assert.Equal(t, existingPerformerID, *id)
 
-	i.Input.Name = performerNameErr
 	id, err = i.FindExistingID(testCtx)
-	assert.Nil(t, id)
-	assert.NotNil(t, err)
+	assert.Nil(t, err)
+	assert.Equal(t, performerID, *id)
 
 	readerWriter.AssertExpectations(t)
 }
 
-func TestImporterPostImportUpdateTags(t *testing.T) {
+func TestImporterPreImportImageErrors(t *testing.T) {
+	const (
+		jpeg         = "/9j/2wCEAAgGBgcGBQgHBwcJCQgKDBQNDAsLDBkSEw8UHRofHh0aHBwgJC4nICIsIxwcKDcpLDAxNDQ0Hyc5PTgyPC4zNDIBCQkJDAsMGA0NGDIhHCEyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMjIyMv/AAAsIAAEAAQEBEQD/xADSAAABBQEBAQEBAQAAAAAAAAAAAQIDBAUGBwgJCgsQAAIBAwMCBAMFBQQEAAABfQECAwAEEQUSITFBBhNRYQcicRQygZGhCCNCscEVUtHwJDNicoIJChYXGBkaJSYnKCkqNDU2Nzg5OkNERUZHSElKU1RVVldYWVpjZGVmZ2hpanN0dXZ3eHl6g4SFhoeIiYqSk5SVlpeYmZqio6Slpqeoqaqys7S1tre4ubrCw8TFxsfIycrS09TV1tfY2drh4uPk5ebn6Onq8fLz9PX29/j5+v/aAAgBAQAAPwD5/r//2Q=="
+		gif          = "R0lGODlhAQABAIAAAAAAAP///ywAAAAAAQABAAACAkQBADs="
+		animatedGIF  = "R0lGODlhAQABAAAAACH/C05FVFNDQVBFMi4wAwEAAAAsAAAAAAEAAQCAAAAA////AgJEAQAsAAAAAAEAAQCAAAAA////AgJEAQA7"
+		truncatedPNG = "iVBORw0KGgowMDAw"
+	)
+	text := utils.GetBase64StringFromData([]byte("not an image"))
+	oversize := utils.GetBase64StringFromData(append(imageBytes, make([]byte, DefaultImageMaxSize)...))
+
+	tests := []struct {
+		name    string
+		image   string
+		limits  ImageLimits
+		queue   bool
+		wantErr error
+	}{
+		{"decode", invalidImage, ImageLimits{}, false, ErrImageDecode},
+		{"valid png", image, ImageLimits{}, false, nil},
+		{"valid jpeg", jpeg, ImageLimits{}, false, nil},
+		{"too large", image, ImageLimits{MaxSize: 5}, false, ErrImageTooLarge},
+		{"too large default", oversize, ImageLimits{}, false, ErrImageTooLarge},
+		{"no limit", oversize, ImageLimits{MaxSize: -1}, false, nil},
+		{"too large deferred", image, ImageLimits{MaxSize: 5}, true, ErrImageTooLarge},
+		{"too large data uri", "data:image/png;base64," + image, ImageLimits{MaxSize: 5}, false, ErrImageTooLarge},
+		{"exact size", image, ImageLimits{MaxSize: len(imageBytes)}, false, nil},
+		{"not an image", text, ImageLimits{}, false, ErrImageUnsupportedFormat},
+		{"invalid header", truncatedPNG, ImageLimits{}, false, ErrImageDecode},
+		{"unsupported format", gif, ImageLimits{}, false, ErrImageUnsupportedFormat},
+		{"unsupported configured format", jpeg, ImageLimits{Formats: []string{"image/png"}}, false, ErrImageUnsupportedFormat},
+		{"supported format", gif, ImageLimits{Formats: []string{"image/gif"}}, false, nil},
+		{"animated", animatedGIF, ImageLimits{Formats: []string{"image/gif"}}, false, ErrImageAnimated},
+		{"animated allowed", animatedGIF, ImageLimits{Formats: []string{"image/gif"}, AllowAnimated: true}, false, nil},
+		{"valid deferred", image, ImageLimits{}, true, nil},
+		{"decode deferred", invalidImage, ImageLimits{}, true, ErrImageDecode},
+		{"not an image deferred", text, ImageLimits{}, true, ErrImageUnsupportedFormat},
+		{"unsupported format deferred", gif, ImageLimits{}, true, ErrImageUnsupportedFormat},
+		{"animated deferred", animatedGIF, ImageLimits{Formats: []string{"image/gif"}}, true, ErrImageAnimated},
+		// the header is only decoded in full when the image is written
+		{"invalid header deferred", truncatedPNG, ImageLimits{}, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				ImageLimits: tt.limits,
+				Input: jsonschema.Performer{
+					Name:  performerName,
+					Image: tt.image,
+				},
+			}
+
+			if tt.queue {
+				i.ImageQueue = NewImageQueue(testCtx, &mocks.TxnManager{}, &mocks.PerformerReaderWriter{})
+				defer i.ImageQueue.Wait()
+			}
+
+			err := i.PreImport(testCtx)
+			if tt.wantErr == nil {
+				assert.Nil(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImporterPreImportImageFetch(t *testing.T) {
+	pngData := imageBytes
+	textData := []byte("not an image")
+
+	fsys := fstest.MapFS{
+		"image.png": &fstest.MapFile{Data: pngData},
+		"image.txt": &fstest.MapFile{Data: textData},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(pngData)
+	})
+	mux.HandleFunc("/image.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write(pngData)
+	})
+	mux.HandleFunc("/slow.png", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := &ImageFetcher{
+		FS:      fsys,
+		Timeout: 50 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name     string
+		input    jsonschema.Performer
+		fetcher  *ImageFetcher
+		limits   ImageLimits
+		wantData []byte
+		wantErr  error
+	}{
+		{"path", jsonschema.Performer{ImagePath: "image.png"}, fetcher, ImageLimits{}, pngData, nil},
+		{"path too large", jsonschema.Performer{ImagePath: "image.png"}, fetcher, ImageLimits{MaxSize: 5}, nil, ErrImageTooLarge},
+		{"path not image", jsonschema.Performer{ImagePath: "image.txt"}, fetcher, ImageLimits{}, nil, ErrImageUnsupportedFormat},
+		{"path missing", jsonschema.Performer{ImagePath: "missing.png"}, fetcher, ImageLimits{}, nil, ErrImageFetch},
+		{"path without fs", jsonschema.Performer{ImagePath: "image.png"}, &ImageFetcher{}, ImageLimits{}, nil, ErrImageFetch},
+		{"url", jsonschema.Performer{ImageURL: server.URL + "/image.png"}, fetcher, ImageLimits{}, pngData, nil},
+		{"url too large", jsonschema.Performer{ImageURL: server.URL + "/image.png"}, fetcher, ImageLimits{MaxSize: 5}, nil, ErrImageTooLarge},
+		{"url not image", jsonschema.Performer{ImageURL: server.URL + "/image.html"}, fetcher, ImageLimits{}, nil, ErrImageUnsupportedFormat},
+		{"url timeout", jsonschema.Performer{ImageURL: server.URL + "/slow.png"}, fetcher, ImageLimits{}, nil, ErrImageFetch},
+		{"url invalid scheme", jsonschema.Performer{ImageURL: "file:///image.png"}, fetcher, ImageLimits{}, nil, ErrImageFetch},
+		{"not enabled", jsonschema.Performer{ImageURL: server.URL + "/image.png"}, nil, ImageLimits{}, nil, ErrImageFetch},
+		{"base64 preferred", jsonschema.Performer{Image: image, ImageURL: server.URL + "/image.html"}, fetcher, ImageLimits{}, imageBytes, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input
+			input.Name = performerName
+
+			i := Importer{
+				ImageFetcher: tt.fetcher,
+				ImageLimits:  tt.limits,
+				Input:        input,
+			}
+
+			err := i.PreImport(testCtx)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantData, i.imageData)
+		})
+	}
+}
+
+func TestImporterPostImportFetchedImage(t *testing.T) {
 	readerWriter := &mocks.PerformerReaderWriter{}
+	pngData := imageBytes
 
 	i := Importer{
 		ReaderWriter: readerWriter,
-		tags: []*models.Tag{
-			{
-				ID: existingTagID,
+		ImageFetcher: &ImageFetcher{
+			FS: fstest.MapFS{
+				"image.png": &fstest.MapFile{Data: pngData},
 			},
 		},
+		Input: jsonschema.Performer{
+			Name:      performerName,
+			ImagePath: "image.png",
+		},
 	}
 
-	updateErr := errors.New("UpdateTags error")
-
-	readerWriter.On("UpdateTags", testCtx, performerID, []int{existingTagID}).Return(nil).Once()
-	readerWriter.On("UpdateTags", testCtx, errTagsID, mock.AnythingOfType("[]int")).Return(updateErr).Once()
+	readerWriter.On("UpdateImage", testCtx, performerID, pngData).Return(nil).Once()
 
-	err := i.PostImport(testCtx, performerID)
+	err := i.PreImport(testCtx)
 	assert.Nil(t, err)
 
-	err = i.PostImport(testCtx, errTagsID)
-	assert.NotNil(t, err)
+	err = i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
 
 	readerWriter.AssertExpectations(t)
 }
 
-func TestCreate(t *testing.T) {
-	readerWriter := &mocks.PerformerReaderWriter{}
-
-	performer := models.Performer{
-		Name: performerName,
+func TestImporterPreImportImageMIMEMismatch(t *testing.T) {
+	png := image
+
+	tests := []struct {
+		name                string
+		image               string
+		strict              bool
+		missingRefBehaviour models.ImportMissingRefEnum
+		wantErr             bool
+		wantWarning         bool
+	}{
+		{"not strict", "data:image/jpeg;base64," + png, false, models.ImportMissingRefEnumFail, false, false},
+		{"match", "data:image/png;base64," + png, true, models.ImportMissingRefEnumFail, false, false},
+		{"undeclared", png, true, models.ImportMissingRefEnumFail, false, false},
+		{"mismatch fail", "data:image/jpeg;base64," + png, true, models.ImportMissingRefEnumFail, true, false},
+		{"mismatch ignore", "data:image/jpeg;base64," + png, true, models.ImportMissingRefEnumIgnore, false, true},
 	}
 
-	performerErr := models.Performer{
-		Name: performerNameErr,
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				MissingRefBehaviour: tt.missingRefBehaviour,
+				ImageLimits: ImageLimits{
+					StrictMIME: tt.strict,
+				},
+				Input: jsonschema.Performer{
+					Name:  performerName,
+					Image: tt.image,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrImageMIMEMismatch)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.NotEmpty(t, i.imageData)
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+		})
 	}
+}
 
-	i := Importer{
-		ReaderWriter: readerWriter,
-		performer:    performer,
+func TestImporterPreImportPrimaryImage(t *testing.T) {
+	const (
+		firstImage  = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAAAAAA6fptVAAAAD0lEQVR4nAACAP3/Av8DAAEFAQJnLD7dAAAAAElFTkSuQmCC"
+		secondImage = "R0lGODlhAQABAIAAAAAAAP///ywAAAAAAQABAAACAkQBADs="
+	)
+
+	tests := []struct {
+		name         string
+		images       []string
+		primaryImage int
+		want         string
+		wantWarning  bool
+	}{
+		{"no images", nil, 0, image, false},
+		{"first", []string{firstImage, secondImage}, 0, firstImage, false},
+		{"second", []string{firstImage, secondImage}, 1, secondImage, false},
+		{"out of range", []string{firstImage, secondImage}, 2, firstImage, true},
+		{"negative", []string{firstImage, secondImage}, -1, firstImage, true},
 	}
 
-	errCreate := errors.New("Create error")
-	readerWriter.On("Create", testCtx, &performer).Run(func(args mock.Arguments) {
-		arg := args.Get(1).(*models.Performer)
-		arg.ID = performerID
-	}).Return(nil).Once()
-	readerWriter.On("Create", testCtx, &performerErr).Return(errCreate).Once()
-
-	id, err := i.Create(testCtx)
-	assert.Equal(t, performerID, *id)
-	assert.Nil(t, err)
-
-	i.performer = performerErr
-	id, err = i.Create(testCtx)
-	assert.Nil(t, id)
-	assert.NotNil(t, err)
-
-	readerWriter.AssertExpectations(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				ImageLimits: ImageLimits{
+					Formats: []string{"image/png", "image/gif"},
+				},
+				Input: jsonschema.Performer{
+					Name:         performerName,
+					Image:        image,
+					Images:       tt.images,
+					PrimaryImage: tt.primaryImage,
+				},
+			}
+
+			want, _ := base64.StdEncoding.DecodeString(tt.want)
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, want, i.imageData)
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+		})
+	}
 }
 
-func TestUpdate(t *testing.T) {
-	readerWriter := &mocks.PerformerReaderWriter{}
+func TestImporterPreImportAliases(t *testing.T) {
+	const aliases = "Alias, alias,  Other  Alias ," + performerName
 
-	performer := models.Performer{
-		Name: performerName,
+	tests := []struct {
+		name string
+		raw  bool
+		want string
+	}{
+		{"normalized", false, "Alias, Other Alias"},
+		{"raw", true, aliases},
 	}
 
-	performerErr := models.Performer{
-		Name: performerNameErr,
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				RawAliases: tt.raw,
+				Input: jsonschema.Performer{
+					Name:    performerName,
+					Aliases: aliases,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, i.performer.Aliases)
+		})
 	}
+}
 
+func TestImporterPreImportMeasurements(t *testing.T) {
 	i := Importer{
-		ReaderWriter: readerWriter,
-		performer:    performer,
+		Input: jsonschema.Performer{
+			Name:             performerName,
+			Measurements:     "86C-61-86",
+			MeasurementUnits: string(MeasurementUnitsMetric),
+		},
 	}
 
-	errUpdate := errors.New("Update error")
-
-	// id needs to be set for the mock input
-	performer.ID = performerID
-	readerWriter.On("Update", testCtx, &performer).Return(nil).Once()
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, "86C-61-86", i.performer.Measurements)
+	assert.Len(t, i.Warnings(), 0)
 
-	err := i.Update(testCtx, performerID)
+	i.Input.Measurements = "invalid"
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, "invalid", i.performer.Measurements)
+	assert.Len(t, i.Warnings(), 1)
+
+	i.Input.MeasurementUnits = "invalid"
+	err = i.PreImport(testCtx)
+	assert.NotNil(t, err)
+}
+
+func TestImporterMode(t *testing.T) {
+	input := jsonschema.Performer{
+		Name: performerName,
+		CreatedAt: json.JSONTime{
+			Time: createTime,
+		},
+		UpdatedAt: json.JSONTime{
+			Time: updateTime,
+		},
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		readerWriter := &mocks.PerformerReaderWriter{}
+		i := Importer{
+			ReaderWriter: readerWriter,
+			Input:        input,
+		}
+
+		err := i.PreImport(testCtx)
+		assert.Nil(t, err)
+		// timestamps are taken from the input
+		assert.Equal(t, createTime, i.performer.CreatedAt)
+		assert.Equal(t, updateTime, i.performer.UpdatedAt)
+
+		readerWriter.On("Update", testCtx, mock.MatchedBy(func(p *models.Performer) bool {
+			return p.CreatedAt.Equal(createTime) && p.UpdatedAt.Equal(updateTime)
+		})).Return(nil).Once()
+
+		err = i.Update(testCtx, performerID)
+		assert.Nil(t, err)
+
+		readerWriter.AssertExpectations(t)
+	})
+
+	t.Run("sync", func(t *testing.T) {
+		readerWriter := &mocks.PerformerReaderWriter{}
+		i := Importer{
+			ReaderWriter: readerWriter,
+			Input:        input,
+			Mode:         ImportModeSync,
+		}
+
+		before := time.Now()
+		err := i.PreImport(testCtx)
+		assert.Nil(t, err)
+		// created time is taken from the input for new performers, and
+		// updated time is set to now
+		assert.Equal(t, createTime, i.performer.CreatedAt)
+		assert.False(t, i.performer.UpdatedAt.Before(before))
+
+		// existing performers keep their created time
+		readerWriter.On("UpdatePartial", testCtx, performerID, mock.MatchedBy(func(p models.PerformerPartial) bool {
+			return !p.CreatedAt.Set && p.UpdatedAt.Set && !p.UpdatedAt.Value.Before(before)
+		})).Return(nil, nil).Once()
+
+		err = i.Update(testCtx, performerID)
+		assert.Nil(t, err)
+
+		readerWriter.AssertExpectations(t)
+	})
+}
+
+func TestImporterPreImportWithTag(t *testing.T) {
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		TagWriter:           tagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+		Input: jsonschema.Performer{
+			Tags: []string{
+				existingTagName,
+			},
+		},
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagName}, false).Return([]*models.Tag{
+		{
+			ID:   existingTagID,
+			Name: existingTagName,
+		},
+	}, nil).Once()
+	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagErr}, false).Return(nil, errors.New("FindByNames error")).Once()
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, existingTagID, i.tags[0].ID)
+
+	i.Input.Tags = []string{existingTagErr}
+	err = i.PreImport(testCtx)
+	assert.NotNil(t, err)
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportWithMissingTag(t *testing.T) {
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		TagWriter: tagReaderWriter,
+		Input: jsonschema.Performer{
+			Tags: []string{
+				missingTagName,
+			},
+		},
+		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{missingTagName}, false).Return(nil, nil).Times(3)
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+	tagReaderWriter.On("Create", testCtx, mock.AnythingOfType("models.Tag")).Return(&models.Tag{
+		ID: existingTagID,
+	}, nil)
+
+	err := i.PreImport(testCtx)
+	assert.NotNil(t, err)
+
+	i.MissingRefBehaviour = models.ImportMissingRefEnumIgnore
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	i.MissingRefBehaviour = models.ImportMissingRefEnumCreate
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, existingTagID, i.tags[0].ID)
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportWithDuplicateTags(t *testing.T) {
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	const (
+		existingName = "Blonde"
+		missingName  = "Brunette"
+		otherTagID   = 107
+	)
+
+	i := Importer{
+		TagWriter: tagReaderWriter,
+		Input: jsonschema.Performer{
+			Tags: []string{
+				existingName,
+				" blonde ",
+				"BLONDE",
+				missingName + "\t",
+				"brunette",
+				" ",
+			},
+		},
+		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{existingName, missingName}, false).Return([]*models.Tag{
+		{ID: existingTagID, Name: existingName},
+	}, nil).Once()
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+	tagReaderWriter.On("Create", testCtx, mock.MatchedBy(func(newTag models.Tag) bool {
+		return newTag.Name == missingName
+	})).Return(&models.Tag{
+		ID:   otherTagID,
+		Name: missingName,
+	}, nil).Once()
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	var ids []int
+	for _, t := range i.tags {
+		ids = append(ids, t.ID)
+	}
+	assert.Equal(t, []int{existingTagID, otherTagID}, ids)
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPostImportDuplicateTags(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	const otherTagID = 107
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		tags: []*models.Tag{
+			{ID: existingTagID},
+			{ID: otherTagID},
+			{ID: existingTagID},
+		},
+	}
+
+	readerWriter.On("UpdateTags", testCtx, performerID, []int{existingTagID, otherTagID}).Return(nil).Once()
+
+	err := i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+type manyCreatorTagReaderWriter struct {
+	*mocks.TagReaderWriter
+}
+
+func (rw manyCreatorTagReaderWriter) CreateMany(ctx context.Context, newTags []models.Tag) ([]*models.Tag, error) {
+	ret := rw.Called(ctx, newTags)
+	if ret.Get(0) == nil {
+		return nil, ret.Error(1)
+	}
+	return ret.Get(0).([]*models.Tag), ret.Error(1)
+}
+
+func TestImporterPreImportWithMissingTagCreateMany(t *testing.T) {
+	tagReaderWriter := manyCreatorTagReaderWriter{&mocks.TagReaderWriter{}}
+
+	const otherMissingTagName = "otherMissingTagName"
+	names := []string{missingTagName, otherMissingTagName}
+
+	i := Importer{
+		TagWriter: tagReaderWriter,
+		Input: jsonschema.Performer{
+			Tags: names,
+		},
+		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, names, false).Return(nil, nil).Times(3)
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+	tagReaderWriter.On("CreateMany", testCtx, mock.MatchedBy(func(newTags []models.Tag) bool {
+		return len(newTags) == 2 && newTags[0].Name == missingTagName && newTags[1].Name == otherMissingTagName
+	})).Return([]*models.Tag{
+		{ID: existingTagID, Name: missingTagName},
+		{ID: existingTagID + 1, Name: otherMissingTagName},
+	}, nil).Once()
+
+	err := i.PreImport(testCtx)
+	assert.NotNil(t, err)
+
+	i.MissingRefBehaviour = models.ImportMissingRefEnumIgnore
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Len(t, i.tags, 0)
+
+	i.MissingRefBehaviour = models.ImportMissingRefEnumCreate
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Len(t, i.tags, 2)
+
+	tagReaderWriter.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	tagReaderWriter.AssertExpectations(t)
+}
+
+// countingTagWriter counts the calls made to the tag writer. Methods not
+// used when creating tags are not implemented.
+type countingTagWriter struct {
+	TagFinderCreatorUpdater
+	calls  int
+	nextID int
+}
+
+func (w *countingTagWriter) FindByNames(ctx context.Context, names []string, nocase bool) ([]*models.Tag, error) {
+	w.calls++
+	return nil, nil
+}
+
+func (w *countingTagWriter) Query(ctx context.Context, tagFilter *models.TagFilterType, findFilter *models.FindFilterType) ([]*models.Tag, int, error) {
+	w.calls++
+	return nil, 0, nil
+}
+
+func (w *countingTagWriter) Create(ctx context.Context, newTag models.Tag) (*models.Tag, error) {
+	w.calls++
+	w.nextID++
+	newTag.ID = w.nextID
+	return &newTag, nil
+}
+
+type countingManyCreatorTagWriter struct {
+	*countingTagWriter
+}
+
+func (w countingManyCreatorTagWriter) CreateMany(ctx context.Context, newTags []models.Tag) ([]*models.Tag, error) {
+	w.calls++
+	var ret []*models.Tag
+	for _, t := range newTags {
+		created := t
+		w.nextID++
+		created.ID = w.nextID
+		ret = append(ret, &created)
+	}
+	return ret, nil
+}
+
+// countingBatchTagWriter is a countingManyCreatorTagWriter that also finds
+// tags by alias in a single call.
+type countingBatchTagWriter struct {
+	countingManyCreatorTagWriter
+}
+
+func (w countingBatchTagWriter) FindByAliases(ctx context.Context, aliases []string) (map[string]*models.Tag, error) {
+	w.calls++
+	return nil, nil
+}
+
+func missingTagNames(n int) []string {
+	var ret []string
+	for i := 0; i < n; i++ {
+		ret = append(ret, fmt.Sprintf("tag%d", i))
+	}
+	return ret
+}
+
+func TestImporterCreateMissingTagsCalls(t *testing.T) {
+	for _, n := range []int{1, 50} {
+		w := &countingTagWriter{}
+		i := Importer{
+			TagWriter:           countingBatchTagWriter{countingManyCreatorTagWriter{w}},
+			MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+			Input: jsonschema.Performer{
+				Name: performerName,
+				Tags: missingTagNames(n),
+			},
+		}
+
+		err := i.PreImport(testCtx)
+		assert.NoError(t, err)
+		assert.Len(t, i.tags, n)
+
+		// FindByNames, FindByAliases and CreateMany
+		assert.Equal(t, 3, w.calls, "calls for %d tags", n)
+	}
+}
+
+func TestImporterBatchTags(t *testing.T) {
+	w := &countingTagWriter{}
+	tagWriter := countingBatchTagWriter{countingManyCreatorTagWriter{w}}
+	batch := &Batch{}
+
+	newImporter := func(tags []string) *Importer {
+		return &Importer{
+			TagWriter:           tagWriter,
+			MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+			Batch:               batch,
+			Input: jsonschema.Performer{
+				Name: performerName,
+				Tags: tags,
+			},
+		}
+	}
+
+	i := newImporter([]string{"tag1", "tag2"})
+	assert.NoError(t, i.PreImport(testCtx))
+	assert.Equal(t, 3, w.calls)
+
+	// tags created earlier in the session are not looked up or created again
+	i = newImporter([]string{"tag2", "tag1"})
+	assert.NoError(t, i.PreImport(testCtx))
+	assert.Equal(t, 3, w.calls)
+	assert.Equal(t, 2, w.nextID)
+	assert.Equal(t, []string{"tag2", "tag1"}, []string{i.tags[0].Name, i.tags[1].Name})
+
+	// tags created by a rolled back import are forgotten
+	i = newImporter([]string{"tag3"})
+	assert.NoError(t, i.PreImport(testCtx))
+	i.release()
+
+	i = newImporter([]string{"tag3"})
+	assert.NoError(t, i.PreImport(testCtx))
+	assert.Equal(t, 4, w.nextID)
+}
+
+func BenchmarkImporterCreateMissingTags(b *testing.B) {
+	benchmarks := []struct {
+		name      string
+		tagWriter func(w *countingTagWriter) TagFinderCreatorUpdater
+		// maxCalls is the expected maximum number of calls per import,
+		// regardless of the number of tags, or 0 if not constant
+		maxCalls int
+	}{
+		{"Create", func(w *countingTagWriter) TagFinderCreatorUpdater {
+			return w
+		}, 0},
+		{"CreateMany", func(w *countingTagWriter) TagFinderCreatorUpdater {
+			return countingManyCreatorTagWriter{w}
+		}, 0},
+		{"FindByAliases", func(w *countingTagWriter) TagFinderCreatorUpdater {
+			return countingBatchTagWriter{countingManyCreatorTagWriter{w}}
+		}, 3},
+	}
+
+	for _, bm := range benchmarks {
+		for _, n := range []int{1, 50} {
+			b.Run(fmt.Sprintf("%s/%d", bm.name, n), func(b *testing.B) {
+				tags := missingTagNames(n)
+				w := &countingTagWriter{}
+				for j := 0; j < b.N; j++ {
+					i := Importer{
+						TagWriter:           bm.tagWriter(w),
+						MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+						Input: jsonschema.Performer{
+							Name: performerName,
+							Tags: tags,
+						},
+					}
+
+					if err := i.PreImport(testCtx); err != nil {
+						b.Fatal(err)
+					}
+				}
+
+				calls := float64(w.calls) / float64(b.N)
+				if bm.maxCalls > 0 && calls > float64(bm.maxCalls) {
+					b.Fatalf("%v calls per import, expected at most %d", calls, bm.maxCalls)
+				}
+
+				b.ReportMetric(calls, "calls/op")
+			})
+		}
+	}
+}
+
+func TestImporterPreImportWithMissingTagCreateErr(t *testing.T) {
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		TagWriter: tagReaderWriter,
+		Input: jsonschema.Performer{
+			Tags: []string{
+				missingTagName,
+			},
+		},
+		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{missingTagName}, false).Return(nil, nil).Once()
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+	tagReaderWriter.On("Create", testCtx, mock.AnythingOfType("models.Tag")).Return(nil, errors.New("Create error"))
+
+	err := i.PreImport(testCtx)
+	assert.NotNil(t, err)
+}
+
+func TestImporterPreImportWithTagLookupErr(t *testing.T) {
+	lookupErr := errors.New("FindByNames error")
+	names := []string{existingTagName, missingTagName}
+
+	tests := []struct {
+		name                string
+		found               []*models.Tag
+		missingRefBehaviour models.ImportMissingRefEnum
+		wantErr             bool
+		wantTags            int
+	}{
+		{"no results", nil, models.ImportMissingRefEnumIgnore, true, 0},
+		{"partial fail", []*models.Tag{{ID: existingTagID, Name: existingTagName}}, models.ImportMissingRefEnumFail, true, 0},
+		{"partial create", []*models.Tag{{ID: existingTagID, Name: existingTagName}}, models.ImportMissingRefEnumCreate, true, 0},
+		{"partial ignore", []*models.Tag{{ID: existingTagID, Name: existingTagName}}, models.ImportMissingRefEnumIgnore, false, 1},
+		{"all found", []*models.Tag{{ID: existingTagID, Name: existingTagName}, {ID: errTagsID, Name: missingTagName}}, models.ImportMissingRefEnumFail, false, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagReaderWriter := &mocks.TagReaderWriter{}
+
+			i := Importer{
+				TagWriter:           tagReaderWriter,
+				MissingRefBehaviour: tt.missingRefBehaviour,
+				Input: jsonschema.Performer{
+					Tags: names,
+				},
+			}
+
+			tagReaderWriter.On("FindByNames", testCtx, names, false).Return(tt.found, lookupErr).Once()
+
+			err := i.PreImport(testCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PreImport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				assert.Len(t, i.tags, tt.wantTags)
+				assert.NotEmpty(t, i.Warnings())
+			}
+
+			tagReaderWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImporterPreImportWithMissingTagAliases(t *testing.T) {
+	const (
+		aliasTagID   = 120
+		aliasTagName = "aliasTagName"
+		alias        = "alias"
+	)
+
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		TagWriter:           tagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+		Input: jsonschema.Performer{
+			Tags: []string{
+				missingTagName,
+				alias,
+				existingTagErr,
+			},
+			TagAliases: map[string][]string{
+				missingTagName: {alias},
+			},
+		},
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, i.Input.Tags, false).Return(nil, nil).Once()
+	tagReaderWriter.On("Query", testCtx, mock.MatchedBy(func(f *models.TagFilterType) bool {
+		return f.Aliases.Value == existingTagErr
+	}), mock.Anything).Return([]*models.Tag{
+		{
+			ID:   aliasTagID,
+			Name: aliasTagName,
+		},
+	}, 1, nil).Once()
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil).Twice()
+	tagReaderWriter.On("Create", testCtx, mock.MatchedBy(func(t models.Tag) bool {
+		return t.Name == missingTagName
+	})).Return(&models.Tag{
+		ID:   existingTagID,
+		Name: missingTagName,
+	}, nil).Once()
+	tagReaderWriter.On("UpdateAliases", testCtx, existingTagID, []string{alias}).Return(nil).Once()
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+	if assert.Len(t, i.tags, 2) {
+		assert.Equal(t, aliasTagID, i.tags[0].ID)
+		assert.Equal(t, existingTagID, i.tags[1].ID)
+	}
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportTagPrefix(t *testing.T) {
+	const prefix = "tenant:"
+
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		TagWriter:           tagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+		TagPrefix:           prefix,
+		Input: jsonschema.Performer{
+			Tags: []string{
+				existingTagName,
+				missingTagName,
+			},
+			RemoveTags: []string{
+				existingTagErr,
+			},
+		},
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{prefix + existingTagErr}, false).Return([]*models.Tag{
+		{
+			ID:   errTagsID,
+			Name: prefix + existingTagErr,
+		},
+	}, nil).Once()
+	tagReaderWriter.On("FindByNames", testCtx, []string{prefix + existingTagName, prefix + missingTagName}, false).Return([]*models.Tag{
+		{
+			ID:   existingTagID,
+			Name: prefix + existingTagName,
+		},
+	}, nil).Once()
+	tagReaderWriter.On("Query", testCtx, mock.MatchedBy(func(f *models.TagFilterType) bool {
+		return f.Aliases.Value == prefix+missingTagName
+	}), mock.Anything).Return(nil, 0, nil).Once()
+	tagReaderWriter.On("Create", testCtx, mock.MatchedBy(func(t models.Tag) bool {
+		return t.Name == prefix+missingTagName
+	})).Return(&models.Tag{
+		ID:   existingTagID + 1,
+		Name: prefix + missingTagName,
+	}, nil).Once()
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Len(t, i.tags, 2)
+	assert.Len(t, i.removeTags, 1)
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportWithRemoveTag(t *testing.T) {
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		TagWriter:           tagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+		Input: jsonschema.Performer{
+			RemoveTags: []string{
+				existingTagName,
+				missingTagName,
+			},
+		},
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagName, missingTagName}, false).Return([]*models.Tag{
+		{
+			ID:   existingTagID,
+			Name: existingTagName,
+		},
+	}, nil).Once()
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+	assert.Len(t, i.removeTags, 1)
+	assert.Equal(t, existingTagID, i.removeTags[0].ID)
+
+	// tag in both lists
+	i.Input.Tags = []string{existingTagName}
+	err = i.PreImport(testCtx)
+	assert.NotNil(t, err)
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportSortMissingTags(t *testing.T) {
+	names := []string{"c", "a", "b"}
+
+	tests := []struct {
+		name string
+		sort bool
+		want []string
+	}{
+		{"unsorted", false, names},
+		{"sorted", true, []string{"a", "b", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagReaderWriter := &mocks.TagReaderWriter{}
+
+			i := Importer{
+				TagWriter:           tagReaderWriter,
+				MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+				SortMissingTags:     tt.sort,
+				Input: jsonschema.Performer{
+					Tags: names,
+				},
+			}
+
+			var created []string
+			tagReaderWriter.On("FindByNames", testCtx, names, false).Return(nil, nil).Once()
+			tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+			tagReaderWriter.On("Create", testCtx, mock.AnythingOfType("models.Tag")).Return(func(ctx context.Context, newTag models.Tag) *models.Tag {
+				created = append(created, newTag.Name)
+				return &models.Tag{
+					ID:   len(created),
+					Name: newTag.Name,
+				}
+			}, nil).Times(len(names))
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, created)
+
+			tagReaderWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImporterPreImportTagCollection(t *testing.T) {
+	const (
+		collectionName = "collection"
+		collectionID   = 110
+		memberTagID    = 111
+		memberTagName  = "memberTagName"
+	)
+
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		TagWriter:           tagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+		TagCollection:       collectionName,
+		Input: jsonschema.Performer{
+			Tags: []string{
+				existingTagName,
+				memberTagName,
+			},
+		},
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagName, memberTagName}, false).Return([]*models.Tag{
+		{
+			ID:   existingTagID,
+			Name: existingTagName,
+		},
+		{
+			ID:   memberTagID,
+			Name: memberTagName,
+		},
+	}, nil).Once()
+	tagReaderWriter.On("FindByNames", testCtx, []string{collectionName}, false).Return([]*models.Tag{
+		{
+			ID:   collectionID,
+			Name: collectionName,
+		},
+	}, nil).Once()
+
+	tagReaderWriter.On("FindByChildTagID", testCtx, existingTagID).Return(nil, nil).Once()
+	tagReaderWriter.On("FindByChildTagID", testCtx, memberTagID).Return([]*models.Tag{
+		{
+			ID: collectionID,
+		},
+	}, nil).Once()
+
+	tagReaderWriter.On("FindAllAncestors", testCtx, existingTagID, []int(nil)).Return(nil, nil).Once()
+	tagReaderWriter.On("FindAllDescendants", testCtx, existingTagID, []int(nil)).Return(nil, nil).Once()
+	tagReaderWriter.On("FindByParentTagID", testCtx, existingTagID).Return(nil, nil).Once()
+	tagReaderWriter.On("UpdateParentTags", testCtx, existingTagID, []int{collectionID}).Return(nil).Once()
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportMissingTagCollection(t *testing.T) {
+	const collectionName = "collection"
+
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		TagWriter:           tagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumFail,
+		TagCollection:       collectionName,
+		Input: jsonschema.Performer{
+			Tags: []string{
+				existingTagName,
+			},
+		},
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagName}, false).Return([]*models.Tag{
+		{
+			ID:   existingTagID,
+			Name: existingTagName,
+		},
+	}, nil).Twice()
+	tagReaderWriter.On("FindByNames", testCtx, []string{collectionName}, false).Return(nil, nil).Twice()
+
+	err := i.PreImport(testCtx)
+	assert.NotNil(t, err)
+
+	// ignored collection is a no-op
+	i.MissingRefBehaviour = models.ImportMissingRefEnumIgnore
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPostImport(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		imageData:    imageBytes,
+	}
+
+	updatePerformerImageErr := errors.New("UpdateImage error")
+
+	readerWriter.On("UpdateImage", testCtx, performerID, imageBytes).Return(nil).Once()
+	readerWriter.On("UpdateImage", testCtx, errImageID, imageBytes).Return(updatePerformerImageErr).Once()
+
+	err := i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"image"}, i.Changed())
+
+	err = i.PostImport(testCtx, errImageID)
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportAttachments(t *testing.T) {
+	encode := func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	}
+
+	pdf := jsonschema.PerformerAttachment{
+		Name: "release.pdf",
+		Data: encode("%PDF-1.4 release form"),
+	}
+	text := jsonschema.PerformerAttachment{
+		Name:        "notes.txt",
+		ContentType: "text/markdown",
+		Data:        encode("notes"),
+	}
+	invalid := jsonschema.PerformerAttachment{
+		Name: "invalid",
+		Data: "not base64!",
+	}
+
+	limits := AttachmentLimits{
+		MaxSize:      64,
+		ContentTypes: []string{"application/pdf", "text/plain"},
+	}
+
+	tests := []struct {
+		name        string
+		attachments []jsonschema.PerformerAttachment
+		limits      AttachmentLimits
+		behaviour   AttachmentErrorBehaviour
+		want        []models.PerformerAttachment
+		wantErr     error
+		wantWarning bool
+	}{
+		{"none", nil, limits, "", nil, nil, false},
+		{"valid", []jsonschema.PerformerAttachment{pdf, text}, limits, "", []models.PerformerAttachment{
+			{Name: pdf.Name, ContentType: "application/pdf", Data: []byte("%PDF-1.4 release form")},
+			{Name: text.Name, ContentType: text.ContentType, Data: []byte("notes")},
+		}, nil, false},
+		{"invalid", []jsonschema.PerformerAttachment{pdf, invalid}, limits, "", nil, ErrAttachmentDecode, false},
+		{"too large", []jsonschema.PerformerAttachment{pdf}, AttachmentLimits{MaxSize: 4}, "", nil, ErrAttachmentTooLarge, false},
+		{"unsupported type", []jsonschema.PerformerAttachment{pdf}, AttachmentLimits{ContentTypes: []string{"text/plain"}}, "", nil, ErrAttachmentUnsupportedType, false},
+		{"skip invalid", []jsonschema.PerformerAttachment{invalid, text}, limits, AttachmentErrorSkip, []models.PerformerAttachment{
+			{Name: text.Name, ContentType: text.ContentType, Data: []byte("notes")},
+		}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				AttachmentLimits:         tt.limits,
+				AttachmentErrorBehaviour: tt.behaviour,
+				Input: jsonschema.Performer{
+					Name:        performerName,
+					Attachments: tt.attachments,
+				},
+			}
+
+			err := i.PreImport(testCtx)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, i.attachments)
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+		})
+	}
+}
+
+func TestImporterPostImportAttachments(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	attachments := []models.PerformerAttachment{
+		{
+			Name:        "notes.txt",
+			ContentType: "text/plain",
+			Data:        []byte("notes"),
+		},
+	}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		attachments:  attachments,
+	}
+
+	updateAttachmentsErr := errors.New("UpdateAttachments error")
+
+	readerWriter.On("UpdateAttachments", testCtx, performerID, attachments).Return(nil).Once()
+	readerWriter.On("UpdateAttachments", testCtx, errAttachmentsID, attachments).Return(updateAttachmentsErr).Once()
+
+	err := i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"attachments"}, i.Changed())
+
+	err = i.PostImport(testCtx, errAttachmentsID)
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterFindExistingID(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		Input: jsonschema.Performer{
+			Name: performerName,
+		},
+	}
+
+	errFindByNames := errors.New("FindByNames error")
+	readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return(nil, nil).Once()
+	readerWriter.On("FindByNames", testCtx, []string{existingPerformerName}, false).Return([]*models.Performer{
+		{
+			ID: existingPerformerID,
+		},
+	}, nil).Once()
+	readerWriter.On("FindByNames", testCtx, []string{performerNameErr}, false).Return(nil, errFindByNames).Once()
+
+	id, err := i.FindExistingID(testCtx)
+	assert.Nil(t, id)
+	assert.Nil(t, err)
+
+	i.Input.Name = existingPerformerName
+	id, err = i.FindExistingID(testCtx)
+	assert.Equal(t, existingPerformerID, *id)
+	assert.Nil(t, err)
+
+	i.Input.Name = performerNameErr
+	id, err = i.FindExistingID(testCtx)
+	assert.Nil(t, id)
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterFindExistingIDStashID(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	otherStashID := models.StashID{
+		StashID:  "otherStashID",
+		Endpoint: "endpoint",
+	}
+	errStashID := models.StashID{
+		StashID:  "errStashID",
+		Endpoint: "endpoint",
+	}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		Input: jsonschema.Performer{
+			// name changed upstream
+			Name:     performerName,
+			StashIDs: []models.StashID{otherStashID, stashID},
+		},
+	}
+
+	readerWriter.On("FindByStashID", testCtx, otherStashID).Return(nil, nil)
+	readerWriter.On("FindByStashID", testCtx, stashID).Return([]*models.Performer{
+		{
+			ID:   existingPerformerID,
+			Name: existingPerformerName,
+		},
+	}, nil).Once()
+
+	id, err := i.FindExistingID(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, existingPerformerID, *id)
+
+	// fall back to name lookup if no stash ID matches
+	i.Input.StashIDs = []models.StashID{otherStashID}
+	readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return(nil, nil).Once()
+
+	id, err = i.FindExistingID(testCtx)
+	assert.Nil(t, err)
+	assert.Nil(t, id)
+
+	i.Input.StashIDs = []models.StashID{errStashID}
+	readerWriter.On("FindByStashID", testCtx, errStashID).Return(nil, errors.New("FindByStashID error")).Once()
+
+	id, err = i.FindExistingID(testCtx)
+	assert.NotNil(t, err)
+	assert.Nil(t, id)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterFindExistingIDCaseInsensitive(t *testing.T) {
+	const foldedName = "TESTPERFORMER"
+
+	tests := []struct {
+		name     string
+		existing []*models.Performer
+		want     int
+	}{
+		{
+			"folded",
+			[]*models.Performer{
+				{ID: 5, Name: foldedName},
+			},
+			5,
+		},
+		{
+			"prefer exact",
+			[]*models.Performer{
+				{ID: 1, Name: foldedName},
+				{ID: 7, Name: performerName},
+			},
+			7,
+		},
+		{
+			"lowest id",
+			[]*models.Performer{
+				{ID: 5, Name: foldedName},
+				{ID: 3, Name: "testPERFORMER"},
+				{ID: 4, Name: "TestPerformer"},
+			},
+			3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+
+			i := Importer{
+				ReaderWriter:         readerWriter,
+				CaseInsensitiveMatch: true,
+				Input: jsonschema.Performer{
+					Name: performerName,
+				},
+			}
+
+			readerWriter.On("FindByNames", testCtx, []string{performerName}, true).Return(tt.existing, nil).Once()
+
+			id, err := i.FindExistingID(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, *id)
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImporterFindExistingIDAlias(t *testing.T) {
+	const otherID = 101
+
+	tests := []struct {
+		name                string
+		matchAliases        bool
+		missingRefBehaviour models.ImportMissingRefEnum
+		existing            []*models.Performer
+		want                *int
+		wantErr             bool
+		wantWarning         bool
+	}{
+		{"disabled", false, "", nil, nil, false, false},
+		{"no match", true, "", nil, nil, false, false},
+		{"match", true, "", []*models.Performer{
+			{ID: existingPerformerID, Aliases: performerName},
+		}, intPtr(existingPerformerID), false, false},
+		{"ambiguous fail", true, models.ImportMissingRefEnumFail, []*models.Performer{
+			{ID: existingPerformerID, Aliases: performerName},
+			{ID: otherID, Aliases: performerName},
+		}, nil, true, false},
+		{"ambiguous ignore", true, models.ImportMissingRefEnumIgnore, []*models.Performer{
+			{ID: existingPerformerID, Aliases: performerName},
+			{ID: otherID, Aliases: performerName},
+		}, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+
+			i := Importer{
+				ReaderWriter:        readerWriter,
+				MatchAliases:        tt.matchAliases,
+				MissingRefBehaviour: tt.missingRefBehaviour,
+				Input: jsonschema.Performer{
+					Name: performerName,
+				},
+			}
+
+			readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return(nil, nil).Once()
+			if tt.matchAliases {
+				readerWriter.On("FindByAlias", testCtx, performerName, false).Return(tt.existing, nil).Once()
+			}
+
+			id, err := i.FindExistingID(testCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Importer.FindExistingID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, id)
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImporterFindExistingIDBatch(t *testing.T) {
+	const otherName = "otherName"
+
+	tests := []struct {
+		name        string
+		policy      DuplicateRecordPolicy
+		wantErr     bool
+		wantSkipped bool
+	}{
+		{"last", DuplicateRecordPolicyLast, false, false},
+		{"first", DuplicateRecordPolicyFirst, false, true},
+		{"fail", DuplicateRecordPolicyFail, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+			readerWriter.On("FindByNames", testCtx, mock.Anything, false).Return([]*models.Performer{
+				{
+					ID: existingPerformerID,
+				},
+			}, nil).Twice()
+
+			batch := &Batch{
+				DuplicateRecordPolicy: tt.policy,
+			}
+
+			first := Importer{
+				ReaderWriter: readerWriter,
+				Batch:        batch,
+				Input: jsonschema.Performer{
+					Name: existingPerformerName,
+				},
+			}
+
+			id, err := first.FindExistingID(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, existingPerformerID, *id)
+			assert.False(t, first.Skipped())
+			assert.Empty(t, first.Warnings())
+
+			second := Importer{
+				ReaderWriter: readerWriter,
+				Batch:        batch,
+				Input: jsonschema.Performer{
+					Name: otherName,
+				},
+			}
+
+			_, err = second.FindExistingID(testCtx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FindExistingID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantSkipped, second.Skipped())
+			if !tt.wantErr {
+				assert.Len(t, second.Warnings(), 1)
+			}
+
+			// skipped importers must not write
+			if tt.wantSkipped {
+				assert.Nil(t, second.Update(testCtx, existingPerformerID))
+				assert.Nil(t, second.PostImport(testCtx, existingPerformerID))
+			}
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImporterFindExistingIDDisambiguation(t *testing.T) {
+	const (
+		firstID  = 1
+		secondID = 2
+	)
+
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	existing := []*models.Performer{
+		{ID: firstID, Name: performerName, Disambiguation: "first"},
+		{ID: secondID, Name: performerName, Disambiguation: "second"},
+	}
+
+	readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return(existing, nil)
+	readerWriter.On("FindByAlias", testCtx, performerName, false).Return(existing, nil)
+
+	tests := []struct {
+		name           string
+		disambiguation string
+		want           *int
+	}{
+		{"first", "first", intPtr(firstID)},
+		{"second", "second", intPtr(secondID)},
+		{"different", "third", nil},
+		{"empty", "", nil},
+	}
+
+	var checksums []string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				ReaderWriter: readerWriter,
+				MatchAliases: true,
+				Input: jsonschema.Performer{
+					Name:           performerName,
+					Disambiguation: tt.disambiguation,
+				},
+			}
+
+			assert.Nil(t, i.PreImport(testCtx))
+			checksums = append(checksums, i.performer.Checksum)
+
+			id, err := i.FindExistingID(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+
+	// all same-named performers have distinct checksums
+	assert.Len(t, stringslice.StrAppendUniques(nil, checksums), len(tests))
+}
+
+func TestImporterBatchDisambiguation(t *testing.T) {
+	const (
+		firstID  = 1
+		secondID = 2
+	)
+
+	readerWriter := &mocks.PerformerReaderWriter{}
+	batch := &Batch{}
+
+	newImporter := func(disambiguation string) *Importer {
+		return &Importer{
+			ReaderWriter: readerWriter,
+			Batch:        batch,
+			Input: jsonschema.Performer{
+				Name:           performerName,
+				Disambiguation: disambiguation,
+			},
+		}
+	}
+
+	createdPerformer := func(id int) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			args.Get(1).(*models.Performer).ID = id
+		}
+	}
+
+	readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return(nil, nil).Once()
+	readerWriter.On("Create", testCtx, mock.AnythingOfType("*models.Performer")).Run(createdPerformer(firstID)).Return(nil).Once()
+
+	first := newImporter("first")
+	assert.Nil(t, first.PreImport(testCtx))
+	id, err := first.FindExistingID(testCtx)
+	assert.Nil(t, err)
+	assert.Nil(t, id)
+	id, err = first.Create(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, firstID, *id)
+
+	// the first performer is found by name, but has a different
+	// disambiguation, so a second performer is created
+	readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return([]*models.Performer{
+		{
+			ID:   firstID,
+			Name: performerName,
+		},
+	}, nil).Once()
+	readerWriter.On("Create", testCtx, mock.AnythingOfType("*models.Performer")).Run(createdPerformer(secondID)).Return(nil).Once()
+
+	second := newImporter("second")
+	assert.Nil(t, second.PreImport(testCtx))
+	id, err = second.FindExistingID(testCtx)
+	assert.Nil(t, err)
+	assert.Nil(t, id)
+	id, err = second.Create(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, secondID, *id)
+	assert.NotEqual(t, first.performer.Checksum, second.performer.Checksum)
+
+	// a repeated record with the same disambiguation resolves to the
+	// performer created earlier in the batch
+	repeated := newImporter("first")
+	assert.Nil(t, repeated.PreImport(testCtx))
+	id, err = repeated.FindExistingID(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, firstID, *id)
+	assert.Len(t, repeated.Warnings(), 1)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestChecksum(t *testing.T) {
+	assert.Equal(t, md5.FromString(performerName), Checksum(performerName, ""))
+	assert.NotEqual(t, Checksum(performerName, "first"), Checksum(performerName, "second"))
+	assert.NotEqual(t, Checksum(performerName, ""), Checksum(performerName, "first"))
+}
+
+func TestImporterPostImportUpdateTags(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		tags: []*models.Tag{
+			{
+				ID: existingTagID,
+			},
+		},
+	}
+
+	updateErr := errors.New("UpdateTags error")
+
+	readerWriter.On("UpdateTags", testCtx, performerID, []int{existingTagID}).Return(nil).Once()
+	readerWriter.On("UpdateTags", testCtx, errTagsID, mock.AnythingOfType("[]int")).Return(updateErr).Once()
+
+	err := i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+
+	err = i.PostImport(testCtx, errTagsID)
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterPostImportRemoveTags(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	const (
+		otherTagID  = 107
+		removeTagID = 108
+	)
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		Input: jsonschema.Performer{
+			RemoveTags: []string{existingTagErr},
+		},
+		tags: []*models.Tag{
+			{
+				ID: existingTagID,
+			},
+		},
+		removeTags: []*models.Tag{
+			{
+				ID: removeTagID,
+			},
+		},
+	}
+
+	getErr := errors.New("GetTagIDs error")
+
+	readerWriter.On("GetTagIDs", testCtx, performerID).Return([]int{otherTagID, removeTagID}, nil).Once()
+	readerWriter.On("UpdateTags", testCtx, performerID, []int{otherTagID, existingTagID}).Return(nil).Once()
+	readerWriter.On("GetTagIDs", testCtx, errTagsID).Return(nil, getErr).Once()
+
+	err := i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+
+	err = i.PostImport(testCtx, errTagsID)
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterPostImportTagUpdateMode(t *testing.T) {
+	const otherTagID = 107
+
+	inputTags := []*models.Tag{
+		{
+			ID: existingTagID,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		tags       []*models.Tag
+		removeTags []string
+		updated    bool
+		// nil if UpdateTags should not be called
+		wantTagIDs []int
+	}{
+		{"create replaces", inputTags, nil, false, []int{existingTagID}},
+		{"create without tags", nil, nil, false, nil},
+		{"update replaces", inputTags, nil, true, []int{existingTagID}},
+		{"update without tags clears", nil, nil, true, []int{}},
+		{"merge adds", inputTags, []string{missingTagName}, true, []int{otherTagID, existingTagID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+
+			i := Importer{
+				ReaderWriter: readerWriter,
+				Input: jsonschema.Performer{
+					RemoveTags: tt.removeTags,
+				},
+				tags:    tt.tags,
+				updated: tt.updated,
+			}
+
+			readerWriter.On("GetTagIDs", testCtx, performerID).Return([]int{otherTagID}, nil).Maybe()
+			if tt.wantTagIDs != nil {
+				readerWriter.On("UpdateTags", testCtx, performerID, mock.MatchedBy(func(ids []int) bool {
+					return assert.ElementsMatch(t, tt.wantTagIDs, ids)
+				})).Return(nil).Once()
+			}
+
+			err := i.PostImport(testCtx, performerID)
+			assert.Nil(t, err)
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	performer := models.Performer{
+		Name: performerName,
+	}
+
+	performerErr := models.Performer{
+		Name: performerNameErr,
+	}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		performer:    performer,
+	}
+
+	errCreate := errors.New("Create error")
+	readerWriter.On("Create", testCtx, &performer).Run(func(args mock.Arguments) {
+		arg := args.Get(1).(*models.Performer)
+		arg.ID = performerID
+	}).Return(nil).Once()
+	readerWriter.On("Create", testCtx, &performerErr).Return(errCreate).Once()
+
+	id, err := i.Create(testCtx)
+	assert.Equal(t, performerID, *id)
+	assert.Nil(t, err)
+
+	i.performer = performerErr
+	id, err = i.Create(testCtx)
+	assert.Nil(t, id)
+	assert.NotNil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestUpdate(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	performer := models.Performer{
+		Name: performerName,
+	}
+
+	performerErr := models.Performer{
+		Name: performerNameErr,
+	}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		performer:    performer,
+	}
+
+	errUpdate := errors.New("Update error")
+
+	// id needs to be set for the mock input
+	performer.ID = performerID
+	readerWriter.On("Update", testCtx, &performer).Return(nil).Once()
+
+	err := i.Update(testCtx, performerID)
 	assert.Nil(t, err)
 
 	i.performer = performerErr
@@ -304,3 +2397,596 @@ func TestUpdate(t *testing.T) {
 
 	readerWriter.AssertExpectations(t)
 }
+
+type changeReportingReaderWriter struct {
+	*mocks.PerformerReaderWriter
+}
+
+func (rw changeReportingReaderWriter) UpdateIfChanged(ctx context.Context, updatedPerformer *models.Performer) (bool, error) {
+	ret := rw.Called(ctx, updatedPerformer)
+	return ret.Bool(0), ret.Error(1)
+}
+
+func TestUpdateIfChanged(t *testing.T) {
+	readerWriter := changeReportingReaderWriter{&mocks.PerformerReaderWriter{}}
+
+	performer := models.Performer{
+		Name: performerName,
+	}
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		performer:    performer,
+	}
+
+	performer.ID = performerID
+	readerWriter.On("UpdateIfChanged", testCtx, &performer).Return(false, nil).Twice()
+	readerWriter.On("GetTagIDs", testCtx, performerID).Return([]int{}, nil).Once()
+
+	err := i.Update(testCtx, performerID)
+	assert.Nil(t, err)
+	assert.True(t, i.Unchanged())
+	assert.True(t, i.Skipped())
+	assert.Len(t, i.Changed(), 0)
+
+	// the performer is changed if PostImport changes its tags
+	i = Importer{
+		ReaderWriter: readerWriter,
+		performer:    performer,
+	}
+
+	readerWriter.On("GetTagIDs", testCtx, performerID).Return([]int{existingTagID}, nil).Once()
+
+	err = i.Update(testCtx, performerID)
+	assert.Nil(t, err)
+	assert.False(t, i.Unchanged())
+	assert.False(t, i.Skipped())
+	assert.Len(t, i.Changed(), 0)
+
+	i = Importer{
+		ReaderWriter: readerWriter,
+		performer:    performer,
+	}
+
+	readerWriter.On("UpdateIfChanged", testCtx, &performer).Return(true, nil).Once()
+
+	err = i.Update(testCtx, performerID)
+	assert.Nil(t, err)
+	assert.False(t, i.Unchanged())
+	assert.Equal(t, []string{"performer"}, i.Changed())
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestUpdateMerge(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+
+	const (
+		details      = "existing details"
+		measurements = "34C-24-34"
+		oldURL       = "http://example.com/old"
+		newURL       = "http://example.com/new"
+	)
+
+	existing := models.Performer{
+		ID:           performerID,
+		Name:         performerName,
+		Checksum:     Checksum(performerName, ""),
+		URL:          oldURL,
+		URLs:         []string{oldURL},
+		Details:      details,
+		Measurements: measurements,
+		Favorite:     true,
+		Rating:       intPtr(rating),
+	}
+
+	i := Importer{
+		ReaderWriter:  readerWriter,
+		MergeStrategy: MergeStrategyMerge,
+		Input: jsonschema.Performer{
+			Name: performerName,
+			URL:  newURL,
+		},
+	}
+
+	i.performer = i.performerJSONToPerformer(i.Input)
+
+	expected := existing
+	expected.URL = newURL
+	expected.URLs = []string{newURL}
+	expected.CreatedAt = i.performer.CreatedAt
+	expected.UpdatedAt = i.performer.UpdatedAt
+
+	readerWriter.On("Find", testCtx, performerID).Return(&existing, nil).Once()
+	readerWriter.On("Update", testCtx, &expected).Return(nil).Once()
+	readerWriter.On("Find", testCtx, errImageID).Return(nil, nil).Once()
+
+	err := i.Update(testCtx, performerID)
+	assert.Nil(t, err)
+
+	err = i.Update(testCtx, errImageID)
+	assert.NotNil(t, err)
+
+	// replace is the default
+	i.MergeStrategy = ""
+	replaced := i.performer
+	replaced.ID = performerID
+	readerWriter.On("Update", testCtx, &replaced).Return(nil).Once()
+
+	err = i.Update(testCtx, performerID)
+	assert.Nil(t, err)
+
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterPostImportMerge(t *testing.T) {
+	const (
+		otherTagID = 107
+		endpoint   = "endpoint"
+	)
+
+	existingStashID := models.StashID{StashID: "existing", Endpoint: "otherEndpoint"}
+	newStashID := models.StashID{StashID: "new", Endpoint: endpoint}
+	newImage := []byte("newImage")
+
+	tests := []struct {
+		name          string
+		relationships models.RelationshipUpdateMode
+		tags          []string
+		existingImage []byte
+		wantTagIDs    []int
+		wantStashIDs  []models.StashID
+		wantImage     bool
+	}{
+		{
+			"add",
+			"",
+			[]string{existingTagName},
+			imageBytes,
+			[]int{otherTagID, existingTagID},
+			[]models.StashID{existingStashID, newStashID},
+			false,
+		},
+		{
+			"add without existing image",
+			models.RelationshipUpdateModeAdd,
+			[]string{existingTagName},
+			nil,
+			[]int{otherTagID, existingTagID},
+			[]models.StashID{existingStashID, newStashID},
+			true,
+		},
+		{
+			"set",
+			models.RelationshipUpdateModeSet,
+			[]string{existingTagName},
+			imageBytes,
+			[]int{existingTagID},
+			[]models.StashID{newStashID},
+			true,
+		},
+		{
+			"empty tags",
+			models.RelationshipUpdateModeSet,
+			nil,
+			imageBytes,
+			nil,
+			[]models.StashID{newStashID},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+
+			i := Importer{
+				ReaderWriter:       readerWriter,
+				MergeStrategy:      MergeStrategyMerge,
+				MergeRelationships: tt.relationships,
+				Input: jsonschema.Performer{
+					Name:     performerName,
+					Tags:     tt.tags,
+					StashIDs: []models.StashID{newStashID},
+				},
+				image:     image,
+				imageData: newImage,
+				updated:   true,
+			}
+			if len(tt.tags) > 0 {
+				i.tags = []*models.Tag{{ID: existingTagID}}
+			}
+
+			readerWriter.On("GetTagIDs", testCtx, performerID).Return([]int{otherTagID}, nil).Maybe()
+			readerWriter.On("GetStashIDs", testCtx, performerID).Return([]models.StashID{existingStashID}, nil).Maybe()
+			readerWriter.On("GetImage", testCtx, performerID).Return(tt.existingImage, nil).Maybe()
+			if tt.wantTagIDs != nil {
+				readerWriter.On("UpdateTags", testCtx, performerID, tt.wantTagIDs).Return(nil).Once()
+			}
+			readerWriter.On("UpdateStashIDs", testCtx, performerID, tt.wantStashIDs).Return(nil).Once()
+			if tt.wantImage {
+				readerWriter.On("UpdateImage", testCtx, performerID, newImage).Return(nil).Once()
+			}
+
+			err := i.PostImport(testCtx, performerID)
+			assert.Nil(t, err)
+
+			if tt.wantTagIDs == nil {
+				readerWriter.AssertNotCalled(t, "UpdateTags", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if !tt.wantImage {
+				readerWriter.AssertNotCalled(t, "UpdateImage", mock.Anything, mock.Anything, mock.Anything)
+			}
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImporterContinueOnError(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	const (
+		failTagName = "failTagName"
+		createdID   = 107
+	)
+
+	names := []string{failTagName, missingTagName}
+	createErr := errors.New("Create error")
+
+	i := Importer{
+		ReaderWriter: readerWriter,
+		TagWriter:    tagReaderWriter,
+		Input: jsonschema.Performer{
+			Name:  performerName,
+			Tags:  names,
+			Image: invalidImage,
+		},
+		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, names, false).Return(nil, nil).Times(2)
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+	tagReaderWriter.On("Create", testCtx, mock.MatchedBy(func(newTag models.Tag) bool {
+		return newTag.Name == failTagName
+	})).Return(nil, createErr).Twice()
+	tagReaderWriter.On("Create", testCtx, mock.MatchedBy(func(newTag models.Tag) bool {
+		return newTag.Name == missingTagName
+	})).Return(&models.Tag{
+		ID:   createdID,
+		Name: missingTagName,
+	}, nil).Once()
+
+	// the first error fails the import by default
+	err := i.PreImport(testCtx)
+	assert.NotNil(t, err)
+
+	i.ContinueOnError = true
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+	if assert.Len(t, i.tags, 1) {
+		assert.Equal(t, createdID, i.tags[0].ID)
+	}
+	assert.Empty(t, i.imageData)
+
+	readerWriter.On("Create", testCtx, mock.AnythingOfType("*models.Performer")).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Performer).ID = performerID
+	}).Return(nil).Once()
+	readerWriter.On("UpdateTags", testCtx, performerID, []int{createdID}).Return(nil).Once()
+
+	id, err := i.Create(testCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, performerID, *id)
+
+	err = i.PostImport(testCtx, performerID)
+	var importErrs ImportErrors
+	if assert.True(t, errors.As(err, &importErrs)) {
+		// the failed tag and the invalid image are reported
+		assert.Len(t, importErrs, 2)
+		assert.Contains(t, err.Error(), failTagName)
+	}
+
+	readerWriter.AssertExpectations(t)
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterDryRun(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	names := []string{existingTagName, missingTagName}
+
+	newImporter := func(name string) *Importer {
+		return &Importer{
+			ReaderWriter: readerWriter,
+			TagWriter:    tagReaderWriter,
+			Input: jsonschema.Performer{
+				Name:     name,
+				Tags:     names,
+				Image:    image,
+				StashIDs: []models.StashID{{StashID: "stashID", Endpoint: "endpoint"}},
+			},
+			MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+			DryRun:              true,
+		}
+	}
+
+	tagReaderWriter.On("FindByNames", testCtx, names, false).Return([]*models.Tag{
+		{ID: existingTagID, Name: existingTagName},
+	}, nil)
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+	readerWriter.On("FindByStashID", testCtx, mock.Anything).Return(nil, nil)
+	readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return(nil, nil).Once()
+	readerWriter.On("FindByNames", testCtx, []string{existingPerformerName}, false).Return([]*models.Performer{
+		{ID: existingPerformerID, Name: existingPerformerName},
+	}, nil).Once()
+
+	// new performer
+	i := newImporter(performerName)
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	existing, err := i.FindExistingID(testCtx)
+	assert.Nil(t, err)
+	assert.Nil(t, existing)
+
+	id, err := i.Create(testCtx)
+	assert.Nil(t, err)
+	assert.NotNil(t, id)
+
+	err = i.PostImport(testCtx, *id)
+	assert.Nil(t, err)
+
+	assert.Equal(t, Preview{
+		Name:        performerName,
+		Action:      PreviewActionCreate,
+		CreateTags:  []string{missingTagName},
+		MatchedTags: []string{existingTagName},
+		Image:       PreviewImageActionSet,
+	}, i.Plan())
+
+	// existing performer
+	i = newImporter(existingPerformerName)
+	err = i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	existing, err = i.FindExistingID(testCtx)
+	assert.Nil(t, err)
+	if assert.NotNil(t, existing) {
+		err = i.Update(testCtx, *existing)
+		assert.Nil(t, err)
+
+		err = i.PostImport(testCtx, *existing)
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, Preview{
+		Name:        existingPerformerName,
+		Action:      PreviewActionUpdate,
+		ID:          existingPerformerID,
+		CreateTags:  []string{missingTagName},
+		MatchedTags: []string{existingTagName},
+		Image:       PreviewImageActionSet,
+	}, i.Plan())
+
+	// invalid input is still reported
+	i = newImporter(performerName)
+	i.Input.Image = invalidImage
+	err = i.PreImport(testCtx)
+	assert.NotNil(t, err)
+
+	// only read methods are called
+	reads := []string{"FindByNames", "FindByStashID", "Query"}
+	for _, c := range append(readerWriter.Calls, tagReaderWriter.Calls...) {
+		assert.Contains(t, reads, c.Method)
+	}
+	readerWriter.AssertExpectations(t)
+}
+
+func TestImporterPostImportStashIDPolicy(t *testing.T) {
+	const (
+		endpoint      = "endpoint"
+		otherEndpoint = "otherEndpoint"
+	)
+
+	existing := []models.StashID{
+		{StashID: "old", Endpoint: endpoint},
+		{StashID: "other", Endpoint: otherEndpoint},
+	}
+
+	input := []models.StashID{
+		{StashID: "first", Endpoint: endpoint},
+		{StashID: "new", Endpoint: endpoint},
+	}
+
+	tests := []struct {
+		name    string
+		policy  StashIDPolicy
+		updated bool
+		want    []models.StashID
+	}{
+		{
+			"replace deduplicates",
+			StashIDPolicyReplace,
+			true,
+			[]models.StashID{{StashID: "new", Endpoint: endpoint}},
+		},
+		{
+			"default replaces",
+			"",
+			true,
+			[]models.StashID{{StashID: "new", Endpoint: endpoint}},
+		},
+		{
+			"merge by endpoint",
+			StashIDPolicyMerge,
+			true,
+			[]models.StashID{
+				{StashID: "new", Endpoint: endpoint},
+				{StashID: "other", Endpoint: otherEndpoint},
+			},
+		},
+		{
+			"merge created",
+			StashIDPolicyMerge,
+			false,
+			[]models.StashID{{StashID: "new", Endpoint: endpoint}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+
+			i := Importer{
+				ReaderWriter:  readerWriter,
+				StashIDPolicy: tt.policy,
+				Input: jsonschema.Performer{
+					Name:     performerName,
+					StashIDs: input,
+				},
+				updated: tt.updated,
+			}
+
+			if tt.updated && tt.policy == StashIDPolicyMerge {
+				readerWriter.On("GetStashIDs", testCtx, performerID).Return(existing, nil).Once()
+			}
+			// the tags of an updated performer are replaced
+			readerWriter.On("UpdateTags", testCtx, performerID, mock.Anything).Return(nil).Maybe()
+			readerWriter.On("UpdateStashIDs", testCtx, performerID, tt.want).Return(nil).Once()
+
+			err := i.PostImport(testCtx, performerID)
+			assert.Nil(t, err)
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateTagsCancelled(t *testing.T) {
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	ctx, cancel := context.WithCancel(testCtx)
+	defer cancel()
+
+	names := []string{"first", "second", "third"}
+
+	// the context is cancelled while creating the first tag
+	tagReaderWriter.On("Create", ctx, mock.AnythingOfType("models.Tag")).Run(func(args mock.Arguments) {
+		cancel()
+	}).Return(&models.Tag{ID: existingTagID, Name: "first"}, nil).Once()
+
+	_, err := createTags(ctx, tagReaderWriter, names, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	tagReaderWriter.AssertNumberOfCalls(t, "Create", 1)
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterPreImportCancelled(t *testing.T) {
+	for _, continueOnError := range []bool{false, true} {
+		t.Run(fmt.Sprintf("continue on error %v", continueOnError), func(t *testing.T) {
+			tagReaderWriter := &mocks.TagReaderWriter{}
+
+			ctx, cancel := context.WithCancel(testCtx)
+			defer cancel()
+
+			names := []string{"first", "second", "third"}
+
+			i := Importer{
+				TagWriter: tagReaderWriter,
+				Input: jsonschema.Performer{
+					Name: performerName,
+					Tags: names,
+				},
+				MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+				ContinueOnError:     continueOnError,
+			}
+
+			tagReaderWriter.On("FindByNames", ctx, names, false).Return(nil, nil).Once()
+			tagReaderWriter.On("Query", ctx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+			tagReaderWriter.On("Create", ctx, mock.AnythingOfType("models.Tag")).Run(func(args mock.Arguments) {
+				cancel()
+			}).Return(&models.Tag{ID: existingTagID, Name: "first"}, nil).Once()
+
+			err := i.PreImport(ctx)
+			assert.ErrorIs(t, err, context.Canceled)
+
+			tagReaderWriter.AssertNumberOfCalls(t, "Create", 1)
+			tagReaderWriter.AssertExpectations(t)
+		})
+	}
+}
+
+type testPostImportHook struct {
+	err     error
+	calls   int
+	id      int
+	created bool
+}
+
+func (h *testPostImportHook) AfterImport(ctx context.Context, performerID int, created bool) error {
+	h.calls++
+	h.id = performerID
+	h.created = created
+	return h.err
+}
+
+func TestImporterPostImportHook(t *testing.T) {
+	hookErr := errors.New("hook error")
+
+	tests := []struct {
+		name        string
+		updated     bool
+		skipped     bool
+		hookErr     error
+		fatal       bool
+		wantCalled  bool
+		wantErr     bool
+		wantWarning bool
+	}{
+		{"created", false, false, nil, false, true, false, false},
+		{"updated", true, false, nil, false, true, false, false},
+		{"skipped", true, true, nil, false, false, false, false},
+		{"error non-fatal", false, false, hookErr, false, true, false, true},
+		{"error fatal", false, false, hookErr, true, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+			hook := &testPostImportHook{err: tt.hookErr}
+
+			i := Importer{
+				ReaderWriter:        readerWriter,
+				PostImportHook:      hook,
+				PostImportHookFatal: tt.fatal,
+				updated:             tt.updated,
+				skipped:             tt.skipped,
+			}
+
+			// the tags of an updated performer are replaced
+			readerWriter.On("UpdateTags", testCtx, performerID, mock.Anything).Return(nil).Maybe()
+
+			err := i.PostImport(testCtx, performerID)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, hookErr)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			if tt.wantCalled {
+				assert.Equal(t, 1, hook.calls)
+				assert.Equal(t, performerID, hook.id)
+				assert.Equal(t, !tt.updated, hook.created)
+			} else {
+				assert.Equal(t, 0, hook.calls)
+			}
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+		})
+	}
+
+	// existing callers without a hook are unaffected
+	i := Importer{}
+	assert.Nil(t, i.PostImport(testCtx, performerID))
+}
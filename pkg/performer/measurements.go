@@ -0,0 +1,100 @@
+package performer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MeasurementUnits is the unit system of a measurements string. It is used
+// to validate the input measurements on import, and is not stored.
+type MeasurementUnits string
+
+const (
+	// MeasurementUnitsUS interprets measurements in inches.
+	MeasurementUnitsUS MeasurementUnits = "US"
+	// MeasurementUnitsMetric interprets measurements in centimetres.
+	MeasurementUnitsMetric MeasurementUnits = "METRIC"
+)
+
+func (u MeasurementUnits) IsValid() bool {
+	switch u {
+	case MeasurementUnitsUS, MeasurementUnitsMetric:
+		return true
+	}
+	return false
+}
+
+// Measurements are the structured form of a measurements string. All sizes
+// are in centimetres. Only the measurements string of a performer is
+// stored, so the structured form is only used for validation.
+type Measurements struct {
+	BandSize *int
+	CupSize  string
+	Waist    *int
+	Hip      *int
+}
+
+var bustRE = regexp.MustCompile(`^(\d+)?\s*([A-Za-z]*)$`)
+
+// ParseMeasurements parses a measurements string of the form
+// "34C-24-34" into its components, interpreting the numeric values in the
+// provided unit system. An empty unit system is treated as
+// MeasurementUnitsUS.
+func ParseMeasurements(s string, units MeasurementUnits) (*Measurements, error) {
+	if units == "" {
+		units = MeasurementUnitsUS
+	}
+	if !units.IsValid() {
+		return nil, fmt.Errorf("invalid measurement units %q", units)
+	}
+
+	parts := strings.Split(s, "-")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid measurements %q: expected bust-waist-hip", s)
+	}
+
+	ret := &Measurements{}
+
+	bust := bustRE.FindStringSubmatch(strings.TrimSpace(parts[0]))
+	if bust == nil {
+		return nil, fmt.Errorf("invalid bust measurement %q", parts[0])
+	}
+
+	var err error
+	if ret.BandSize, err = parseMeasurement(bust[1], units); err != nil {
+		return nil, fmt.Errorf("invalid bust measurement %q: %w", parts[0], err)
+	}
+	ret.CupSize = strings.ToUpper(bust[2])
+
+	if ret.Waist, err = parseMeasurement(parts[1], units); err != nil {
+		return nil, fmt.Errorf("invalid waist measurement %q: %w", parts[1], err)
+	}
+	if ret.Hip, err = parseMeasurement(parts[2], units); err != nil {
+		return nil, fmt.Errorf("invalid hip measurement %q: %w", parts[2], err)
+	}
+
+	return ret, nil
+}
+
+// parseMeasurement parses a single numeric measurement, returning it in
+// centimetres. An empty string returns nil.
+func parseMeasurement(s string, units MeasurementUnits) (*int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if units == MeasurementUnitsUS {
+		v = int(math.Round(float64(v) * 2.54))
+	}
+
+	return &v, nil
+}
@@ -0,0 +1,43 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMeasurements(t *testing.T) {
+	i := func(v int) *int {
+		return &v
+	}
+
+	tests := []struct {
+		name    string
+		s       string
+		units   MeasurementUnits
+		want    *Measurements
+		wantErr bool
+	}{
+		{"us", "34C-24-34", MeasurementUnitsUS, &Measurements{i(86), "C", i(61), i(86)}, false},
+		{"default us", "34-24-34", "", &Measurements{i(86), "", i(61), i(86)}, false},
+		{"metric", "86dd-61-86", MeasurementUnitsMetric, &Measurements{i(86), "DD", i(61), i(86)}, false},
+		{"spaces", "34 C - 24 - 34", MeasurementUnitsUS, &Measurements{i(86), "C", i(61), i(86)}, false},
+		{"cup only", "C-24-34", MeasurementUnitsUS, &Measurements{nil, "C", i(61), i(86)}, false},
+		{"missing waist", "34C--34", MeasurementUnitsUS, &Measurements{i(86), "C", nil, i(86)}, false},
+		{"too few parts", "34C-24", MeasurementUnitsUS, nil, true},
+		{"invalid bust", "C34-24-34", MeasurementUnitsUS, nil, true},
+		{"invalid waist", "34C-abc-34", MeasurementUnitsUS, nil, true},
+		{"invalid units", "34C-24-34", "invalid", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMeasurements(tt.s, tt.units)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseMeasurements() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package performer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stashapp/stash/pkg/models"
+)
+
+type PreviewReader interface {
+	Find(ctx context.Context, id int) (*models.Performer, error)
+	GetImage(ctx context.Context, performerID int) ([]byte, error)
+}
+
+type PreviewAction string
+
+const (
+	PreviewActionCreate PreviewAction = "CREATE"
+	PreviewActionUpdate PreviewAction = "UPDATE"
+	PreviewActionSkip   PreviewAction = "SKIP"
+	PreviewActionFail   PreviewAction = "FAIL"
+)
+
+type PreviewImageAction string
+
+const (
+	// PreviewImageActionSet sets an image on a performer without one.
+	PreviewImageActionSet PreviewImageAction = "SET"
+	// PreviewImageActionChange replaces a different existing image.
+	PreviewImageActionChange PreviewImageAction = "CHANGE"
+)
+
+// FieldChange is the change to a single performer field.
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Preview describes the changes that importing a single performer would
+// make. It is recorded by DryRun imports.
+type Preview struct {
+	Name string `json:"name"`
+	// Action is empty if neither Create nor Update was called.
+	Action PreviewAction `json:"action"`
+	// ID is the ID of the matched existing performer, if any.
+	ID int `json:"id,omitempty"`
+	// Fields contains the changed fields of an updated performer, keyed by
+	// json field name. It is only set by Preview.
+	Fields map[string]FieldChange `json:"fields,omitempty"`
+	// CreateTags are the names of the tags that would be created.
+	CreateTags []string `json:"create_tags,omitempty"`
+	// MatchedTags are the names of the existing tags that would be
+	// associated with the performer.
+	MatchedTags []string `json:"matched_tags,omitempty"`
+	// Image is PreviewImageActionSet if the performer image would be set.
+	// Preview distinguishes images that would replace a different image
+	// using PreviewImageActionChange, and omits unchanged images.
+	Image    PreviewImageAction `json:"image,omitempty"`
+	Warnings []string           `json:"warnings,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// BatchPreview is the consolidated preview of a batch of performer imports.
+type BatchPreview struct {
+	Performers []Preview `json:"performers"`
+	// CreateTags is the sorted set of tags that would be created by the
+	// batch.
+	CreateTags []string `json:"create_tags,omitempty"`
+}
+
+// PreviewBatch runs each of the importers in dry-run mode, returning the
+// changes that would be made without writing anything. Errors in individual
+// importers are recorded in the preview and do not stop the batch.
+func PreviewBatch(ctx context.Context, reader PreviewReader, importers []*Importer) (*BatchPreview, error) {
+	ret := &BatchPreview{
+		Performers: []Preview{},
+	}
+	createTags := make(map[string]bool)
+
+	for _, i := range importers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		p := i.Preview(ctx, reader)
+		for _, t := range p.CreateTags {
+			createTags[t] = true
+		}
+
+		ret.Performers = append(ret.Performers, *p)
+	}
+
+	for t := range createTags {
+		ret.CreateTags = append(ret.CreateTags, t)
+	}
+	sort.Strings(ret.CreateTags)
+
+	return ret, nil
+}
+
+// Preview runs the importer as a DryRun import and returns the changes that
+// importing the performer would make. Tags are not created and no data is
+// written. Unlike Plan, the fields and image of an updated performer are
+// compared with the existing performer read using reader.
+func (i *Importer) Preview(ctx context.Context, reader PreviewReader) *Preview {
+	dryRun := i.DryRun
+	i.DryRun = true
+	defer func() {
+		i.DryRun = dryRun
+	}()
+
+	err := i.preview(ctx, reader)
+
+	ret := i.Plan()
+	if err != nil {
+		ret.Action = PreviewActionFail
+		ret.Error = err.Error()
+	}
+
+	return &ret
+}
+
+// Plan returns the changes recorded by a DryRun import.
+func (i *Importer) Plan() Preview {
+	ret := i.plan
+	ret.Name = i.Name()
+	ret.CreateTags = append([]string(nil), i.missingTags...)
+	ret.Warnings = i.Warnings()
+
+	for _, t := range i.tags {
+		ret.MatchedTags = append(ret.MatchedTags, t.Name)
+	}
+
+	return ret
+}
+
+func (i *Importer) preview(ctx context.Context, reader PreviewReader) error {
+	if err := i.PreImport(ctx); err != nil {
+		return err
+	}
+
+	id, err := i.FindExistingID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if id == nil {
+		created, err := i.Create(ctx)
+		if err != nil {
+			return err
+		}
+
+		return i.PostImport(ctx, *created)
+	}
+
+	if err := i.Update(ctx, *id); err != nil {
+		return err
+	}
+
+	if err := i.PostImport(ctx, *id); err != nil {
+		return err
+	}
+
+	if i.skipped {
+		return nil
+	}
+
+	existing, err := reader.Find(ctx, *id)
+	if err != nil {
+		return fmt.Errorf("error finding performer: %v", err)
+	}
+	if existing == nil {
+		return fmt.Errorf("performer with id %d not found", *id)
+	}
+
+	i.plan.Fields = i.fieldChanges(*existing)
+
+	i.plan.Image, err = i.imageAction(ctx, reader, *id)
+	return err
+}
+
+// fieldChanges returns the fields of existing that would be changed by the
+// import. If the update is restricted to a set of fields, only those fields
+// are considered.
+func (i *Importer) fieldChanges(existing models.Performer) map[string]FieldChange {
+	performer := i.keepExistingGender(i.performer, existing)
+	if i.merging() {
+		performer = i.mergeInput(existing, performer)
+	}
+
+	oldValues := previewFieldValues(existing)
+	newValues := previewFieldValues(performer)
+	mask := i.updateMask()
+
+	ret := make(map[string]FieldChange)
+	for name, newValue := range newValues {
+		if mask != nil && !mask[name] {
+			continue
+		}
+
+		if oldValue := oldValues[name]; oldValue != newValue {
+			ret[name] = FieldChange{
+				Old: oldValue,
+				New: newValue,
+			}
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil
+	}
+
+	return ret
+}
+
+func (i *Importer) imageAction(ctx context.Context, reader PreviewReader, id int) (PreviewImageAction, error) {
+	if i.image == "" && len(i.imageData) == 0 {
+		return "", nil
+	}
+
+	imageData := i.imageData
+	if imageData == nil {
+		var err error
+		imageData, err = i.ImageLimits.decodeImage(i.image)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	existing, err := reader.GetImage(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("error getting performer image: %v", err)
+	}
+
+	switch {
+	case len(existing) == 0:
+		return PreviewImageActionSet, nil
+	case !bytes.Equal(existing, imageData):
+		return PreviewImageActionChange, nil
+	}
+
+	return "", nil
+}
+
+// previewFieldValues returns the string values of the fields of p, keyed by
+// json field name. Fields that are not set by import are omitted.
+func previewFieldValues(p models.Performer) map[string]string {
+	ret := make(map[string]string)
+
+	v := reflect.ValueOf(p)
+	t := v.Type()
+	for f := 0; f < t.NumField(); f++ {
+		name := strings.Split(t.Field(f).Tag.Get("json"), ",")[0]
+		switch name {
+		// custom fields are not loaded with the performer
+		case "", "-", "id", "checksum", "updated_at", "custom_fields":
+			continue
+		}
+
+		ret[name] = previewValue(v.Field(f))
+	}
+
+	return ret
+}
+
+func previewValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch vv := v.Interface().(type) {
+	case time.Time:
+		return vv.Format(time.RFC3339)
+	case fmt.Stringer:
+		return vv.String()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
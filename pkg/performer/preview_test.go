@@ -0,0 +1,98 @@
+package performer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPreviewBatch(t *testing.T) {
+	const newURL = "newURL"
+
+	readerWriter := &mocks.PerformerReaderWriter{}
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	newImporter := func(input jsonschema.Performer) *Importer {
+		return &Importer{
+			ReaderWriter:        readerWriter,
+			TagWriter:           tagReaderWriter,
+			MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+			Input:               input,
+		}
+	}
+
+	existing := createFullPerformer(existingPerformerID, existingPerformerName)
+	existingInput := createFullJSONPerformer(existingPerformerName, image)
+	existingInput.URL = newURL
+
+	importers := []*Importer{
+		newImporter(jsonschema.Performer{
+			Name:  performerName,
+			Image: image,
+			Tags:  []string{missingTagName},
+		}),
+		newImporter(*existingInput),
+		newImporter(jsonschema.Performer{
+			Name: performerNameErr,
+		}),
+	}
+
+	readerWriter.On("FindByStashID", testCtx, stashID).Return(nil, nil).Once()
+	readerWriter.On("FindByNames", testCtx, []string{performerName}, false).Return(nil, nil).Once()
+	readerWriter.On("FindByNames", testCtx, []string{existingPerformerName}, false).Return([]*models.Performer{
+		existing,
+	}, nil).Once()
+	readerWriter.On("FindByNames", testCtx, []string{performerNameErr}, false).Return(nil, errors.New("FindByNames error")).Once()
+	readerWriter.On("Find", testCtx, existingPerformerID).Return(existing, nil).Once()
+	readerWriter.On("GetImage", testCtx, existingPerformerID).Return([]byte("otherImage"), nil).Once()
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{missingTagName}, false).Return(nil, nil).Once()
+	tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil).Once()
+
+	got, err := PreviewBatch(testCtx, readerWriter, importers)
+	assert.Nil(t, err)
+
+	if !assert.Len(t, got.Performers, 3) {
+		return
+	}
+
+	created := got.Performers[0]
+	assert.Equal(t, PreviewActionCreate, created.Action)
+	assert.Equal(t, []string{missingTagName}, created.CreateTags)
+	assert.Equal(t, PreviewImageActionSet, created.Image)
+
+	updated := got.Performers[1]
+	assert.Equal(t, PreviewActionUpdate, updated.Action)
+	assert.Equal(t, existingPerformerID, updated.ID)
+	assert.Equal(t, map[string]FieldChange{
+		"url": {
+			Old: url,
+			New: newURL,
+		},
+		"urls": {
+			Old: fmt.Sprint(urls),
+			New: fmt.Sprint(append([]string{newURL}, urls...)),
+		},
+	}, updated.Fields)
+	assert.Equal(t, PreviewImageActionChange, updated.Image)
+
+	failed := got.Performers[2]
+	assert.Equal(t, PreviewActionFail, failed.Action)
+	assert.NotEmpty(t, failed.Error)
+
+	assert.Equal(t, []string{missingTagName}, got.CreateTags)
+
+	_, err = json.Marshal(got)
+	assert.Nil(t, err)
+
+	// nothing should have been written
+	readerWriter.AssertExpectations(t)
+	tagReaderWriter.AssertExpectations(t)
+}
@@ -0,0 +1,61 @@
+package performer
+
+// RatingScale is the maximum rating of a rating scale. Ratings range from 1
+// to the maximum, with 0 meaning unset.
+type RatingScale int
+
+const (
+	// RatingScale5 is the internal rating scale.
+	RatingScale5 RatingScale = 5
+	// RatingScale100 is the scale of exports using percentage ratings.
+	RatingScale100 RatingScale = 100
+)
+
+func (s RatingScale) orDefault() RatingScale {
+	if s <= 0 {
+		return RatingScale5
+	}
+	return s
+}
+
+// ConvertRating converts a rating on the scale to the internal scale,
+// rounding to the nearest value from 1 to 5. Ratings outside of the scale
+// are clamped to it, in which case clamped is true. A rating of 0 is unset,
+// and is returned unchanged.
+func ConvertRating(rating int, scale RatingScale) (converted int, clamped bool) {
+	scale = scale.orDefault()
+
+	switch {
+	case rating == 0:
+		return 0, false
+	case rating < 1:
+		rating = 1
+		clamped = true
+	case rating > int(scale):
+		rating = int(scale)
+		clamped = true
+	}
+
+	max := int(RatingScale5)
+	converted = (rating*max + int(scale)/2) / int(scale)
+	if converted < 1 {
+		converted = 1
+	}
+
+	return converted, clamped
+}
+
+// performerRating returns the internal rating of the input, or nil if the
+// rating is unset. As in the stored rating, 0 means unset.
+func (i *Importer) performerRating(rating int) *int {
+	if rating == 0 {
+		return nil
+	}
+
+	converted, clamped := ConvertRating(rating, i.RatingScale)
+	if clamped {
+		i.addWarning("rating %d out of range 1-%d, using %d", rating, i.RatingScale.orDefault(), converted)
+	}
+
+	return &converted
+}
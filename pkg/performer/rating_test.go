@@ -0,0 +1,122 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConvertRating(t *testing.T) {
+	tests := []struct {
+		name        string
+		rating      int
+		scale       RatingScale
+		want        int
+		wantClamped bool
+	}{
+		{"5 scale min", 1, RatingScale5, 1, false},
+		{"5 scale", 3, RatingScale5, 3, false},
+		{"5 scale max", 5, RatingScale5, 5, false},
+		{"default scale", 4, 0, 4, false},
+		{"100 scale", 80, RatingScale100, 4, false},
+		{"100 scale rounds up", 90, RatingScale100, 5, false},
+		{"100 scale min", 1, RatingScale100, 1, false},
+		{"100 scale max", 100, RatingScale100, 5, false},
+		{"other scale rounds", 7, 9, 4, false},
+		{"zero", 0, RatingScale100, 0, false},
+		{"5 scale too high", 80, RatingScale5, 5, true},
+		{"100 scale too high", 101, RatingScale100, 5, true},
+		{"negative", -1, RatingScale100, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, clamped := ConvertRating(tt.rating, tt.scale)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantClamped, clamped)
+		})
+	}
+}
+
+func TestImporterPreImportRating(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        jsonschema.Performer
+		scale        RatingScale
+		want         *int
+		wantClear    bool
+		wantWarnings int
+	}{
+		{"5 scale", jsonschema.Performer{Name: performerName, Rating: 4}, RatingScale5, intPtr(4), false, 0},
+		{"default scale", jsonschema.Performer{Name: performerName, Rating: 4}, 0, intPtr(4), false, 0},
+		{"100 scale", jsonschema.Performer{Name: performerName, Rating: 45}, RatingScale100, intPtr(2), false, 0},
+		{"clamped", jsonschema.Performer{Name: performerName, Rating: 45}, RatingScale5, intPtr(5), false, 1},
+		{"unset", jsonschema.Performer{Name: performerName}, RatingScale5, nil, false, 0},
+		{"absent", loadJSONPerformer(t, `{"name": "name"}`), RatingScale100, nil, false, 0},
+		// 0 is unset, as in the stored rating, but clears a merged rating
+		{"explicit zero", loadJSONPerformer(t, `{"name": "name", "rating": 0}`), RatingScale100, nil, true, 0},
+		{"null", loadJSONPerformer(t, `{"name": "name", "rating": null}`), RatingScale100, nil, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := Importer{
+				Input:       tt.input,
+				RatingScale: tt.scale,
+			}
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, i.performer.Rating)
+			assert.Equal(t, tt.wantClear, i.clearRating)
+			assert.Len(t, i.Warnings(), tt.wantWarnings)
+		})
+	}
+}
+
+func TestImporterUpdateMergeRating(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *int
+	}{
+		{"absent keeps rating", `{"name": "name"}`, intPtr(rating)},
+		{"explicit zero clears rating", `{"name": "name", "rating": 0}`, nil},
+		{"set replaces rating", `{"name": "name", "rating": 1}`, intPtr(1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+
+			existing := models.Performer{
+				ID:     performerID,
+				Name:   "name",
+				Rating: intPtr(rating),
+			}
+
+			i := Importer{
+				ReaderWriter:  readerWriter,
+				MergeStrategy: MergeStrategyMerge,
+				Input:         loadJSONPerformer(t, tt.input),
+			}
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+
+			readerWriter.On("Find", testCtx, performerID).Return(&existing, nil).Once()
+			readerWriter.On("Update", testCtx, mock.MatchedBy(func(p *models.Performer) bool {
+				return assert.ObjectsAreEqual(tt.want, p.Rating)
+			})).Return(nil).Once()
+
+			err = i.Update(testCtx, performerID)
+			assert.Nil(t, err)
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
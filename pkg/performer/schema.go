@@ -0,0 +1,106 @@
+package performer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const schemaResourceURL = "performer.schema.json"
+
+// SchemaViolation is a single violation of a JSON Schema.
+type SchemaViolation struct {
+	// Pointer is the JSON pointer of the invalid value in the record.
+	Pointer string
+	Message string
+}
+
+func (v SchemaViolation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s", pointer, v.Message)
+}
+
+// SchemaValidationError is returned when a record does not conform to the
+// schema. It contains all violations of the record.
+type SchemaValidationError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	var s []string
+	for _, v := range e.Violations {
+		s = append(s, v.String())
+	}
+	return fmt.Sprintf("record does not match schema: %s", strings.Join(s, "; "))
+}
+
+// SchemaValidator validates performer records against a JSON Schema
+// document. Schemas default to draft 2020-12 unless they declare otherwise
+// using $schema.
+type SchemaValidator struct {
+	schema *jsonschema.Schema
+}
+
+// NewSchemaValidator compiles the provided JSON Schema document.
+func NewSchemaValidator(schema []byte) (*SchemaValidator, error) {
+	c := jsonschema.NewCompiler()
+	c.Draft = jsonschema.Draft2020
+
+	if err := c.AddResource(schemaResourceURL, bytes.NewReader(schema)); err != nil {
+		return nil, fmt.Errorf("error reading schema: %w", err)
+	}
+
+	compiled, err := c.Compile(schemaResourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling schema: %w", err)
+	}
+
+	return &SchemaValidator{
+		schema: compiled,
+	}, nil
+}
+
+// Validate validates the JSON encoded record. It returns a
+// *SchemaValidationError if the record does not conform to the schema.
+func (v *SchemaValidator) Validate(record []byte) error {
+	d := json.NewDecoder(bytes.NewReader(record))
+	d.UseNumber()
+
+	var doc interface{}
+	if err := d.Decode(&doc); err != nil {
+		return fmt.Errorf("error decoding record: %w", err)
+	}
+
+	err := v.schema.Validate(doc)
+	var ve *jsonschema.ValidationError
+	if errors.As(err, &ve) {
+		ret := &SchemaValidationError{}
+		collectViolations(ve, ret)
+		return ret
+	}
+
+	return err
+}
+
+// collectViolations adds the leaf errors of ve to ret. Errors with causes
+// only summarise their causes, so are omitted.
+func collectViolations(ve *jsonschema.ValidationError, ret *SchemaValidationError) {
+	if len(ve.Causes) == 0 {
+		ret.Violations = append(ret.Violations, SchemaViolation{
+			Pointer: ve.InstanceLocation,
+			Message: ve.Message,
+		})
+		return
+	}
+
+	for _, c := range ve.Causes {
+		collectViolations(c, ret)
+	}
+}
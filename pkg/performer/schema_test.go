@@ -0,0 +1,69 @@
+package performer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPerformerSchema = `{
+	"type": "object",
+	"required": ["name", "gender"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"gender": {"enum": ["MALE", "FEMALE"]},
+		"rating": {"type": "integer", "maximum": 5},
+		"favorite": {"type": "boolean"}
+	},
+	"additionalProperties": false
+}`
+
+func TestSchemaValidatorValidate(t *testing.T) {
+	v, err := NewSchemaValidator([]byte(testPerformerSchema))
+	if err != nil {
+		t.Fatalf("NewSchemaValidator() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		record   string
+		pointers []string
+		wantErr  bool
+	}{
+		{"valid", `{"name": "name", "gender": "FEMALE", "rating": 5}`, nil, false},
+		{"missing required", `{"name": "name"}`, []string{""}, true},
+		{"multiple violations", `{"name": "", "gender": "invalid", "rating": 6.5}`, []string{"/gender", "/name", "/rating"}, true},
+		{"unknown property", `{"name": "name", "gender": "FEMALE", "unknown": true}`, []string{""}, true},
+		{"wrongly typed value", `{"name": "name", "gender": "FEMALE", "rating": "5"}`, []string{"/rating"}, true},
+		{"wrongly typed zero value", `{"name": "name", "gender": "FEMALE", "favorite": 0}`, []string{"/favorite"}, true},
+		{"invalid json", `{"name":`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate([]byte(tt.record))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SchemaValidator.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var ve *SchemaValidationError
+			if !errors.As(err, &ve) {
+				assert.Nil(t, tt.pointers)
+				return
+			}
+
+			var pointers []string
+			for _, violation := range ve.Violations {
+				pointers = append(pointers, violation.Pointer)
+			}
+			assert.ElementsMatch(t, tt.pointers, pointers)
+		})
+	}
+}
+
+func TestNewSchemaValidatorInvalid(t *testing.T) {
+	_, err := NewSchemaValidator([]byte(`{"type": "invalid"}`))
+	assert.NotNil(t, err)
+}
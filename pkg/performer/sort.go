@@ -0,0 +1,22 @@
+package performer
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// CanonicalSortNameLocale returns the canonical form of the provided BCP 47
+// language tag. An empty locale is returned unchanged.
+func CanonicalSortNameLocale(locale string) (string, error) {
+	if locale == "" {
+		return "", nil
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", fmt.Errorf("invalid sort name locale %q: %w", locale, err)
+	}
+
+	return tag.String(), nil
+}
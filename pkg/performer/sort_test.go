@@ -0,0 +1,31 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalSortNameLocale(t *testing.T) {
+	tests := []struct {
+		locale  string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"sv", "sv", false},
+		{"SV-se", "sv-SE", false},
+		{"not a locale", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			got, err := CanonicalSortNameLocale(tt.locale)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CanonicalSortNameLocale() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package performer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+)
+
+// tagParents returns the names of the parents of each of the created tags,
+// keyed by tag name. Parents are taken from the input TagParents, with
+// TagPrefix applied, and default to DefaultTagParent.
+func (i *Importer) tagParents(created []*models.Tag) map[string][]string {
+	inputParents := make(map[string][]string)
+	for name, parents := range i.Input.TagParents {
+		inputParents[i.TagPrefix+name] = i.prefixTags(parents)
+	}
+
+	ret := make(map[string][]string)
+	for _, t := range created {
+		parents := inputParents[t.Name]
+		if len(parents) == 0 && i.DefaultTagParent != "" {
+			parents = []string{i.DefaultTagParent}
+		}
+
+		if len(parents) > 0 {
+			ret[t.Name] = parents
+		}
+	}
+
+	return ret
+}
+
+// setCreatedTagParents sets the parents of the tags created during import.
+// Parents may be other created tags or existing tags. Missing parents are
+// handled according to TagParentMissingRefBehaviour.
+func (i *Importer) setCreatedTagParents(ctx context.Context, created []*models.Tag) error {
+	parents := i.tagParents(created)
+	if len(parents) == 0 {
+		return nil
+	}
+
+	if err := checkTagParentCycles(parents); err != nil {
+		return err
+	}
+
+	ids := make(map[string]int)
+	for _, t := range created {
+		ids[t.Name] = t.ID
+	}
+
+	if err := i.resolveTagParents(ctx, created, parents, ids); err != nil {
+		return err
+	}
+
+	for _, t := range created {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var parentIDs []int
+		for _, parent := range parents[t.Name] {
+			if id, found := ids[parent]; found {
+				parentIDs = append(parentIDs, id)
+			}
+		}
+
+		if len(parentIDs) == 0 {
+			continue
+		}
+
+		if err := i.TagWriter.UpdateParentTags(ctx, t.ID, parentIDs); err != nil {
+			return fmt.Errorf("error setting parents of tag %q: %v", t.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// resolveTagParents adds the IDs of the parents that were not created to
+// ids. Parents that do not exist are created, ignored or returned as an
+// error according to TagParentMissingRefBehaviour.
+func (i *Importer) resolveTagParents(ctx context.Context, created []*models.Tag, parents map[string][]string, ids map[string]int) error {
+	var names []string
+	for _, t := range created {
+		for _, parent := range parents[t.Name] {
+			if _, found := ids[parent]; !found {
+				names = stringslice.StrAppendUnique(names, parent)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	existing, err := i.TagWriter.FindByNames(ctx, names, false)
+	if err != nil {
+		return fmt.Errorf("error finding parent tags: %v", err)
+	}
+
+	for _, t := range existing {
+		ids[t.Name] = t.ID
+	}
+
+	var missing []string
+	for _, name := range names {
+		if _, found := ids[name]; !found {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	switch i.TagParentMissingRefBehaviour {
+	case models.ImportMissingRefEnumCreate:
+		createdParents, err := createTags(ctx, i.TagWriter, missing, nil)
+		if err != nil {
+			return fmt.Errorf("error creating parent tags: %w", err)
+		}
+
+		for _, t := range createdParents {
+			ids[t.Name] = t.ID
+		}
+		i.changes.TagsCreated += len(createdParents)
+	case models.ImportMissingRefEnumIgnore:
+		for _, name := range missing {
+			i.addWarning("ignoring missing parent tag %q", name)
+		}
+	default:
+		return fmt.Errorf("parent tags [%s] not found", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// checkTagParentCycles returns an error if the parents of the created tags
+// form a cycle. Existing tags cannot have a created tag as an ancestor, so
+// only the created tags are checked.
+func checkTagParentCycles(parents map[string][]string) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("tag parents contain a cycle at %q", name)
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		for _, parent := range parents[name] {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+
+		return nil
+	}
+
+	for name := range parents {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package performer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImporterPreImportTagParents(t *testing.T) {
+	const (
+		childName  = "Child"
+		otherName  = "Other"
+		parentName = "Parent"
+		childID    = 201
+		otherID    = 202
+		parentID   = 203
+	)
+
+	ids := map[string]int{
+		childName:  childID,
+		otherName:  otherID,
+		parentName: parentID,
+	}
+
+	tests := []struct {
+		name             string
+		tags             []string
+		tagParents       map[string][]string
+		defaultParent    string
+		parentBehaviour  models.ImportMissingRefEnum
+		parentExists     bool
+		wantParents      map[int][]int
+		wantCreateParent bool
+		wantErr          bool
+		wantWarning      bool
+	}{
+		{
+			name:          "default parent",
+			tags:          []string{childName},
+			defaultParent: parentName,
+			parentExists:  true,
+			wantParents:   map[int][]int{childID: {parentID}},
+		},
+		{
+			name:         "input parent",
+			tags:         []string{childName, otherName},
+			tagParents:   map[string][]string{childName: {parentName}},
+			parentExists: true,
+			wantParents:  map[int][]int{childID: {parentID}},
+		},
+		{
+			name:          "input parent overrides default",
+			tags:          []string{childName},
+			tagParents:    map[string][]string{childName: {otherName}},
+			defaultParent: parentName,
+			parentExists:  true,
+			wantParents:   map[int][]int{childID: {otherID}},
+		},
+		{
+			name:        "created parent",
+			tags:        []string{childName, parentName},
+			tagParents:  map[string][]string{childName: {parentName}},
+			wantParents: map[int][]int{childID: {parentID}},
+		},
+		{
+			name:          "missing parent fail",
+			tags:          []string{childName},
+			defaultParent: parentName,
+			wantErr:       true,
+		},
+		{
+			name:            "missing parent ignore",
+			tags:            []string{childName},
+			defaultParent:   parentName,
+			parentBehaviour: models.ImportMissingRefEnumIgnore,
+			wantWarning:     true,
+		},
+		{
+			name:             "missing parent create",
+			tags:             []string{childName},
+			defaultParent:    parentName,
+			parentBehaviour:  models.ImportMissingRefEnumCreate,
+			wantCreateParent: true,
+			wantParents:      map[int][]int{childID: {parentID}},
+		},
+		{
+			name:       "cycle",
+			tags:       []string{childName, parentName},
+			tagParents: map[string][]string{childName: {parentName}, parentName: {childName}},
+			wantErr:    true,
+		},
+		{
+			name:       "own parent",
+			tags:       []string{childName},
+			tagParents: map[string][]string{childName: {childName}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagReaderWriter := &mocks.TagReaderWriter{}
+
+			i := Importer{
+				TagWriter:                    tagReaderWriter,
+				MissingRefBehaviour:          models.ImportMissingRefEnumCreate,
+				DefaultTagParent:             tt.defaultParent,
+				TagParentMissingRefBehaviour: tt.parentBehaviour,
+				Input: jsonschema.Performer{
+					Name:       performerName,
+					Tags:       tt.tags,
+					TagParents: tt.tagParents,
+				},
+			}
+
+			tagReaderWriter.On("FindByNames", testCtx, tt.tags, false).Return(nil, nil).Once()
+			tagReaderWriter.On("Query", testCtx, mock.Anything, mock.Anything).Return(nil, 0, nil)
+			tagReaderWriter.On("Create", testCtx, mock.AnythingOfType("models.Tag")).Return(func(_ context.Context, newTag models.Tag) *models.Tag {
+				newTag.ID = ids[newTag.Name]
+				return &newTag
+			}, nil)
+
+			var existing []*models.Tag
+			if tt.parentExists {
+				for name, id := range ids {
+					existing = append(existing, &models.Tag{ID: id, Name: name})
+				}
+			}
+			tagReaderWriter.On("FindByNames", testCtx, mock.Anything, false).Return(existing, nil).Maybe()
+
+			for id, parentIDs := range tt.wantParents {
+				tagReaderWriter.On("UpdateParentTags", testCtx, id, parentIDs).Return(nil).Once()
+			}
+
+			err := i.PreImport(testCtx)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			assert.Equal(t, tt.wantWarning, len(i.Warnings()) > 0)
+
+			createdParent := false
+			for _, c := range tagReaderWriter.Calls {
+				switch c.Method {
+				case "Create":
+					if c.Arguments.Get(1).(models.Tag).Name == parentName && !stringslice.StrInclude(tt.tags, parentName) {
+						createdParent = true
+					}
+				case "UpdateParentTags":
+					assert.Contains(t, tt.wantParents, c.Arguments.Int(1))
+				}
+			}
+			assert.Equal(t, tt.wantCreateParent, createdParent)
+
+			tagReaderWriter.AssertExpectations(t)
+		})
+	}
+}
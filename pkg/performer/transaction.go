@@ -0,0 +1,79 @@
+package performer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/txn"
+)
+
+// Import imports the performer in a transaction begun using m, creating it
+// or updating the existing performer. All reads and writes, including
+// those of PostImport, are made in the transaction, so a failure in any
+// stage rolls back the whole performer, including any tags created for it.
+// The Batch records of a rolled back performer are released, so that later
+// records do not resolve to it or to the tags created for it. Errors recorded under ContinueOnError do not
+// roll back the transaction, and are returned once it has been committed.
+//
+// Images deferred to the ImageQueue are only queued once the transaction
+// has been committed. If m is nil, the performer is imported in the
+// transaction of ctx, which is left to the caller to commit or roll back.
+func (i *Importer) Import(ctx context.Context, m txn.Manager) error {
+	return i.WithTxn(ctx, m, func(ctx context.Context) error {
+		_, err := models.PerformImport(ctx, i, models.ImportDuplicateEnumOverwrite)
+		return err
+	})
+}
+
+// WithTxn calls fn, which imports the performer using the importer, in a
+// transaction begun using m, as Import does. It allows callers to drive the
+// import stages themselves.
+func (i *Importer) WithTxn(ctx context.Context, m txn.Manager, fn txn.TxnFunc) error {
+	if m == nil {
+		return fn(ctx)
+	}
+
+	i.claimedID = 0
+	i.createdTagNames = nil
+
+	var partialErr error
+	err := txn.WithTxn(ctx, m, func(ctx context.Context) error {
+		err := fn(ctx)
+
+		var importErrs ImportErrors
+		if errors.As(err, &importErrs) {
+			partialErr = err
+			return nil
+		}
+
+		return err
+	})
+
+	if err != nil {
+		i.release()
+		return err
+	}
+
+	return partialErr
+}
+
+// release removes the Batch records made by the importer, once its
+// transaction has been rolled back.
+func (i *Importer) release() {
+	if i.Batch == nil {
+		return
+	}
+
+	name := i.Name()
+	if i.claimedID != 0 {
+		i.Batch.release(i.claimedID, name, i.performer.Disambiguation)
+	}
+	if i.changes.Created && i.changes.ID != i.claimedID {
+		i.Batch.release(i.changes.ID, name, i.performer.Disambiguation)
+	}
+	i.Batch.forgetTags(i.createdTagNames)
+
+	i.claimedID = 0
+	i.createdTagNames = nil
+}
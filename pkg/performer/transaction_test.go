@@ -0,0 +1,103 @@
+package performer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingTxnManager counts the committed and rolled back transactions.
+type recordingTxnManager struct {
+	mocks.TxnManager
+	commits   int
+	rollbacks int
+}
+
+func (m *recordingTxnManager) Commit(ctx context.Context) error {
+	m.commits++
+	return nil
+}
+
+func (m *recordingTxnManager) Rollback(ctx context.Context) error {
+	m.rollbacks++
+	return nil
+}
+
+// failingImageWriter is a countingPerformerWriter that fails to set
+// performer images.
+type failingImageWriter struct {
+	*countingPerformerWriter
+}
+
+func (w failingImageWriter) UpdateImage(ctx context.Context, performerID int, image []byte) error {
+	return errors.New("UpdateImage error")
+}
+
+func TestImporterImport(t *testing.T) {
+	m := &recordingTxnManager{}
+	performerWriter := newCountingPerformerWriter()
+	batch := &Batch{
+		DuplicateRecordPolicy: DuplicateRecordPolicyFail,
+	}
+
+	i := Importer{
+		ReaderWriter: failingImageWriter{performerWriter},
+		TagWriter:    &countingTagWriter{},
+		Batch:        batch,
+		Input: jsonschema.Performer{
+			Name:  performerName,
+			Image: image,
+		},
+	}
+
+	err := i.Import(testCtx, m)
+	assert.ErrorContains(t, err, "UpdateImage error")
+	assert.Equal(t, 0, m.commits)
+	assert.Equal(t, 1, m.rollbacks)
+
+	// the rolled back performer is released from the batch
+	assert.Empty(t, batch.claimed)
+	assert.Empty(t, batch.createdIDs(performerName))
+
+	i = Importer{
+		ReaderWriter: performerWriter,
+		TagWriter:    &countingTagWriter{},
+		Batch:        batch,
+		Input: jsonschema.Performer{
+			Name: performerName,
+		},
+	}
+
+	// the fake store does not roll back, so the performer is found
+	err = i.Import(testCtx, m)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, m.commits)
+	assert.Equal(t, 1, m.rollbacks)
+}
+
+func TestImporterImportContinueOnError(t *testing.T) {
+	m := &recordingTxnManager{}
+	performerWriter := newCountingPerformerWriter()
+
+	i := Importer{
+		ReaderWriter:    failingImageWriter{performerWriter},
+		TagWriter:       &countingTagWriter{},
+		ContinueOnError: true,
+		Input: jsonschema.Performer{
+			Name:  performerName,
+			Image: image,
+		},
+	}
+
+	err := i.Import(testCtx, m)
+
+	var importErrs ImportErrors
+	assert.ErrorAs(t, err, &importErrs)
+	assert.Equal(t, 1, m.commits)
+	assert.Equal(t, 0, m.rollbacks)
+	assert.Contains(t, performerWriter.performers, performerName)
+}
@@ -0,0 +1,178 @@
+package performer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/stashapp/stash/pkg/hash/md5"
+	"github.com/stashapp/stash/pkg/models"
+)
+
+// matchesExisting returns true if updating the existing performer would not
+// change it. The related data is compared with the data that PostImport
+// would write.
+func (i *Importer) matchesExisting(ctx context.Context, id int, existing models.Performer) (bool, error) {
+	if len(i.fieldChanges(existing)) > 0 {
+		return false, nil
+	}
+
+	return i.relatedMatch(ctx, id)
+}
+
+// relatedMatch returns true if the related data that PostImport would write
+// for the existing performer matches its current related data.
+func (i *Importer) relatedMatch(ctx context.Context, id int) (bool, error) {
+	// the related data is written as for an updated performer
+	updated := i.updated
+	i.updated = true
+	defer func() {
+		i.updated = updated
+	}()
+
+	checks := []func(ctx context.Context, id int) (bool, error){
+		i.tagsMatch,
+		i.stashIDsMatch,
+		i.imageMatches,
+		i.attachmentsMatch,
+		i.customFieldsMatch,
+	}
+
+	for _, check := range checks {
+		match, err := check(ctx, id)
+		if err != nil || !match {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (i *Importer) tagsMatch(ctx context.Context, id int) (bool, error) {
+	tagIDs, set, err := i.tagIDsToSet(ctx, id)
+	if err != nil || !set {
+		return err == nil, err
+	}
+
+	existing, err := i.ReaderWriter.GetTagIDs(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to get existing tags: %v", err)
+	}
+
+	return sameIDs(existing, tagIDs), nil
+}
+
+// sameIDs returns true if a and b contain the same IDs, in any order.
+func sameIDs(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = append([]int(nil), a...)
+	b = append([]int(nil), b...)
+	sort.Ints(a)
+	sort.Ints(b)
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (i *Importer) stashIDsMatch(ctx context.Context, id int) (bool, error) {
+	if !i.setsStashIDs() {
+		return true, nil
+	}
+
+	existing, err := i.ReaderWriter.GetStashIDs(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("error getting stash ids: %v", err)
+	}
+
+	stashIDs := mergeStashIDs(nil, i.Input.StashIDs)
+	if i.stashIDPolicy() == StashIDPolicyMerge {
+		stashIDs = mergeStashIDs(existing, stashIDs)
+	}
+
+	return stashIDsEqual(existing, stashIDs), nil
+}
+
+// imageMatches compares the checksums of the input and existing images.
+func (i *Importer) imageMatches(ctx context.Context, id int) (bool, error) {
+	if len(i.image) == 0 && len(i.imageData) == 0 {
+		return true, nil
+	}
+
+	// queued images are only decoded when they are written
+	if len(i.imageData) == 0 {
+		return false, nil
+	}
+
+	existing, err := i.ReaderWriter.GetImage(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("error getting performer image: %v", err)
+	}
+
+	if len(existing) == 0 {
+		return false, nil
+	}
+
+	// the existing image is kept when merging
+	if i.merging() && i.mergeRelationships() == models.RelationshipUpdateModeAdd {
+		return true, nil
+	}
+
+	return md5.FromBytes(existing) == md5.FromBytes(i.imageData), nil
+}
+
+func (i *Importer) attachmentsMatch(ctx context.Context, id int) (bool, error) {
+	if !i.setsAttachments() {
+		return true, nil
+	}
+
+	existing, err := i.ReaderWriter.GetAttachments(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("error getting performer attachments: %v", err)
+	}
+
+	if len(existing) != len(i.attachments) {
+		return false, nil
+	}
+
+	for n, a := range existing {
+		b := i.attachments[n]
+		if a.Name != b.Name || a.ContentType != b.ContentType || !bytes.Equal(a.Data, b.Data) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (i *Importer) customFieldsMatch(ctx context.Context, id int) (bool, error) {
+	fields, set, err := i.customFieldsToSet(ctx, id)
+	if err != nil || !set {
+		return err == nil, err
+	}
+
+	existing, err := i.ReaderWriter.GetCustomFields(ctx, id)
+	if err != nil {
+		return false, fmt.Errorf("error getting performer custom fields: %v", err)
+	}
+
+	if len(existing) != len(fields) {
+		return false, nil
+	}
+
+	for k, v := range fields {
+		if existingValue, found := existing[k]; !found || existingValue != v {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,132 @@
+package performer
+
+import (
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockExistingPerformer sets the reads of the existing full performer, with
+// the existing tag, the input image and the custom fields.
+func mockExistingPerformer(readerWriter *mocks.PerformerReaderWriter, tagReaderWriter *mocks.TagReaderWriter) {
+	readerWriter.On("Find", testCtx, performerID).Return(createFullPerformer(performerID, performerName), nil).Once()
+	readerWriter.On("GetTagIDs", testCtx, performerID).Return([]int{existingTagID}, nil).Once()
+	readerWriter.On("GetStashIDs", testCtx, performerID).Return([]models.StashID{stashID}, nil).Once()
+	readerWriter.On("GetImage", testCtx, performerID).Return(imageBytes, nil).Once()
+	readerWriter.On("GetCustomFields", testCtx, performerID).Return(customFields, nil).Once()
+
+	tagReaderWriter.On("FindByNames", testCtx, []string{existingTagName}, false).Return([]*models.Tag{
+		{
+			ID:   existingTagID,
+			Name: existingTagName,
+		},
+	}, nil).Once()
+}
+
+func TestImporterUpdateSkipUnchanged(t *testing.T) {
+	readerWriter := &mocks.PerformerReaderWriter{}
+	tagReaderWriter := &mocks.TagReaderWriter{}
+
+	i := Importer{
+		ReaderWriter:  readerWriter,
+		TagWriter:     tagReaderWriter,
+		SkipUnchanged: true,
+		Input:         *createFullJSONPerformer(performerName, image),
+	}
+	i.Input.Tags = []string{existingTagName}
+	i.Input.CustomFields = customFields
+
+	// only reads are expected, so any write fails the test
+	mockExistingPerformer(readerWriter, tagReaderWriter)
+
+	err := i.PreImport(testCtx)
+	assert.Nil(t, err)
+
+	err = i.Update(testCtx, performerID)
+	assert.Nil(t, err)
+
+	err = i.PostImport(testCtx, performerID)
+	assert.Nil(t, err)
+
+	assert.True(t, i.Skipped())
+	assert.True(t, i.Unchanged())
+	assert.Empty(t, i.Changed())
+	assert.Equal(t, ImportChanges{
+		ID:      performerID,
+		Skipped: true,
+	}, i.Changes())
+
+	readerWriter.AssertExpectations(t)
+	tagReaderWriter.AssertExpectations(t)
+}
+
+func TestImporterUpdateSkipUnchangedChanged(t *testing.T) {
+	otherImage := append([]byte(nil), imageBytes...)
+	// change the last byte of the png, which is not validated
+	otherImage[len(otherImage)-1]++
+
+	tests := []struct {
+		name string
+		// change modifies the importer after PreImport
+		change func(i *Importer)
+	}{
+		{"field", func(i *Importer) {
+			i.performer.Details = "changed"
+		}},
+		{"tags", func(i *Importer) {
+			i.tags = nil
+		}},
+		{"image", func(i *Importer) {
+			i.imageData = otherImage
+		}},
+		{"custom fields", func(i *Importer) {
+			i.performer.CustomFields = map[string]string{"pronouns": "they/them"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readerWriter := &mocks.PerformerReaderWriter{}
+			tagReaderWriter := &mocks.TagReaderWriter{}
+
+			i := Importer{
+				ReaderWriter:  readerWriter,
+				TagWriter:     tagReaderWriter,
+				SkipUnchanged: true,
+				Input:         *createFullJSONPerformer(performerName, image),
+			}
+			i.Input.Tags = []string{existingTagName}
+			i.Input.CustomFields = customFields
+
+			readerWriter.On("Find", testCtx, performerID).Return(createFullPerformer(performerID, performerName), nil).Once()
+			// comparisons stop at the first difference
+			readerWriter.On("GetTagIDs", testCtx, performerID).Return([]int{existingTagID}, nil).Maybe()
+			readerWriter.On("GetStashIDs", testCtx, performerID).Return([]models.StashID{stashID}, nil).Maybe()
+			readerWriter.On("GetImage", testCtx, performerID).Return(imageBytes, nil).Maybe()
+			readerWriter.On("GetCustomFields", testCtx, performerID).Return(customFields, nil).Maybe()
+			tagReaderWriter.On("FindByNames", testCtx, []string{existingTagName}, false).Return([]*models.Tag{
+				{
+					ID:   existingTagID,
+					Name: existingTagName,
+				},
+			}, nil).Once()
+
+			err := i.PreImport(testCtx)
+			assert.Nil(t, err)
+
+			tt.change(&i)
+
+			readerWriter.On("Update", testCtx, mock.AnythingOfType("*models.Performer")).Return(nil).Once()
+
+			err = i.Update(testCtx, performerID)
+			assert.Nil(t, err)
+			assert.False(t, i.Skipped())
+			assert.True(t, i.Changes().Updated)
+
+			readerWriter.AssertExpectations(t)
+		})
+	}
+}
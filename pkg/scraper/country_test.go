@@ -0,0 +1,34 @@
+package scraper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveCountryName(t *testing.T) {
+	strPtr := func(s string) *string {
+		return &s
+	}
+
+	tests := []struct {
+		name  string
+		input *string
+		want  *string
+	}{
+		{"nil", nil, nil},
+		{"empty", strPtr("  "), nil},
+		{"alpha-2", strPtr(" US "), strPtr("US")},
+		{"name", strPtr("Germany"), strPtr("DE")},
+		{"name case", strPtr("UNITED KINGDOM"), strPtr("GB")},
+		// only names are resolved, so alpha-3 codes are returned as is
+		{"alpha-3", strPtr("DEU"), strPtr("DEU")},
+		{"unknown", strPtr("Atlantis"), strPtr("Atlantis")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveCountryName(tt.input))
+		})
+	}
+}
@@ -22,7 +22,7 @@ import (
 	"github.com/stashapp/stash/pkg/logger"
 )
 
-var appSchemaVersion uint = 37
+var appSchemaVersion uint = 44
 
 //go:embed migrations/*.sql
 var migrationsBox embed.FS
@@ -401,6 +401,7 @@ func registerCustomDriver() {
 				funcs := map[string]interface{}{
 					"regexp":            regexFn,
 					"durationToTinyInt": durationToTinyIntFn,
+					"collationKey":      collationKeyFn,
 				}
 
 				for name, fn := range funcs {
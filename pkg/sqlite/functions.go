@@ -3,6 +3,10 @@ package sqlite
 import (
 	"strconv"
 	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
 )
 
 func durationToTinyIntFn(str string) (int64, error) {
@@ -30,3 +34,31 @@ func durationToTinyIntFn(str string) (int64, error) {
 
 	return int64(seconds), nil
 }
+
+var collators = struct {
+	sync.Mutex
+	byLocale map[string]*collate.Collator
+}{
+	byLocale: make(map[string]*collate.Collator),
+}
+
+// collationKeyFn returns the key of str for the collation of the provided
+// BCP 47 locale. Keys compare bytewise in the collation order. An empty or
+// invalid locale uses the default collation.
+func collationKeyFn(str string, locale string) []byte {
+	collators.Lock()
+	defer collators.Unlock()
+
+	c := collators.byLocale[locale]
+	if c == nil {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			tag = language.Und
+		}
+
+		c = collate.New(tag)
+		collators.byLocale[locale] = c
+	}
+
+	return c.KeyFromString(&collate.Buffer{}, str)
+}
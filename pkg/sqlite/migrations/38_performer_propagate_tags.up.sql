@@ -0,0 +1 @@
+ALTER TABLE `performers` ADD COLUMN `propagate_tags` boolean not null default '0';
@@ -0,0 +1,2 @@
+ALTER TABLE `performers` ADD COLUMN `sort_name` varchar(255);
+ALTER TABLE `performers` ADD COLUMN `sort_name_locale` varchar(35);
@@ -0,0 +1,9 @@
+CREATE TABLE `performers_attachments` (
+  `performer_id` integer NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `content_type` varchar(255) NOT NULL,
+  `data` blob NOT NULL,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE
+);
+
+CREATE INDEX `index_performers_attachments_on_performer_id` on `performers_attachments` (`performer_id`);
@@ -0,0 +1 @@
+ALTER TABLE `performers` ADD COLUMN `disambiguation` varchar(255);
@@ -0,0 +1,7 @@
+CREATE TABLE `performers_custom_fields` (
+  `performer_id` integer NOT NULL,
+  `field` varchar(255) NOT NULL,
+  `value` text NOT NULL,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE,
+  PRIMARY KEY(`performer_id`, `field`)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE `performer_aliases` (
+  `performer_id` integer NOT NULL,
+  `alias` varchar(255) NOT NULL,
+  `alias_folded` varchar(255) NOT NULL,
+  foreign key(`performer_id`) references `performers`(`id`) on delete CASCADE,
+  PRIMARY KEY(`performer_id`, `alias`)
+);
+
+CREATE INDEX `index_performer_aliases_alias` on `performer_aliases` (`alias`);
+CREATE INDEX `index_performer_aliases_alias_folded` on `performer_aliases` (`alias_folded`);
@@ -0,0 +1,111 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stashapp/stash/pkg/logger"
+	"github.com/stashapp/stash/pkg/sqlite"
+)
+
+type schema44Migrator struct {
+	migrator
+}
+
+func post44(ctx context.Context, db *sqlx.DB) error {
+	logger.Info("Running post-migration for schema version 44")
+
+	m := schema44Migrator{
+		migrator: migrator{
+			db: db,
+		},
+	}
+
+	return m.migratePerformerAliases(ctx)
+}
+
+// migratePerformerAliases populates performer_aliases from the comma
+// separated aliases of each performer.
+func (m *schema44Migrator) migratePerformerAliases(ctx context.Context) error {
+	logger.Info("Migrating performer aliases")
+
+	const limit = 1000
+
+	lastID := 0
+	count := 0
+
+	for {
+		gotSome := false
+
+		if err := m.withTxn(ctx, func(tx *sqlx.Tx) error {
+			query := "SELECT `id`, `aliases` FROM `performers` WHERE `aliases` IS NOT NULL AND `aliases` != ''"
+
+			if lastID != 0 {
+				query += fmt.Sprintf(" AND `id` > %d ", lastID)
+			}
+
+			query += fmt.Sprintf(" ORDER BY `id` LIMIT %d", limit)
+
+			rows, err := tx.Query(query)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			type performerAliases struct {
+				id      int
+				aliases string
+			}
+			var performers []performerAliases
+
+			for rows.Next() {
+				var p performerAliases
+				if err := rows.Scan(&p.id, &p.aliases); err != nil {
+					return err
+				}
+
+				performers = append(performers, p)
+			}
+
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			rows.Close()
+
+			for _, p := range performers {
+				gotSome = true
+				lastID = p.id
+				count++
+
+				for _, alias := range strings.Split(p.aliases, ",") {
+					alias = strings.TrimSpace(alias)
+					if alias == "" {
+						continue
+					}
+
+					if _, err := tx.Exec("INSERT OR IGNORE INTO `performer_aliases` (`performer_id`, `alias`, `alias_folded`) VALUES (?, ?, ?)", p.id, alias, strings.ToLower(alias)); err != nil {
+						return fmt.Errorf("inserting aliases of performer %d: %w", p.id, err)
+					}
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if !gotSome {
+			break
+		}
+	}
+
+	logger.Infof("Migrated aliases of %d performers", count)
+
+	return nil
+}
+
+func init() {
+	sqlite.RegisterPostMigration(44, post44)
+}
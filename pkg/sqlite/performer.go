@@ -20,45 +20,57 @@ const performerTable = "performers"
 const performerIDColumn = "performer_id"
 const performersTagsTable = "performers_tags"
 const performersImageTable = "performers_image" // performer cover image
+const performersAttachmentsTable = "performers_attachments"
+const performersCustomFieldsTable = "performers_custom_fields"
+const performerAliasesTable = "performer_aliases"
 
 type performerRow struct {
-	ID            int                    `db:"id" goqu:"skipinsert"`
-	Checksum      string                 `db:"checksum"`
-	Name          zero.String            `db:"name"`
-	Gender        zero.String            `db:"gender"`
-	URL           zero.String            `db:"url"`
-	Twitter       zero.String            `db:"twitter"`
-	Instagram     zero.String            `db:"instagram"`
-	Birthdate     models.SQLiteDate      `db:"birthdate"`
-	Ethnicity     zero.String            `db:"ethnicity"`
-	Country       zero.String            `db:"country"`
-	EyeColor      zero.String            `db:"eye_color"`
-	Height        zero.String            `db:"height"`
-	Measurements  zero.String            `db:"measurements"`
-	FakeTits      zero.String            `db:"fake_tits"`
-	CareerLength  zero.String            `db:"career_length"`
-	Tattoos       zero.String            `db:"tattoos"`
-	Piercings     zero.String            `db:"piercings"`
-	Aliases       zero.String            `db:"aliases"`
-	Favorite      sql.NullBool           `db:"favorite"`
-	CreatedAt     models.SQLiteTimestamp `db:"created_at"`
-	UpdatedAt     models.SQLiteTimestamp `db:"updated_at"`
-	Rating        null.Int               `db:"rating"`
-	Details       zero.String            `db:"details"`
-	DeathDate     models.SQLiteDate      `db:"death_date"`
-	HairColor     zero.String            `db:"hair_color"`
-	Weight        null.Int               `db:"weight"`
-	IgnoreAutoTag bool                   `db:"ignore_auto_tag"`
+	ID             int                    `db:"id" goqu:"skipinsert"`
+	Checksum       string                 `db:"checksum"`
+	Name           zero.String            `db:"name"`
+	SortName       zero.String            `db:"sort_name"`
+	SortNameLocale zero.String            `db:"sort_name_locale"`
+	Disambiguation zero.String            `db:"disambiguation"`
+	Gender         zero.String            `db:"gender"`
+	URL            zero.String            `db:"url"`
+	URLs           zero.String            `db:"urls"`
+	Twitter        zero.String            `db:"twitter"`
+	Instagram      zero.String            `db:"instagram"`
+	Birthdate      models.SQLiteDate      `db:"birthdate"`
+	Ethnicity      zero.String            `db:"ethnicity"`
+	Country        zero.String            `db:"country"`
+	EyeColor       zero.String            `db:"eye_color"`
+	Height         zero.String            `db:"height"`
+	Measurements   zero.String            `db:"measurements"`
+	FakeTits       zero.String            `db:"fake_tits"`
+	CareerLength   zero.String            `db:"career_length"`
+	Tattoos        zero.String            `db:"tattoos"`
+	Piercings      zero.String            `db:"piercings"`
+	Aliases        zero.String            `db:"aliases"`
+	Favorite       sql.NullBool           `db:"favorite"`
+	CreatedAt      models.SQLiteTimestamp `db:"created_at"`
+	UpdatedAt      models.SQLiteTimestamp `db:"updated_at"`
+	Rating         null.Int               `db:"rating"`
+	Details        zero.String            `db:"details"`
+	DeathDate      models.SQLiteDate      `db:"death_date"`
+	HairColor      zero.String            `db:"hair_color"`
+	Weight         null.Int               `db:"weight"`
+	IgnoreAutoTag  bool                   `db:"ignore_auto_tag"`
+	PropagateTags  bool                   `db:"propagate_tags"`
 }
 
 func (r *performerRow) fromPerformer(o models.Performer) {
 	r.ID = o.ID
 	r.Checksum = o.Checksum
 	r.Name = zero.StringFrom(o.Name)
+	r.SortName = zero.StringFrom(o.SortName)
+	r.SortNameLocale = zero.StringFrom(o.SortNameLocale)
+	r.Disambiguation = zero.StringFrom(o.Disambiguation)
 	if o.Gender.IsValid() {
 		r.Gender = zero.StringFrom(o.Gender.String())
 	}
 	r.URL = zero.StringFrom(o.URL)
+	r.URLs = urlsToString(o.URLs)
 	r.Twitter = zero.StringFrom(o.Twitter)
 	r.Instagram = zero.StringFrom(o.Instagram)
 	if o.Birthdate != nil {
@@ -85,42 +97,78 @@ func (r *performerRow) fromPerformer(o models.Performer) {
 	r.HairColor = zero.StringFrom(o.HairColor)
 	r.Weight = intFromPtr(o.Weight)
 	r.IgnoreAutoTag = o.IgnoreAutoTag
+	r.PropagateTags = o.PropagateTags
 }
 
 func (r *performerRow) resolve() *models.Performer {
 	ret := &models.Performer{
-		ID:            r.ID,
-		Checksum:      r.Checksum,
-		Name:          r.Name.String,
-		Gender:        models.GenderEnum(r.Gender.String),
-		URL:           r.URL.String,
-		Twitter:       r.Twitter.String,
-		Instagram:     r.Instagram.String,
-		Birthdate:     r.Birthdate.DatePtr(),
-		Ethnicity:     r.Ethnicity.String,
-		Country:       r.Country.String,
-		EyeColor:      r.EyeColor.String,
-		Height:        r.Height.String,
-		Measurements:  r.Measurements.String,
-		FakeTits:      r.FakeTits.String,
-		CareerLength:  r.CareerLength.String,
-		Tattoos:       r.Tattoos.String,
-		Piercings:     r.Piercings.String,
-		Aliases:       r.Aliases.String,
-		Favorite:      r.Favorite.Bool,
-		CreatedAt:     r.CreatedAt.Timestamp,
-		UpdatedAt:     r.UpdatedAt.Timestamp,
-		Rating:        nullIntPtr(r.Rating),
-		Details:       r.Details.String,
-		DeathDate:     r.DeathDate.DatePtr(),
-		HairColor:     r.HairColor.String,
-		Weight:        nullIntPtr(r.Weight),
-		IgnoreAutoTag: r.IgnoreAutoTag,
+		ID:             r.ID,
+		Checksum:       r.Checksum,
+		Name:           r.Name.String,
+		SortName:       r.SortName.String,
+		SortNameLocale: r.SortNameLocale.String,
+		Disambiguation: r.Disambiguation.String,
+		Gender:         models.GenderEnum(r.Gender.String),
+		URL:            r.URL.String,
+		URLs:           urlsFromString(r.URLs, r.URL),
+		Twitter:        r.Twitter.String,
+		Instagram:      r.Instagram.String,
+		Birthdate:      r.Birthdate.DatePtr(),
+		Ethnicity:      r.Ethnicity.String,
+		Country:        r.Country.String,
+		EyeColor:       r.EyeColor.String,
+		Height:         r.Height.String,
+		Measurements:   r.Measurements.String,
+		FakeTits:       r.FakeTits.String,
+		CareerLength:   r.CareerLength.String,
+		Tattoos:        r.Tattoos.String,
+		Piercings:      r.Piercings.String,
+		Aliases:        r.Aliases.String,
+		Favorite:       r.Favorite.Bool,
+		CreatedAt:      r.CreatedAt.Timestamp,
+		UpdatedAt:      r.UpdatedAt.Timestamp,
+		Rating:         nullIntPtr(r.Rating),
+		Details:        r.Details.String,
+		DeathDate:      r.DeathDate.DatePtr(),
+		HairColor:      r.HairColor.String,
+		Weight:         nullIntPtr(r.Weight),
+		IgnoreAutoTag:  r.IgnoreAutoTag,
+		PropagateTags:  r.PropagateTags,
 	}
 
 	return ret
 }
 
+// equals returns true if r and o have the same values. Timestamps are equal
+// if they represent the same instant.
+func (r performerRow) equals(o performerRow) bool {
+	if !r.CreatedAt.Timestamp.Equal(o.CreatedAt.Timestamp) || !r.UpdatedAt.Timestamp.Equal(o.UpdatedAt.Timestamp) {
+		return false
+	}
+
+	r.CreatedAt, r.UpdatedAt = o.CreatedAt, o.UpdatedAt
+	return r == o
+}
+
+// urlsToString returns the newline-separated urls, or null if urls is
+// empty.
+func urlsToString(urls []string) zero.String {
+	return zero.StringFrom(strings.Join(urls, "\n"))
+}
+
+// urlsFromString returns the newline-separated urls. Performers stored
+// before the list of URLs was added only have a single URL.
+func urlsFromString(urls zero.String, url zero.String) []string {
+	if urls.String == "" {
+		if url.String == "" {
+			return nil
+		}
+		return []string{url.String}
+	}
+
+	return strings.Split(urls.String, "\n")
+}
+
 type performerRowRecord struct {
 	updateRecord
 }
@@ -128,8 +176,14 @@ type performerRowRecord struct {
 func (r *performerRowRecord) fromPartial(o models.PerformerPartial) {
 	r.setNullString("checksum", o.Checksum)
 	r.setNullString("name", o.Name)
+	r.setNullString("sort_name", o.SortName)
+	r.setNullString("sort_name_locale", o.SortNameLocale)
+	r.setNullString("disambiguation", o.Disambiguation)
 	r.setNullString("gender", o.Gender)
 	r.setNullString("url", o.URL)
+	if o.URLs != nil {
+		r.set("urls", urlsToString(*o.URLs))
+	}
 	r.setNullString("twitter", o.Twitter)
 	r.setNullString("instagram", o.Instagram)
 	r.setSQLiteDate("birthdate", o.Birthdate)
@@ -152,6 +206,7 @@ func (r *performerRowRecord) fromPartial(o models.PerformerPartial) {
 	r.setNullString("hair_color", o.HairColor)
 	r.setNullInt("weight", o.Weight)
 	r.setBool("ignore_auto_tag", o.IgnoreAutoTag)
+	r.setBool("propagate_tags", o.PropagateTags)
 }
 
 type PerformerStore struct {
@@ -179,6 +234,10 @@ func (qb *PerformerStore) Create(ctx context.Context, newObject *models.Performe
 		return err
 	}
 
+	if err := qb.aliasRepository().replace(ctx, id, newObject.Aliases); err != nil {
+		return fmt.Errorf("setting aliases: %w", err)
+	}
+
 	updated, err := qb.Find(ctx, id)
 	if err != nil {
 		return fmt.Errorf("finding after create: %w", err)
@@ -204,6 +263,12 @@ func (qb *PerformerStore) UpdatePartial(ctx context.Context, id int, updatedObje
 		}
 	}
 
+	if updatedObject.Aliases.Set {
+		if err := qb.aliasRepository().replace(ctx, id, updatedObject.Aliases.Value); err != nil {
+			return nil, fmt.Errorf("setting aliases: %w", err)
+		}
+	}
+
 	return qb.Find(ctx, id)
 }
 
@@ -215,9 +280,38 @@ func (qb *PerformerStore) Update(ctx context.Context, updatedObject *models.Perf
 		return err
 	}
 
+	if err := qb.aliasRepository().replace(ctx, updatedObject.ID, updatedObject.Aliases); err != nil {
+		return fmt.Errorf("setting aliases: %w", err)
+	}
+
 	return nil
 }
 
+// UpdateIfChanged updates the performer only if it differs from the stored
+// performer. It returns true if the performer was updated.
+func (qb *PerformerStore) UpdateIfChanged(ctx context.Context, updatedObject *models.Performer) (bool, error) {
+	existing, err := qb.Find(ctx, updatedObject.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if existing != nil {
+		var existingRow, r performerRow
+		existingRow.fromPerformer(*existing)
+		r.fromPerformer(*updatedObject)
+
+		if r.equals(existingRow) {
+			return false, nil
+		}
+	}
+
+	if err := qb.Update(ctx, updatedObject); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (qb *PerformerStore) Destroy(ctx context.Context, id int) error {
 	return qb.destroyExisting(ctx, []int{id})
 }
@@ -373,6 +467,40 @@ func (qb *PerformerStore) FindByNames(ctx context.Context, names []string, nocas
 	return ret, nil
 }
 
+// FindByAlias returns the performers with the provided alias. Aliases are
+// comma separated, and only match whole aliases. If nocase is set, aliases
+// are matched ignoring case.
+func (qb *PerformerStore) FindByAlias(ctx context.Context, alias string, nocase bool) ([]*models.Performer, error) {
+	column := "alias"
+	value := strings.TrimSpace(alias)
+	if nocase {
+		column = "alias_folded"
+		value = foldPerformerAlias(alias)
+	}
+
+	r := qb.aliasRepository()
+	query := fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s = ?", r.idColumn, r.tableName, column)
+
+	var ids []int
+	if err := r.queryFunc(ctx, query, []interface{}{value}, false, func(rows *sqlx.Rows) error {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+
+		ids = append(ids, id)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("getting performers by alias: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return qb.FindMany(ctx, ids)
+}
+
 func (qb *PerformerStore) CountByTagID(ctx context.Context, tagID int) (int, error) {
 	joinTable := performersTagsJoinTable
 
@@ -750,6 +878,10 @@ func (qb *PerformerStore) getPerformerSort(findFilter *models.FindFilterType) st
 	if sort == "galleries_count" {
 		return getCountSort(performerTable, performersGalleriesTable, performerIDColumn, direction)
 	}
+	if sort == "name" {
+		// sort by the sort name if set, collated using its locale
+		return " ORDER BY collationKey(COALESCE(NULLIF(performers.sort_name, ''), performers.name), COALESCE(performers.sort_name_locale, '')) " + getSortDirection(direction)
+	}
 
 	return getSort(sort, direction, "performers")
 }
@@ -797,6 +929,175 @@ func (qb *PerformerStore) DestroyImage(ctx context.Context, performerID int) err
 	return qb.imageRepository().destroy(ctx, []int{performerID})
 }
 
+type performerAttachmentRepository struct {
+	repository
+}
+
+type performerAttachments []models.PerformerAttachment
+
+func (a *performerAttachments) Append(o interface{}) {
+	*a = append(*a, *o.(*models.PerformerAttachment))
+}
+
+func (a *performerAttachments) New() interface{} {
+	return &models.PerformerAttachment{}
+}
+
+func (r *performerAttachmentRepository) get(ctx context.Context, id int) ([]models.PerformerAttachment, error) {
+	query := fmt.Sprintf("SELECT name, content_type, data from %s WHERE %s = ? ORDER BY rowid", r.tableName, r.idColumn)
+	var ret performerAttachments
+	err := r.query(ctx, query, []interface{}{id}, &ret)
+	return []models.PerformerAttachment(ret), err
+}
+
+func (r *performerAttachmentRepository) replace(ctx context.Context, id int, attachments []models.PerformerAttachment) error {
+	if err := r.destroy(ctx, []int{id}); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, name, content_type, data) VALUES (?, ?, ?, ?)", r.tableName, r.idColumn)
+	for _, a := range attachments {
+		_, err := r.tx.Exec(ctx, query, id, a.Name, a.ContentType, a.Data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (qb *PerformerStore) attachmentRepository() *performerAttachmentRepository {
+	return &performerAttachmentRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: performersAttachmentsTable,
+			idColumn:  performerIDColumn,
+		},
+	}
+}
+
+func (qb *PerformerStore) GetAttachments(ctx context.Context, performerID int) ([]models.PerformerAttachment, error) {
+	return qb.attachmentRepository().get(ctx, performerID)
+}
+
+func (qb *PerformerStore) UpdateAttachments(ctx context.Context, performerID int, attachments []models.PerformerAttachment) error {
+	return qb.attachmentRepository().replace(ctx, performerID, attachments)
+}
+
+type performerCustomFieldRepository struct {
+	repository
+}
+
+func (r *performerCustomFieldRepository) get(ctx context.Context, id int) (map[string]string, error) {
+	query := fmt.Sprintf("SELECT field, value from %s WHERE %s = ?", r.tableName, r.idColumn)
+
+	ret := make(map[string]string)
+	err := r.queryFunc(ctx, query, []interface{}{id}, false, func(rows *sqlx.Rows) error {
+		var field, value string
+		if err := rows.Scan(&field, &value); err != nil {
+			return err
+		}
+
+		ret[field] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret) == 0 {
+		return nil, nil
+	}
+
+	return ret, nil
+}
+
+func (r *performerCustomFieldRepository) replace(ctx context.Context, id int, fields map[string]string) error {
+	if err := r.destroy(ctx, []int{id}); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s, field, value) VALUES (?, ?, ?)", r.tableName, r.idColumn)
+	for field, value := range fields {
+		_, err := r.tx.Exec(ctx, query, id, field, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitPerformerAliases returns the comma separated aliases, with
+// surrounding whitespace trimmed and empty aliases removed.
+func splitPerformerAliases(aliases string) []string {
+	var ret []string
+	for _, alias := range strings.Split(aliases, ",") {
+		alias = strings.TrimSpace(alias)
+		if alias != "" {
+			ret = append(ret, alias)
+		}
+	}
+
+	return ret
+}
+
+// foldPerformerAlias returns the form of alias used to match it ignoring
+// case.
+func foldPerformerAlias(alias string) string {
+	return strings.ToLower(strings.TrimSpace(alias))
+}
+
+// performerAliasRepository maintains the aliases of each performer in a
+// separate table, so that performers can be found by alias.
+type performerAliasRepository struct {
+	repository
+}
+
+func (r *performerAliasRepository) replace(ctx context.Context, id int, aliases string) error {
+	if err := r.destroy(ctx, []int{id}); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("INSERT OR IGNORE INTO %s (%s, alias, alias_folded) VALUES (?, ?, ?)", r.tableName, r.idColumn)
+	for _, alias := range splitPerformerAliases(aliases) {
+		if _, err := r.tx.Exec(ctx, query, id, alias, foldPerformerAlias(alias)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (qb *PerformerStore) aliasRepository() *performerAliasRepository {
+	return &performerAliasRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: performerAliasesTable,
+			idColumn:  performerIDColumn,
+		},
+	}
+}
+
+func (qb *PerformerStore) customFieldRepository() *performerCustomFieldRepository {
+	return &performerCustomFieldRepository{
+		repository{
+			tx:        qb.tx,
+			tableName: performersCustomFieldsTable,
+			idColumn:  performerIDColumn,
+		},
+	}
+}
+
+// GetCustomFields returns the custom fields of the performer, or nil if it
+// has none.
+func (qb *PerformerStore) GetCustomFields(ctx context.Context, performerID int) (map[string]string, error) {
+	return qb.customFieldRepository().get(ctx, performerID)
+}
+
+// UpdateCustomFields replaces the custom fields of the performer.
+func (qb *PerformerStore) UpdateCustomFields(ctx context.Context, performerID int, fields map[string]string) error {
+	return qb.customFieldRepository().replace(ctx, performerID, fields)
+}
+
 func (qb *PerformerStore) stashIDRepository() *stashIDRepository {
 	return &stashIDRepository{
 		repository{
@@ -5,6 +5,7 @@ package sqlite_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strconv"
@@ -14,35 +15,43 @@ import (
 
 	"github.com/stashapp/stash/pkg/hash/md5"
 	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/performer"
+	"github.com/stashapp/stash/pkg/sqlite"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_PerformerStore_Update(t *testing.T) {
 	var (
-		name          = "name"
-		gender        = models.GenderEnumFemale
-		checksum      = "checksum"
-		details       = "details"
-		url           = "url"
-		twitter       = "twitter"
-		instagram     = "instagram"
-		rating        = 3
-		ethnicity     = "ethnicity"
-		country       = "country"
-		eyeColor      = "eyeColor"
-		height        = "height"
-		measurements  = "measurements"
-		fakeTits      = "fakeTits"
-		careerLength  = "careerLength"
-		tattoos       = "tattoos"
-		piercings     = "piercings"
-		aliases       = "aliases"
-		hairColor     = "hairColor"
-		weight        = 123
-		ignoreAutoTag = true
-		favorite      = true
-		createdAt     = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
-		updatedAt     = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		name           = "name"
+		sortName       = "sortName"
+		sortLocale     = "sv"
+		disambiguation = "disambiguation"
+		gender         = models.GenderEnumFemale
+		checksum       = "checksum"
+		details        = "details"
+		url            = "url"
+		urls           = []string{url, "otherURL"}
+		twitter        = "twitter"
+		instagram      = "instagram"
+		rating         = 3
+		ethnicity      = "ethnicity"
+		country        = "country"
+		eyeColor       = "eyeColor"
+		height         = "height"
+		measurements   = "measurements"
+		fakeTits       = "fakeTits"
+		careerLength   = "careerLength"
+		tattoos        = "tattoos"
+		piercings      = "piercings"
+		aliases        = "aliases"
+		hairColor      = "hairColor"
+		weight         = 123
+		ignoreAutoTag  = true
+		propagateTags  = true
+		favorite       = true
+		createdAt      = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		updatedAt      = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
 
 		birthdate = models.NewDate("2003-02-01")
 		deathdate = models.NewDate("2023-02-01")
@@ -56,33 +65,38 @@ func Test_PerformerStore_Update(t *testing.T) {
 		{
 			"full",
 			&models.Performer{
-				ID:            performerIDs[performerIdxWithGallery],
-				Name:          name,
-				Checksum:      checksum,
-				Gender:        gender,
-				URL:           url,
-				Twitter:       twitter,
-				Instagram:     instagram,
-				Birthdate:     &birthdate,
-				Ethnicity:     ethnicity,
-				Country:       country,
-				EyeColor:      eyeColor,
-				Height:        height,
-				Measurements:  measurements,
-				FakeTits:      fakeTits,
-				CareerLength:  careerLength,
-				Tattoos:       tattoos,
-				Piercings:     piercings,
-				Aliases:       aliases,
-				Favorite:      favorite,
-				Rating:        &rating,
-				Details:       details,
-				DeathDate:     &deathdate,
-				HairColor:     hairColor,
-				Weight:        &weight,
-				IgnoreAutoTag: ignoreAutoTag,
-				CreatedAt:     createdAt,
-				UpdatedAt:     updatedAt,
+				ID:             performerIDs[performerIdxWithGallery],
+				Name:           name,
+				SortName:       sortName,
+				SortNameLocale: sortLocale,
+				Disambiguation: disambiguation,
+				Checksum:       checksum,
+				Gender:         gender,
+				URL:            url,
+				URLs:           urls,
+				Twitter:        twitter,
+				Instagram:      instagram,
+				Birthdate:      &birthdate,
+				Ethnicity:      ethnicity,
+				Country:        country,
+				EyeColor:       eyeColor,
+				Height:         height,
+				Measurements:   measurements,
+				FakeTits:       fakeTits,
+				CareerLength:   careerLength,
+				Tattoos:        tattoos,
+				Piercings:      piercings,
+				Aliases:        aliases,
+				Favorite:       favorite,
+				Rating:         &rating,
+				Details:        details,
+				DeathDate:      &deathdate,
+				HairColor:      hairColor,
+				Weight:         &weight,
+				IgnoreAutoTag:  ignoreAutoTag,
+				PropagateTags:  propagateTags,
+				CreatedAt:      createdAt,
+				UpdatedAt:      updatedAt,
 			},
 			false,
 		},
@@ -122,30 +136,35 @@ func Test_PerformerStore_Update(t *testing.T) {
 
 func Test_PerformerStore_UpdatePartial(t *testing.T) {
 	var (
-		name          = "name"
-		gender        = models.GenderEnumFemale
-		checksum      = "checksum"
-		details       = "details"
-		url           = "url"
-		twitter       = "twitter"
-		instagram     = "instagram"
-		rating        = 3
-		ethnicity     = "ethnicity"
-		country       = "country"
-		eyeColor      = "eyeColor"
-		height        = "height"
-		measurements  = "measurements"
-		fakeTits      = "fakeTits"
-		careerLength  = "careerLength"
-		tattoos       = "tattoos"
-		piercings     = "piercings"
-		aliases       = "aliases"
-		hairColor     = "hairColor"
-		weight        = 123
-		ignoreAutoTag = true
-		favorite      = true
-		createdAt     = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
-		updatedAt     = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		name           = "name"
+		sortName       = "sortName"
+		sortLocale     = "sv"
+		disambiguation = "disambiguation"
+		gender         = models.GenderEnumFemale
+		checksum       = "checksum"
+		details        = "details"
+		url            = "url"
+		urls           = []string{url, "otherURL"}
+		twitter        = "twitter"
+		instagram      = "instagram"
+		rating         = 3
+		ethnicity      = "ethnicity"
+		country        = "country"
+		eyeColor       = "eyeColor"
+		height         = "height"
+		measurements   = "measurements"
+		fakeTits       = "fakeTits"
+		careerLength   = "careerLength"
+		tattoos        = "tattoos"
+		piercings      = "piercings"
+		aliases        = "aliases"
+		hairColor      = "hairColor"
+		weight         = 123
+		ignoreAutoTag  = true
+		propagateTags  = true
+		favorite       = true
+		createdAt      = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		updatedAt      = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
 
 		birthdate = models.NewDate("2003-02-01")
 		deathdate = models.NewDate("2023-02-01")
@@ -162,61 +181,71 @@ func Test_PerformerStore_UpdatePartial(t *testing.T) {
 			"full",
 			performerIDs[performerIdxWithDupName],
 			models.PerformerPartial{
-				Name:          models.NewOptionalString(name),
-				Checksum:      models.NewOptionalString(checksum),
-				Gender:        models.NewOptionalString(gender.String()),
-				URL:           models.NewOptionalString(url),
-				Twitter:       models.NewOptionalString(twitter),
-				Instagram:     models.NewOptionalString(instagram),
-				Birthdate:     models.NewOptionalDate(birthdate),
-				Ethnicity:     models.NewOptionalString(ethnicity),
-				Country:       models.NewOptionalString(country),
-				EyeColor:      models.NewOptionalString(eyeColor),
-				Height:        models.NewOptionalString(height),
-				Measurements:  models.NewOptionalString(measurements),
-				FakeTits:      models.NewOptionalString(fakeTits),
-				CareerLength:  models.NewOptionalString(careerLength),
-				Tattoos:       models.NewOptionalString(tattoos),
-				Piercings:     models.NewOptionalString(piercings),
-				Aliases:       models.NewOptionalString(aliases),
-				Favorite:      models.NewOptionalBool(favorite),
-				Rating:        models.NewOptionalInt(rating),
-				Details:       models.NewOptionalString(details),
-				DeathDate:     models.NewOptionalDate(deathdate),
-				HairColor:     models.NewOptionalString(hairColor),
-				Weight:        models.NewOptionalInt(weight),
-				IgnoreAutoTag: models.NewOptionalBool(ignoreAutoTag),
-				CreatedAt:     models.NewOptionalTime(createdAt),
-				UpdatedAt:     models.NewOptionalTime(updatedAt),
+				Name:           models.NewOptionalString(name),
+				SortName:       models.NewOptionalString(sortName),
+				SortNameLocale: models.NewOptionalString(sortLocale),
+				Disambiguation: models.NewOptionalString(disambiguation),
+				Checksum:       models.NewOptionalString(checksum),
+				Gender:         models.NewOptionalString(gender.String()),
+				URL:            models.NewOptionalString(url),
+				URLs:           &urls,
+				Twitter:        models.NewOptionalString(twitter),
+				Instagram:      models.NewOptionalString(instagram),
+				Birthdate:      models.NewOptionalDate(birthdate),
+				Ethnicity:      models.NewOptionalString(ethnicity),
+				Country:        models.NewOptionalString(country),
+				EyeColor:       models.NewOptionalString(eyeColor),
+				Height:         models.NewOptionalString(height),
+				Measurements:   models.NewOptionalString(measurements),
+				FakeTits:       models.NewOptionalString(fakeTits),
+				CareerLength:   models.NewOptionalString(careerLength),
+				Tattoos:        models.NewOptionalString(tattoos),
+				Piercings:      models.NewOptionalString(piercings),
+				Aliases:        models.NewOptionalString(aliases),
+				Favorite:       models.NewOptionalBool(favorite),
+				Rating:         models.NewOptionalInt(rating),
+				Details:        models.NewOptionalString(details),
+				DeathDate:      models.NewOptionalDate(deathdate),
+				HairColor:      models.NewOptionalString(hairColor),
+				Weight:         models.NewOptionalInt(weight),
+				IgnoreAutoTag:  models.NewOptionalBool(ignoreAutoTag),
+				PropagateTags:  models.NewOptionalBool(propagateTags),
+				CreatedAt:      models.NewOptionalTime(createdAt),
+				UpdatedAt:      models.NewOptionalTime(updatedAt),
 			},
 			models.Performer{
-				ID:            performerIDs[performerIdxWithDupName],
-				Name:          name,
-				Checksum:      checksum,
-				Gender:        gender,
-				URL:           url,
-				Twitter:       twitter,
-				Instagram:     instagram,
-				Birthdate:     &birthdate,
-				Ethnicity:     ethnicity,
-				Country:       country,
-				EyeColor:      eyeColor,
-				Height:        height,
-				Measurements:  measurements,
-				FakeTits:      fakeTits,
-				CareerLength:  careerLength,
-				Tattoos:       tattoos,
-				Piercings:     piercings,
-				Aliases:       aliases,
-				Favorite:      favorite,
-				Rating:        &rating,
-				Details:       details,
-				DeathDate:     &deathdate,
-				HairColor:     hairColor,
-				Weight:        &weight,
-				IgnoreAutoTag: ignoreAutoTag,
-				CreatedAt:     createdAt,
-				UpdatedAt:     updatedAt,
+				ID:             performerIDs[performerIdxWithDupName],
+				Name:           name,
+				SortName:       sortName,
+				SortNameLocale: sortLocale,
+				Disambiguation: disambiguation,
+				Checksum:       checksum,
+				Gender:         gender,
+				URL:            url,
+				URLs:           urls,
+				Twitter:        twitter,
+				Instagram:      instagram,
+				Birthdate:      &birthdate,
+				Ethnicity:      ethnicity,
+				Country:        country,
+				EyeColor:       eyeColor,
+				Height:         height,
+				Measurements:   measurements,
+				FakeTits:       fakeTits,
+				CareerLength:   careerLength,
+				Tattoos:        tattoos,
+				Piercings:      piercings,
+				Aliases:        aliases,
+				Favorite:       favorite,
+				Rating:         &rating,
+				Details:        details,
+				DeathDate:      &deathdate,
+				HairColor:      hairColor,
+				Weight:         &weight,
+				IgnoreAutoTag:  ignoreAutoTag,
+				PropagateTags:  propagateTags,
+				CreatedAt:      createdAt,
+				UpdatedAt:      updatedAt,
 			},
 			false,
 		},
@@ -1200,6 +1229,368 @@ func TestPerformerQueryIsMissingRating(t *testing.T) {
 	})
 }
 
+func TestPerformerFindByAlias(t *testing.T) {
+	if err := withRollbackTxn(func(ctx context.Context) error {
+		qb := db.Performer
+
+		create := func(name string, aliases string) (*models.Performer, error) {
+			p := &models.Performer{
+				Name:     name,
+				Checksum: md5.FromString(name),
+				Aliases:  aliases,
+			}
+			if err := qb.Create(ctx, p); err != nil {
+				return nil, fmt.Errorf("Error creating performer: %s", err.Error())
+			}
+			return p, nil
+		}
+
+		withAliases, err := create("TestFindByAlias1", "AliasFoo, AliasBar")
+		if err != nil {
+			return err
+		}
+		if _, err := create("TestFindByAlias2", "AliasFooBar"); err != nil {
+			return err
+		}
+		lowerCase, err := create("TestFindByAlias3", "aliasbar")
+		if err != nil {
+			return err
+		}
+
+		names := func(performers []*models.Performer) []string {
+			var ret []string
+			for _, p := range performers {
+				ret = append(ret, p.Name)
+			}
+			return ret
+		}
+
+		performers, err := qb.FindByAlias(ctx, "AliasBar", false)
+		if err != nil {
+			return fmt.Errorf("Error finding performers: %s", err.Error())
+		}
+		assert.Equal(t, []string{withAliases.Name}, names(performers))
+
+		performers, err = qb.FindByAlias(ctx, "AliasBar", true)
+		if err != nil {
+			return fmt.Errorf("Error finding performers: %s", err.Error())
+		}
+		assert.ElementsMatch(t, []string{withAliases.Name, lowerCase.Name}, names(performers))
+
+		// substrings of aliases do not match
+		performers, err = qb.FindByAlias(ctx, "aliasfoo", true)
+		if err != nil {
+			return fmt.Errorf("Error finding performers: %s", err.Error())
+		}
+		assert.Equal(t, []string{withAliases.Name}, names(performers))
+
+		// wildcard characters match literally
+		wildcard, err := create("TestFindByAlias4", "Alias%, Alias_Baz")
+		if err != nil {
+			return err
+		}
+
+		for _, alias := range []string{"Alias%", "Alias_Baz"} {
+			performers, err = qb.FindByAlias(ctx, alias, false)
+			if err != nil {
+				return fmt.Errorf("Error finding performers: %s", err.Error())
+			}
+			assert.Equal(t, []string{wildcard.Name}, names(performers), alias)
+		}
+
+		for _, alias := range []string{"%", "Alias_", "AliasXBaz"} {
+			performers, err = qb.FindByAlias(ctx, alias, true)
+			if err != nil {
+				return fmt.Errorf("Error finding performers: %s", err.Error())
+			}
+			assert.Empty(t, performers, alias)
+		}
+
+		// case is folded beyond ASCII
+		unicode, err := create("TestFindByAlias5", "Ärla Öberg")
+		if err != nil {
+			return err
+		}
+
+		performers, err = qb.FindByAlias(ctx, "ärla öberg", false)
+		if err != nil {
+			return fmt.Errorf("Error finding performers: %s", err.Error())
+		}
+		assert.Empty(t, performers)
+
+		performers, err = qb.FindByAlias(ctx, "ärla öberg", true)
+		if err != nil {
+			return fmt.Errorf("Error finding performers: %s", err.Error())
+		}
+		assert.Equal(t, []string{unicode.Name}, names(performers))
+
+		// aliases follow updates
+		if err := qb.Update(ctx, &models.Performer{
+			ID:       unicode.ID,
+			Name:     unicode.Name,
+			Checksum: unicode.Checksum,
+			Aliases:  "Other",
+		}); err != nil {
+			return fmt.Errorf("Error updating performer: %s", err.Error())
+		}
+
+		performers, err = qb.FindByAlias(ctx, "Ärla Öberg", false)
+		if err != nil {
+			return fmt.Errorf("Error finding performers: %s", err.Error())
+		}
+		assert.Empty(t, performers)
+
+		if _, err := qb.UpdatePartial(ctx, unicode.ID, models.PerformerPartial{
+			Aliases: models.NewOptionalString("Ärla"),
+		}); err != nil {
+			return fmt.Errorf("Error updating performer: %s", err.Error())
+		}
+
+		performers, err = qb.FindByAlias(ctx, "ÄRLA", true)
+		if err != nil {
+			return fmt.Errorf("Error finding performers: %s", err.Error())
+		}
+		assert.Equal(t, []string{unicode.Name}, names(performers))
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestPerformerAttachments(t *testing.T) {
+	if err := withRollbackTxn(func(ctx context.Context) error {
+		qb := db.Performer
+
+		// create performer to test against
+		const name = "TestAttachments"
+		performer := models.Performer{
+			Name:     name,
+			Checksum: md5.FromString(name),
+		}
+		err := qb.Create(ctx, &performer)
+		if err != nil {
+			return fmt.Errorf("Error creating performer: %s", err.Error())
+		}
+
+		attachments, err := qb.GetAttachments(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting attachments: %s", err.Error())
+		}
+		assert.Len(t, attachments, 0)
+
+		attachments = []models.PerformerAttachment{
+			{
+				Name:        "b.pdf",
+				ContentType: "application/pdf",
+				Data:        []byte("%PDF-1.4"),
+			},
+			{
+				Name:        "a.txt",
+				ContentType: "text/plain; charset=utf-8",
+				Data:        []byte("text"),
+			},
+		}
+		if err := qb.UpdateAttachments(ctx, performer.ID, attachments); err != nil {
+			return fmt.Errorf("Error updating attachments: %s", err.Error())
+		}
+
+		got, err := qb.GetAttachments(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting attachments: %s", err.Error())
+		}
+		assert.Equal(t, attachments, got)
+
+		// replace attachments
+		if err := qb.UpdateAttachments(ctx, performer.ID, attachments[1:]); err != nil {
+			return fmt.Errorf("Error updating attachments: %s", err.Error())
+		}
+
+		got, err = qb.GetAttachments(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting attachments: %s", err.Error())
+		}
+		assert.Equal(t, attachments[1:], got)
+
+		// attachments are removed with the performer
+		if err := qb.Destroy(ctx, performer.ID); err != nil {
+			return fmt.Errorf("Error destroying performer: %s", err.Error())
+		}
+
+		got, err = qb.GetAttachments(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting attachments: %s", err.Error())
+		}
+		assert.Len(t, got, 0)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestPerformerCustomFields(t *testing.T) {
+	if err := withRollbackTxn(func(ctx context.Context) error {
+		qb := db.Performer
+
+		// create performer to test against
+		const name = "TestCustomFields"
+		performer := models.Performer{
+			Name:     name,
+			Checksum: md5.FromString(name),
+		}
+		err := qb.Create(ctx, &performer)
+		if err != nil {
+			return fmt.Errorf("Error creating performer: %s", err.Error())
+		}
+
+		fields, err := qb.GetCustomFields(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting custom fields: %s", err.Error())
+		}
+		assert.Nil(t, fields)
+
+		fields = map[string]string{
+			"pronouns":    "they/them",
+			"internal_id": "42",
+			"empty":       "",
+		}
+		if err := qb.UpdateCustomFields(ctx, performer.ID, fields); err != nil {
+			return fmt.Errorf("Error updating custom fields: %s", err.Error())
+		}
+
+		got, err := qb.GetCustomFields(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting custom fields: %s", err.Error())
+		}
+		assert.Equal(t, fields, got)
+
+		// replace custom fields
+		replaced := map[string]string{
+			"pronouns": "she/her",
+		}
+		if err := qb.UpdateCustomFields(ctx, performer.ID, replaced); err != nil {
+			return fmt.Errorf("Error updating custom fields: %s", err.Error())
+		}
+
+		got, err = qb.GetCustomFields(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting custom fields: %s", err.Error())
+		}
+		assert.Equal(t, replaced, got)
+
+		// an empty map clears the custom fields
+		if err := qb.UpdateCustomFields(ctx, performer.ID, map[string]string{}); err != nil {
+			return fmt.Errorf("Error updating custom fields: %s", err.Error())
+		}
+
+		got, err = qb.GetCustomFields(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error getting custom fields: %s", err.Error())
+		}
+		assert.Nil(t, got)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+// failingImagePerformerStore fails to set performer images.
+type failingImagePerformerStore struct {
+	*sqlite.PerformerStore
+}
+
+func (s failingImagePerformerStore) UpdateImage(ctx context.Context, performerID int, image []byte) error {
+	return errors.New("UpdateImage error")
+}
+
+func TestPerformerImportRollback(t *testing.T) {
+	const (
+		name    = "TestImportRollback"
+		tagName = "TestImportRollbackTag"
+		// 1x1 png
+		image = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAAAAAA6fptVAAAAD0lEQVR4nAACAP3/AgADAAAGAAMh/KwGAAAAAElFTkSuQmCC"
+	)
+
+	i := performer.Importer{
+		ReaderWriter:        failingImagePerformerStore{db.Performer},
+		TagWriter:           sqlite.TagReaderWriter,
+		MissingRefBehaviour: models.ImportMissingRefEnumCreate,
+		Input: jsonschema.Performer{
+			Name:  name,
+			Tags:  []string{tagName},
+			Image: image,
+		},
+	}
+
+	err := i.Import(context.Background(), db)
+	assert.ErrorContains(t, err, "UpdateImage error")
+
+	if err := withTxn(func(ctx context.Context) error {
+		performers, err := db.Performer.FindByNames(ctx, []string{name}, false)
+		if err != nil {
+			return fmt.Errorf("Error finding performers: %s", err.Error())
+		}
+		assert.Empty(t, performers)
+
+		// the tag created for the performer is rolled back with it
+		tags, err := sqlite.TagReaderWriter.FindByNames(ctx, []string{tagName}, false)
+		if err != nil {
+			return fmt.Errorf("Error finding tags: %s", err.Error())
+		}
+		assert.Empty(t, tags)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestPerformerStore_UpdateIfChanged(t *testing.T) {
+	if err := withRollbackTxn(func(ctx context.Context) error {
+		qb := db.Performer
+
+		const name = "TestUpdateIfChanged"
+		performer := models.Performer{
+			Name:      name,
+			Checksum:  md5.FromString(name),
+			CreatedAt: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if err := qb.Create(ctx, &performer); err != nil {
+			return fmt.Errorf("Error creating performer: %s", err.Error())
+		}
+
+		// same instant in a different location is unchanged
+		unchanged := performer
+		unchanged.CreatedAt = performer.CreatedAt.In(time.FixedZone("test", 3600))
+		changed, err := qb.UpdateIfChanged(ctx, &unchanged)
+		if err != nil {
+			return fmt.Errorf("Error updating performer: %s", err.Error())
+		}
+		assert.False(t, changed)
+
+		updated := performer
+		updated.Details = "details"
+		changed, err = qb.UpdateIfChanged(ctx, &updated)
+		if err != nil {
+			return fmt.Errorf("Error updating performer: %s", err.Error())
+		}
+		assert.True(t, changed)
+
+		got, err := qb.Find(ctx, performer.ID)
+		if err != nil {
+			return fmt.Errorf("Error finding performer: %s", err.Error())
+		}
+		assert.Equal(t, "details", got.Details)
+
+		return nil
+	}); err != nil {
+		t.Error(err.Error())
+	}
+}
+
 func TestPerformerQueryIsMissingImage(t *testing.T) {
 	withTxn(func(ctx context.Context) error {
 		isMissing := "image"
@@ -1266,6 +1657,55 @@ func TestPerformerQuerySortScenesCount(t *testing.T) {
 	})
 }
 
+func TestPerformerQuerySortName(t *testing.T) {
+	const prefix = "sortLocale "
+
+	sort := "name"
+	findFilter := &models.FindFilterType{
+		Sort: &sort,
+	}
+	performerFilter := &models.PerformerFilterType{
+		Name: &models.StringCriterionInput{
+			Value:    prefix,
+			Modifier: models.CriterionModifierIncludes,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		locale string
+		want   []string
+	}{
+		{"default collation", "", []string{"Ärla", "Anna", "Zelda"}},
+		// ä sorts after z in Swedish
+		{"swedish", "sv", []string{"Anna", "Zelda", "Ärla"}},
+	}
+
+	for _, tt := range tests {
+		runWithRollbackTxn(t, tt.name, func(t *testing.T, ctx context.Context) {
+			for _, p := range []models.Performer{
+				{Name: prefix + "Ärla", SortNameLocale: tt.locale},
+				{Name: prefix + "Zelda", SortNameLocale: tt.locale},
+				// sorted by sort name rather than name
+				{Name: prefix + "Anna", SortName: prefix + "Berg", SortNameLocale: tt.locale},
+			} {
+				p.Checksum = md5.FromString(p.Name)
+				if err := db.Performer.Create(ctx, &p); err != nil {
+					t.Errorf("Error creating performer: %s", err.Error())
+					return
+				}
+			}
+
+			var got []string
+			for _, p := range queryPerformers(ctx, t, performerFilter, findFilter) {
+				got = append(got, strings.TrimPrefix(p.Name, prefix))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestPerformerCountByTagID(t *testing.T) {
 	withTxn(func(ctx context.Context) error {
 		sqb := db.Performer
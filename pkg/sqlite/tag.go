@@ -38,6 +38,44 @@ func (qb *tagQueryBuilder) Create(ctx context.Context, newObject models.Tag) (*m
 	return &ret, nil
 }
 
+// CreateMany creates the tags using a single statement, returning the
+// created tags in the same order.
+func (qb *tagQueryBuilder) CreateMany(ctx context.Context, newObjects []models.Tag) ([]*models.Tag, error) {
+	if len(newObjects) == 0 {
+		return nil, nil
+	}
+
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", qb.tableName, listKeys(newObjects[0], false), listKeys(newObjects[0], true))
+	if _, err := qb.tx.NamedExec(ctx, stmt, newObjects); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, t := range newObjects {
+		names = append(names, t.Name)
+	}
+
+	created, err := qb.FindByNames(ctx, names, false)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*models.Tag)
+	for _, t := range created {
+		byName[t.Name] = t
+	}
+
+	ret := make([]*models.Tag, len(newObjects))
+	for i, t := range newObjects {
+		ret[i] = byName[t.Name]
+		if ret[i] == nil {
+			return nil, fmt.Errorf("created tag %q not found", t.Name)
+		}
+	}
+
+	return ret, nil
+}
+
 func (qb *tagQueryBuilder) Update(ctx context.Context, updatedObject models.TagPartial) (*models.Tag, error) {
 	const partial = true
 	if err := qb.update(ctx, updatedObject.ID, updatedObject, partial); err != nil {
@@ -221,6 +259,55 @@ func (qb *tagQueryBuilder) FindByNames(ctx context.Context, names []string, noca
 	return qb.queryTags(ctx, query, args)
 }
 
+// FindByAliases returns the tags with the provided aliases, keyed by the
+// provided alias. Aliases are matched ignoring case.
+func (qb *tagQueryBuilder) FindByAliases(ctx context.Context, aliases []string) (map[string]*models.Tag, error) {
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s COLLATE NOCASE IN %s", tagIDColumn, tagAliasColumn, tagAliasesTable, tagAliasColumn, getInBinding(len(aliases)))
+	var args []interface{}
+	for _, alias := range aliases {
+		args = append(args, alias)
+	}
+
+	idsByAlias := make(map[string]int)
+	var ids []int
+	if err := qb.queryFunc(ctx, query, args, false, func(rows *sqlx.Rows) error {
+		var id int
+		var alias string
+		if err := rows.Scan(&id, &alias); err != nil {
+			return err
+		}
+
+		idsByAlias[strings.ToLower(alias)] = id
+		ids = intslice.IntAppendUnique(ids, id)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	tags, err := qb.FindMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]*models.Tag)
+	for _, alias := range aliases {
+		id, found := idsByAlias[strings.ToLower(alias)]
+		if found {
+			ret[alias] = tags[intslice.IntIndex(ids, id)]
+		}
+	}
+
+	return ret, nil
+}
+
 func (qb *tagQueryBuilder) FindByParentTagID(ctx context.Context, parentID int) ([]*models.Tag, error) {
 	query := `
 		SELECT tags.* FROM tags
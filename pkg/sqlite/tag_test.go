@@ -127,6 +127,65 @@ func TestTagQueryForAutoTag(t *testing.T) {
 	})
 }
 
+func TestTagCreateMany(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		tqb := sqlite.TagReaderWriter
+
+		names := []string{"TestTagCreateMany2", "TestTagCreateMany1"}
+		var newTags []models.Tag
+		for _, name := range names {
+			newTags = append(newTags, *models.NewTag(name))
+		}
+
+		created, err := tqb.CreateMany(ctx, newTags)
+		if err != nil {
+			t.Errorf("Error creating tags: %s", err.Error())
+			return nil
+		}
+
+		if assert.Len(t, created, len(names)) {
+			for i, name := range names {
+				assert.Equal(t, name, created[i].Name)
+				assert.NotZero(t, created[i].ID)
+			}
+		}
+
+		for _, c := range created {
+			if err := tqb.Destroy(ctx, c.ID); err != nil {
+				t.Errorf("Error destroying tag: %s", err.Error())
+			}
+		}
+
+		return nil
+	})
+}
+
+func TestTagFindByAliases(t *testing.T) {
+	withTxn(func(ctx context.Context) error {
+		tqb := sqlite.TagReaderWriter
+
+		alias := getTagStringValue(tagIdxWithScene, "Alias")
+		otherAlias := strings.ToUpper(getTagStringValue(tagIdx1WithScene, "Alias"))
+		const missing = "missing alias"
+
+		tags, err := tqb.FindByAliases(ctx, []string{alias, otherAlias, missing})
+		if err != nil {
+			t.Errorf("Error finding tags: %s", err.Error())
+			return nil
+		}
+
+		assert.Len(t, tags, 2)
+		if assert.NotNil(t, tags[alias]) {
+			assert.Equal(t, tagIDs[tagIdxWithScene], tags[alias].ID)
+		}
+		if assert.NotNil(t, tags[otherAlias]) {
+			assert.Equal(t, tagIDs[tagIdx1WithScene], tags[otherAlias].ID)
+		}
+
+		return nil
+	})
+}
+
 func TestTagFindByNames(t *testing.T) {
 	var names []string
 
@@ -0,0 +1,158 @@
+package tag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/sliceutil/stringslice"
+)
+
+type HierarchyReaderWriter interface {
+	RelationshipGetter
+	FindByName(ctx context.Context, name string, nocase bool) (*models.Tag, error)
+	Create(ctx context.Context, newTag models.Tag) (*models.Tag, error)
+	GetAliases(ctx context.Context, tagID int) ([]string, error)
+	UpdateAliases(ctx context.Context, tagID int, aliases []string) error
+	UpdateParentTags(ctx context.Context, tagID int, parentIDs []int) error
+}
+
+// ImportHierarchy imports the names, aliases and parents of the provided
+// tags, so that subsequent imports referencing the tags by name or alias
+// resolve them without creating new tags. Other fields of the input are
+// ignored.
+//
+// Missing tags are created. The aliases of each tag are added to its
+// existing aliases, and its parents are set to the listed parents. Parents
+// must either be present in tags or already exist.
+func ImportHierarchy(ctx context.Context, rw HierarchyReaderWriter, tags []jsonschema.Tag) error {
+	ordered, err := orderHierarchy(tags)
+	if err != nil {
+		return err
+	}
+
+	ids := make(map[string]int)
+	for _, t := range ordered {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		imported, err := importHierarchyTag(ctx, rw, t, ids)
+		if err != nil {
+			return fmt.Errorf("error importing tag %q: %w", t.Name, err)
+		}
+
+		ids[t.Name] = imported.ID
+	}
+
+	return nil
+}
+
+// orderHierarchy returns tags ordered such that each tag follows its
+// parents. It returns an error if a name is duplicated or the hierarchy
+// contains a cycle.
+func orderHierarchy(tags []jsonschema.Tag) ([]jsonschema.Tag, error) {
+	byName := make(map[string]jsonschema.Tag)
+	for _, t := range tags {
+		if _, found := byName[t.Name]; found {
+			return nil, fmt.Errorf("tag %q is present more than once in hierarchy", t.Name)
+		}
+		byName[t.Name] = t
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var ret []jsonschema.Tag
+
+	var visit func(t jsonschema.Tag) error
+	visit = func(t jsonschema.Tag) error {
+		switch state[t.Name] {
+		case visiting:
+			return fmt.Errorf("tag hierarchy contains a cycle at %q", t.Name)
+		case visited:
+			return nil
+		}
+
+		state[t.Name] = visiting
+		for _, parent := range t.Parents {
+			if p, found := byName[parent]; found {
+				if err := visit(p); err != nil {
+					return err
+				}
+			}
+		}
+		state[t.Name] = visited
+
+		ret = append(ret, t)
+		return nil
+	}
+
+	for _, t := range tags {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return ret, nil
+}
+
+func importHierarchyTag(ctx context.Context, rw HierarchyReaderWriter, input jsonschema.Tag, ids map[string]int) (*models.Tag, error) {
+	t, err := rw.FindByName(ctx, input.Name, false)
+	if err != nil {
+		return nil, fmt.Errorf("error finding tag: %v", err)
+	}
+
+	if t == nil {
+		t, err = rw.Create(ctx, *models.NewTag(input.Name))
+		if err != nil {
+			return nil, fmt.Errorf("error creating tag: %v", err)
+		}
+	}
+
+	if len(input.Aliases) > 0 {
+		existing, err := rw.GetAliases(ctx, t.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting aliases: %v", err)
+		}
+
+		aliases := stringslice.StrAppendUniques(existing, input.Aliases)
+		if len(aliases) != len(existing) {
+			if err := rw.UpdateAliases(ctx, t.ID, aliases); err != nil {
+				return nil, fmt.Errorf("error setting aliases: %v", err)
+			}
+		}
+	}
+
+	var parentIDs []int
+	for _, parent := range input.Parents {
+		id, found := ids[parent]
+		if !found {
+			p, err := rw.FindByName(ctx, parent, false)
+			if err != nil {
+				return nil, fmt.Errorf("error finding parent by name: %v", err)
+			}
+			if p == nil {
+				return nil, ParentTagNotExistError{missingParent: parent}
+			}
+			id = p.ID
+		}
+
+		parentIDs = append(parentIDs, id)
+	}
+
+	if len(parentIDs) > 0 {
+		if err := ValidateHierarchy(ctx, t, parentIDs, nil, rw); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := rw.UpdateParentTags(ctx, t.ID, parentIDs); err != nil {
+		return nil, fmt.Errorf("error setting parents: %v", err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,107 @@
+package tag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stashapp/stash/pkg/models"
+	"github.com/stashapp/stash/pkg/models/jsonschema"
+	"github.com/stashapp/stash/pkg/models/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestImportHierarchy(t *testing.T) {
+	const (
+		rootName   = "root"
+		rootID     = 101
+		rootAlias  = "rootAlias"
+		childName  = "child"
+		externalID = 102
+		external   = "external"
+	)
+
+	db := &mocks.TagReaderWriter{}
+
+	tags := []jsonschema.Tag{
+		{
+			Name:    childName,
+			Parents: []string{rootName, external},
+		},
+		{
+			Name:    rootName,
+			Aliases: []string{rootAlias},
+		},
+	}
+
+	// root is created first, since child references it
+	db.On("FindByName", testCtx, rootName, false).Return(nil, nil).Once()
+	db.On("Create", testCtx, mock.MatchedBy(func(t models.Tag) bool {
+		return t.Name == rootName
+	})).Return(&models.Tag{ID: rootID, Name: rootName}, nil).Once()
+	db.On("GetAliases", testCtx, rootID).Return(nil, nil).Once()
+	db.On("UpdateAliases", testCtx, rootID, []string{rootAlias}).Return(nil).Once()
+	db.On("UpdateParentTags", testCtx, rootID, []int(nil)).Return(nil).Once()
+
+	db.On("FindByName", testCtx, childName, false).Return(&models.Tag{ID: existingTagID, Name: childName}, nil).Once()
+	db.On("FindByName", testCtx, external, false).Return(&models.Tag{ID: externalID, Name: external}, nil).Once()
+	db.On("FindAllAncestors", testCtx, existingTagID, []int(nil)).Return(nil, nil).Once()
+	db.On("FindAllDescendants", testCtx, existingTagID, []int(nil)).Return(nil, nil).Once()
+	db.On("FindByParentTagID", testCtx, existingTagID).Return(nil, nil).Once()
+	db.On("UpdateParentTags", testCtx, existingTagID, []int{rootID, externalID}).Return(nil).Once()
+
+	err := ImportHierarchy(testCtx, db, tags)
+	assert.Nil(t, err)
+
+	db.AssertExpectations(t)
+}
+
+func TestImportHierarchyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []jsonschema.Tag
+	}{
+		{
+			"duplicate",
+			[]jsonschema.Tag{{Name: tagName}, {Name: tagName}},
+		},
+		{
+			"cycle",
+			[]jsonschema.Tag{
+				{Name: tagName, Parents: []string{existingTagName}},
+				{Name: existingTagName, Parents: []string{tagName}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mocks.TagReaderWriter{}
+
+			err := ImportHierarchy(testCtx, db, tt.tags)
+			assert.NotNil(t, err)
+
+			// nothing should be written
+			db.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImportHierarchyMissingParent(t *testing.T) {
+	const missingParent = "missingParent"
+
+	db := &mocks.TagReaderWriter{}
+
+	db.On("FindByName", testCtx, tagName, false).Return(&models.Tag{ID: existingTagID, Name: tagName}, nil).Once()
+	db.On("FindByName", testCtx, missingParent, false).Return(nil, nil).Once()
+
+	err := ImportHierarchy(testCtx, db, []jsonschema.Tag{
+		{Name: tagName, Parents: []string{missingParent}},
+	})
+
+	var parentErr ParentTagNotExistError
+	assert.True(t, errors.As(err, &parentErr))
+	assert.Equal(t, missingParent, parentErr.MissingParent())
+
+	db.AssertExpectations(t)
+}
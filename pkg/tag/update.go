@@ -12,6 +12,20 @@ type NameFinderCreator interface {
 	Create(ctx context.Context, newTag models.Tag) (*models.Tag, error)
 }
 
+// ManyCreator is implemented by writers that can create multiple tags in a
+// single call. The created tags are returned in the same order.
+type ManyCreator interface {
+	CreateMany(ctx context.Context, newTags []models.Tag) ([]*models.Tag, error)
+}
+
+// AliasFinder is implemented by readers that can find the tags of multiple
+// aliases in a single call.
+type AliasFinder interface {
+	// FindByAliases returns the tags with the provided aliases, keyed by
+	// the provided alias. Aliases are matched ignoring case.
+	FindByAliases(ctx context.Context, aliases []string) (map[string]*models.Tag, error)
+}
+
 type NameExistsError struct {
 	Name string
 }
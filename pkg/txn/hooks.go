@@ -64,3 +64,9 @@ func AddPostCompleteHook(ctx context.Context, hook TxnFunc) {
 	m := hookManagerCtx(ctx)
 	m.postCompleteHooks = append(m.postCompleteHooks, hook)
 }
+
+// InTxn returns true if ctx is within a transaction begun by WithTxn, so
+// that hooks can be added to it.
+func InTxn(ctx context.Context) bool {
+	return hookManagerCtx(ctx) != nil
+}
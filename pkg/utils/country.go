@@ -0,0 +1,562 @@
+package utils
+
+import (
+	"strings"
+)
+
+// countryNameMapping maps country names and common synonyms to their ISO
+// 3166-1 alpha-2 codes. Keys are lower case.
+var countryNameMapping = map[string]string{
+	"afghanistan":                          "AF",
+	"albania":                              "AL",
+	"algeria":                              "DZ",
+	"america":                              "US",
+	"american":                             "US",
+	"american samoa":                       "AS",
+	"andorra":                              "AD",
+	"angola":                               "AO",
+	"anguilla":                             "AI",
+	"antarctica":                           "AQ",
+	"antigua and barbuda":                  "AG",
+	"argentina":                            "AR",
+	"armenia":                              "AM",
+	"aruba":                                "AW",
+	"australia":                            "AU",
+	"austria":                              "AT",
+	"azerbaijan":                           "AZ",
+	"bahamas":                              "BS",
+	"bahrain":                              "BH",
+	"bangladesh":                           "BD",
+	"barbados":                             "BB",
+	"belarus":                              "BY",
+	"belgium":                              "BE",
+	"belize":                               "BZ",
+	"benin":                                "BJ",
+	"bermuda":                              "BM",
+	"bhutan":                               "BT",
+	"bolivia":                              "BO",
+	"bosnia and herzegovina":               "BA",
+	"botswana":                             "BW",
+	"bouvet island":                        "BV",
+	"brazil":                               "BR",
+	"british indian ocean territory":       "IO",
+	"brunei darussalam":                    "BN",
+	"bulgaria":                             "BG",
+	"burkina faso":                         "BF",
+	"burundi":                              "BI",
+	"cambodia":                             "KH",
+	"cameroon":                             "CM",
+	"canada":                               "CA",
+	"cape verde":                           "CV",
+	"cayman islands":                       "KY",
+	"central african republic":             "CF",
+	"chad":                                 "TD",
+	"chile":                                "CL",
+	"china":                                "CN",
+	"christmas island":                     "CX",
+	"cocos (keeling) islands":              "CC",
+	"colombia":                             "CO",
+	"comoros":                              "KM",
+	"congo":                                "CG",
+	"congo the democratic republic of the": "CD",
+	"cook islands":                         "CK",
+	"costa rica":                           "CR",
+	"cote d'ivoire":                        "CI",
+	"croatia":                              "HR",
+	"cuba":                                 "CU",
+	"cyprus":                               "CY",
+	"czech republic":                       "CZ",
+	"czechia":                              "CZ",
+	"denmark":                              "DK",
+	"djibouti":                             "DJ",
+	"dominica":                             "DM",
+	"dominican republic":                   "DO",
+	"ecuador":                              "EC",
+	"egypt":                                "EG",
+	"el salvador":                          "SV",
+	"equatorial guinea":                    "GQ",
+	"eritrea":                              "ER",
+	"estonia":                              "EE",
+	"ethiopia":                             "ET",
+	"falkland islands (malvinas)":          "FK",
+	"faroe islands":                        "FO",
+	"fiji":                                 "FJ",
+	"finland":                              "FI",
+	"france":                               "FR",
+	"french guiana":                        "GF",
+	"french polynesia":                     "PF",
+	"french southern territories":          "TF",
+	"gabon":                                "GA",
+	"gambia":                               "GM",
+	"georgia":                              "GE",
+	"germany":                              "DE",
+	"ghana":                                "GH",
+	"gibraltar":                            "GI",
+	"greece":                               "GR",
+	"greenland":                            "GL",
+	"grenada":                              "GD",
+	"guadeloupe":                           "GP",
+	"guam":                                 "GU",
+	"guatemala":                            "GT",
+	"guinea":                               "GN",
+	"guinea-bissau":                        "GW",
+	"guyana":                               "GY",
+	"haiti":                                "HT",
+	"heard island and mcdonald islands":    "HM",
+	"holy see (vatican city state)":        "VA",
+	"honduras":                             "HN",
+	"hong kong":                            "HK",
+	"hungary":                              "HU",
+	"iceland":                              "IS",
+	"india":                                "IN",
+	"indonesia":                            "ID",
+	"iran":                                 "IR",
+	"iran islamic republic of":             "IR",
+	"iraq":                                 "IQ",
+	"ireland":                              "IE",
+	"israel":                               "IL",
+	"italy":                                "IT",
+	"jamaica":                              "JM",
+	"japan":                                "JP",
+	"jordan":                               "JO",
+	"kazakhstan":                           "KZ",
+	"kenya":                                "KE",
+	"kiribati":                             "KI",
+	"north korea":                          "KP",
+	"south korea":                          "KR",
+	"kuwait":                               "KW",
+	"kyrgyzstan":                           "KG",
+	"lao people's democratic republic":     "LA",
+	"latvia":                               "LV",
+	"lebanon":                              "LB",
+	"lesotho":                              "LS",
+	"liberia":                              "LR",
+	"libya":                                "LY",
+	"liechtenstein":                        "LI",
+	"lithuania":                            "LT",
+	"luxembourg":                           "LU",
+	"macao":                                "MO",
+	"madagascar":                           "MG",
+	"malawi":                               "MW",
+	"malaysia":                             "MY",
+	"maldives":                             "MV",
+	"mali":                                 "ML",
+	"malta":                                "MT",
+	"marshall islands":                     "MH",
+	"martinique":                           "MQ",
+	"mauritania":                           "MR",
+	"mauritius":                            "MU",
+	"mayotte":                              "YT",
+	"mexico":                               "MX",
+	"micronesia federated states of":       "FM",
+	"moldova":                              "MD",
+	"moldova republic of":                  "MD",
+	"moldova, republic of":                 "MD",
+	"monaco":                               "MC",
+	"mongolia":                             "MN",
+	"montserrat":                           "MS",
+	"morocco":                              "MA",
+	"mozambique":                           "MZ",
+	"myanmar":                              "MM",
+	"namibia":                              "NA",
+	"nauru":                                "NR",
+	"nepal":                                "NP",
+	"netherlands":                          "NL",
+	"new caledonia":                        "NC",
+	"new zealand":                          "NZ",
+	"nicaragua":                            "NI",
+	"niger":                                "NE",
+	"nigeria":                              "NG",
+	"niue":                                 "NU",
+	"norfolk island":                       "NF",
+	"north macedonia republic of":          "MK",
+	"northern mariana islands":             "MP",
+	"norway":                               "NO",
+	"oman":                                 "OM",
+	"pakistan":                             "PK",
+	"palau":                                "PW",
+	"palestinian territory occupied":       "PS",
+	"panama":                               "PA",
+	"papua new guinea":                     "PG",
+	"paraguay":                             "PY",
+	"peru":                                 "PE",
+	"philippines":                          "PH",
+	"pitcairn":                             "PN",
+	"poland":                               "PL",
+	"portugal":                             "PT",
+	"puerto rico":                          "PR",
+	"qatar":                                "QA",
+	"reunion":                              "RE",
+	"romania":                              "RO",
+	"russia":                               "RU",
+	"russian federation":                   "RU",
+	"rwanda":                               "RW",
+	"saint helena":                         "SH",
+	"saint kitts and nevis":                "KN",
+	"saint lucia":                          "LC",
+	"saint pierre and miquelon":            "PM",
+	"saint vincent and the grenadines":     "VC",
+	"samoa":                                "WS",
+	"san marino":                           "SM",
+	"sao tome and principe":                "ST",
+	"saudi arabia":                         "SA",
+	"senegal":                              "SN",
+	"seychelles":                           "SC",
+	"sierra leone":                         "SL",
+	"singapore":                            "SG",
+	"slovakia":                             "SK",
+	"slovak republic":                      "SK",
+	"slovenia":                             "SI",
+	"solomon islands":                      "SB",
+	"somalia":                              "SO",
+	"south africa":                         "ZA",
+	"south georgia and the south sandwich islands": "GS",
+	"spain":                                "ES",
+	"sri lanka":                            "LK",
+	"sudan":                                "SD",
+	"suriname":                             "SR",
+	"svalbard and jan mayen":               "SJ",
+	"eswatini":                             "SZ",
+	"sweden":                               "SE",
+	"switzerland":                          "CH",
+	"syrian arab republic":                 "SY",
+	"taiwan":                               "TW",
+	"tajikistan":                           "TJ",
+	"tanzania united republic of":          "TZ",
+	"thailand":                             "TH",
+	"timor-leste":                          "TL",
+	"togo":                                 "TG",
+	"tokelau":                              "TK",
+	"tonga":                                "TO",
+	"trinidad and tobago":                  "TT",
+	"tunisia":                              "TN",
+	"turkey":                               "TR",
+	"turkmenistan":                         "TM",
+	"turks and caicos islands":             "TC",
+	"tuvalu":                               "TV",
+	"uganda":                               "UG",
+	"ukraine":                              "UA",
+	"united arab emirates":                 "AE",
+	"england":                              "GB",
+	"great britain":                        "GB",
+	"united kingdom":                       "GB",
+	"uk":                                   "GB",
+	"usa":                                  "US",
+	"united states":                        "US",
+	"united states of america":             "US",
+	"united states minor outlying islands": "UM",
+	"uruguay":                              "UY",
+	"uzbekistan":                           "UZ",
+	"vanuatu":                              "VU",
+	"venezuela":                            "VE",
+	"vietnam":                              "VN",
+	"virgin islands british":               "VG",
+	"virgin islands u.s.":                  "VI",
+	"wallis and futuna":                    "WF",
+	"western sahara":                       "EH",
+	"yemen":                                "YE",
+	"zambia":                               "ZM",
+	"zimbabwe":                             "ZW",
+	"åland islands":                        "AX",
+	"bonaire sint eustatius and saba":      "BQ",
+	"curaçao":                              "CW",
+	"guernsey":                             "GG",
+	"isle of man":                          "IM",
+	"jersey":                               "JE",
+	"montenegro":                           "ME",
+	"saint barthélemy":                     "BL",
+	"saint martin (french part)":           "MF",
+	"serbia":                               "RS",
+	"sint maarten (dutch part)":            "SX",
+	"south sudan":                          "SS",
+	"kosovo":                               "XK",
+}
+
+// countryAlpha3Mapping maps ISO 3166-1 alpha-3 codes to their alpha-2
+// codes. Kosovo uses the commonly used user-assigned codes.
+var countryAlpha3Mapping = map[string]string{
+	"ABW": "AW",
+	"AFG": "AF",
+	"AGO": "AO",
+	"AIA": "AI",
+	"ALA": "AX",
+	"ALB": "AL",
+	"AND": "AD",
+	"ARE": "AE",
+	"ARG": "AR",
+	"ARM": "AM",
+	"ASM": "AS",
+	"ATA": "AQ",
+	"ATF": "TF",
+	"ATG": "AG",
+	"AUS": "AU",
+	"AUT": "AT",
+	"AZE": "AZ",
+	"BDI": "BI",
+	"BEL": "BE",
+	"BEN": "BJ",
+	"BES": "BQ",
+	"BFA": "BF",
+	"BGD": "BD",
+	"BGR": "BG",
+	"BHR": "BH",
+	"BHS": "BS",
+	"BIH": "BA",
+	"BLM": "BL",
+	"BLR": "BY",
+	"BLZ": "BZ",
+	"BMU": "BM",
+	"BOL": "BO",
+	"BRA": "BR",
+	"BRB": "BB",
+	"BRN": "BN",
+	"BTN": "BT",
+	"BVT": "BV",
+	"BWA": "BW",
+	"CAF": "CF",
+	"CAN": "CA",
+	"CCK": "CC",
+	"CHE": "CH",
+	"CHL": "CL",
+	"CHN": "CN",
+	"CIV": "CI",
+	"CMR": "CM",
+	"COD": "CD",
+	"COG": "CG",
+	"COK": "CK",
+	"COL": "CO",
+	"COM": "KM",
+	"CPV": "CV",
+	"CRI": "CR",
+	"CUB": "CU",
+	"CUW": "CW",
+	"CXR": "CX",
+	"CYM": "KY",
+	"CYP": "CY",
+	"CZE": "CZ",
+	"DEU": "DE",
+	"DJI": "DJ",
+	"DMA": "DM",
+	"DNK": "DK",
+	"DOM": "DO",
+	"DZA": "DZ",
+	"ECU": "EC",
+	"EGY": "EG",
+	"ERI": "ER",
+	"ESH": "EH",
+	"ESP": "ES",
+	"EST": "EE",
+	"ETH": "ET",
+	"FIN": "FI",
+	"FJI": "FJ",
+	"FLK": "FK",
+	"FRA": "FR",
+	"FRO": "FO",
+	"FSM": "FM",
+	"GAB": "GA",
+	"GBR": "GB",
+	"GEO": "GE",
+	"GGY": "GG",
+	"GHA": "GH",
+	"GIB": "GI",
+	"GIN": "GN",
+	"GLP": "GP",
+	"GMB": "GM",
+	"GNB": "GW",
+	"GNQ": "GQ",
+	"GRC": "GR",
+	"GRD": "GD",
+	"GRL": "GL",
+	"GTM": "GT",
+	"GUF": "GF",
+	"GUM": "GU",
+	"GUY": "GY",
+	"HKG": "HK",
+	"HMD": "HM",
+	"HND": "HN",
+	"HRV": "HR",
+	"HTI": "HT",
+	"HUN": "HU",
+	"IDN": "ID",
+	"IMN": "IM",
+	"IND": "IN",
+	"IOT": "IO",
+	"IRL": "IE",
+	"IRN": "IR",
+	"IRQ": "IQ",
+	"ISL": "IS",
+	"ISR": "IL",
+	"ITA": "IT",
+	"JAM": "JM",
+	"JEY": "JE",
+	"JOR": "JO",
+	"JPN": "JP",
+	"KAZ": "KZ",
+	"KEN": "KE",
+	"KGZ": "KG",
+	"KHM": "KH",
+	"KIR": "KI",
+	"KNA": "KN",
+	"KOR": "KR",
+	"KWT": "KW",
+	"LAO": "LA",
+	"LBN": "LB",
+	"LBR": "LR",
+	"LBY": "LY",
+	"LCA": "LC",
+	"LIE": "LI",
+	"LKA": "LK",
+	"LSO": "LS",
+	"LTU": "LT",
+	"LUX": "LU",
+	"LVA": "LV",
+	"MAC": "MO",
+	"MAF": "MF",
+	"MAR": "MA",
+	"MCO": "MC",
+	"MDA": "MD",
+	"MDG": "MG",
+	"MDV": "MV",
+	"MEX": "MX",
+	"MHL": "MH",
+	"MKD": "MK",
+	"MLI": "ML",
+	"MLT": "MT",
+	"MMR": "MM",
+	"MNE": "ME",
+	"MNG": "MN",
+	"MNP": "MP",
+	"MOZ": "MZ",
+	"MRT": "MR",
+	"MSR": "MS",
+	"MTQ": "MQ",
+	"MUS": "MU",
+	"MWI": "MW",
+	"MYS": "MY",
+	"MYT": "YT",
+	"NAM": "NA",
+	"NCL": "NC",
+	"NER": "NE",
+	"NFK": "NF",
+	"NGA": "NG",
+	"NIC": "NI",
+	"NIU": "NU",
+	"NLD": "NL",
+	"NOR": "NO",
+	"NPL": "NP",
+	"NRU": "NR",
+	"NZL": "NZ",
+	"OMN": "OM",
+	"PAK": "PK",
+	"PAN": "PA",
+	"PCN": "PN",
+	"PER": "PE",
+	"PHL": "PH",
+	"PLW": "PW",
+	"PNG": "PG",
+	"POL": "PL",
+	"PRI": "PR",
+	"PRK": "KP",
+	"PRT": "PT",
+	"PRY": "PY",
+	"PSE": "PS",
+	"PYF": "PF",
+	"QAT": "QA",
+	"REU": "RE",
+	"ROU": "RO",
+	"RUS": "RU",
+	"RWA": "RW",
+	"SAU": "SA",
+	"SDN": "SD",
+	"SEN": "SN",
+	"SGP": "SG",
+	"SGS": "GS",
+	"SHN": "SH",
+	"SJM": "SJ",
+	"SLB": "SB",
+	"SLE": "SL",
+	"SLV": "SV",
+	"SMR": "SM",
+	"SOM": "SO",
+	"SPM": "PM",
+	"SRB": "RS",
+	"SSD": "SS",
+	"STP": "ST",
+	"SUR": "SR",
+	"SVK": "SK",
+	"SVN": "SI",
+	"SWE": "SE",
+	"SWZ": "SZ",
+	"SXM": "SX",
+	"SYC": "SC",
+	"SYR": "SY",
+	"TCA": "TC",
+	"TCD": "TD",
+	"TGO": "TG",
+	"THA": "TH",
+	"TJK": "TJ",
+	"TKL": "TK",
+	"TKM": "TM",
+	"TLS": "TL",
+	"TON": "TO",
+	"TTO": "TT",
+	"TUN": "TN",
+	"TUR": "TR",
+	"TUV": "TV",
+	"TWN": "TW",
+	"TZA": "TZ",
+	"UGA": "UG",
+	"UKR": "UA",
+	"UMI": "UM",
+	"URY": "UY",
+	"USA": "US",
+	"UZB": "UZ",
+	"VAT": "VA",
+	"VCT": "VC",
+	"VEN": "VE",
+	"VGB": "VG",
+	"VIR": "VI",
+	"VNM": "VN",
+	"VUT": "VU",
+	"WLF": "WF",
+	"WSM": "WS",
+	"XKX": "XK",
+	"YEM": "YE",
+	"ZAF": "ZA",
+	"ZMB": "ZM",
+	"ZWE": "ZW",
+}
+
+// countryCodes is the set of ISO 3166-1 alpha-2 codes.
+var countryCodes = func() map[string]bool {
+	ret := make(map[string]bool)
+	for _, code := range countryAlpha3Mapping {
+		ret[code] = true
+	}
+	return ret
+}()
+
+// CountryCodeFromName returns the ISO 3166-1 alpha-2 code of the named
+// country, in any case. Codes are not accepted. It returns false if the
+// name is not a known country name or synonym.
+func CountryCodeFromName(name string) (string, bool) {
+	code, found := countryNameMapping[strings.ToLower(name)]
+	return code, found
+}
+
+// ParseCountry returns the ISO 3166-1 alpha-2 code of the country
+// represented by s. s may be an alpha-2 or alpha-3 code or a country name,
+// in any case. It returns false if s is not a known country.
+func ParseCountry(s string) (string, bool) {
+	s = strings.Join(strings.Fields(s), " ")
+
+	upper := strings.ToUpper(s)
+	if countryCodes[upper] {
+		return upper, true
+	}
+	if code, found := countryAlpha3Mapping[upper]; found {
+		return code, true
+	}
+
+	return CountryCodeFromName(s)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountryMappings(t *testing.T) {
+	// all names map to a known code
+	for name, code := range countryNameMapping {
+		assert.True(t, countryCodes[code], "%q maps to unknown code %q", name, code)
+	}
+}
+
+func TestParseCountry(t *testing.T) {
+	tests := []struct {
+		input     string
+		want      string
+		wantValid bool
+	}{
+		{"US", "US", true},
+		{"us", "US", true},
+		{" gb ", "GB", true},
+		{"USA", "US", true},
+		{"deu", "DE", true},
+		{"United States", "US", true},
+		{"united  states of america", "US", true},
+		{"Germany", "DE", true},
+		{"UK", "GB", true},
+		{"Czechia", "CZ", true},
+		{"ZZ", "", false},
+		{"XYZ", "", false},
+		{"Atlantis", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, valid := ParseCountry(tt.input)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantValid, valid)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// DefaultTrackingParams are common tracking query parameters.
+var DefaultTrackingParams = []string{"utm_*", "fbclid", "gclid"}
+
+// URLCanonicalizer normalises URLs so that equivalent URLs compare equal.
+type URLCanonicalizer struct {
+	// TrackingParams are query parameters that are removed from the URL.
+	// A trailing "*" matches any parameter starting with the preceding prefix.
+	TrackingParams []string
+}
+
+// Canonicalize returns the canonical form of s. The scheme and host are
+// lower-cased, http is upgraded to https, default ports and tracking
+// parameters are removed, and trailing slashes are trimmed from the path.
+// Values that are not absolute URLs are returned trimmed but otherwise
+// unchanged.
+func (c URLCanonicalizer) Canonicalize(s string) string {
+	s = strings.TrimSpace(s)
+
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return s
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		port = ""
+	}
+
+	if scheme == "http" {
+		scheme = "https"
+	}
+
+	u.Scheme = scheme
+	u.Host = host
+	if port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	}
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if c.isTrackingParam(k) {
+				q.Del(k)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	return u.String()
+}
+
+func (c URLCanonicalizer) isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range c.TrackingParams {
+		p = strings.ToLower(p)
+		if prefix := strings.TrimSuffix(p, "*"); prefix != p {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == p {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,36 @@
+package utils
+
+import "testing"
+
+func TestURLCanonicalizer_Canonicalize(t *testing.T) {
+	c := URLCanonicalizer{
+		TrackingParams: DefaultTrackingParams,
+	}
+
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"empty", "", ""},
+		{"not a url", "  not a url ", "not a url"},
+		{"unchanged", "https://example.com/path", "https://example.com/path"},
+		{"http upgrade", "http://example.com/path", "https://example.com/path"},
+		{"case", "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"default http port", "http://example.com:80/path", "https://example.com/path"},
+		{"default https port", "https://example.com:443/path", "https://example.com/path"},
+		{"other port", "https://example.com:8080/path", "https://example.com:8080/path"},
+		{"trailing slash", "https://example.com/path/", "https://example.com/path"},
+		{"root slash", "https://example.com/", "https://example.com"},
+		{"tracking params", "https://example.com/path?utm_source=x&id=1&fbclid=y", "https://example.com/path?id=1"},
+		{"only tracking params", "https://example.com/path?utm_source=x", "https://example.com/path"},
+		{"fragment", "https://example.com/path/#frag", "https://example.com/path#frag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Canonicalize(tt.s); got != tt.want {
+				t.Errorf("URLCanonicalizer.Canonicalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}